@@ -0,0 +1,183 @@
+/* pokebattle runs a number of battles between two teams using builtin
+players & writes the results.
+
+	pokebattle -p1 teams/a.txt -p2 teams/b.txt -n 100 -results results.csv
+
+Team files may be in Showdown's export (paste) or packed formats, or a JSON
+list of PokemonSpec. Random battle formats don't need teams.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/voidshard/poke-showdown-go/pkg/match"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+func main() {
+	p1Team := flag.String("p1", "", "team file for p1")
+	p2Team := flag.String("p2", "", "team file for p2")
+	p1Player := flag.String("p1-player", "maxpower", fmt.Sprintf("player for p1, one of %v", player.Names()))
+	p2Player := flag.String("p2-player", "random", fmt.Sprintf("player for p2, one of %v", player.Names()))
+	format := flag.String("format", string(sim.FormatGen8), "battle format")
+	battles := flag.Int("n", 10, "number of battles")
+	seedFlag := flag.String("seed", "", "master seed (\"1,2,3,4\" or a number), random if not given")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "battles to run at once")
+	timeout := flag.Duration("timeout", 5*time.Minute, "give up on a battle after this long")
+	results := flag.String("results", "", "write per battle results here (.csv for CSV, otherwise JSON, - for stdout)")
+	summary := flag.String("summary", "-", "write aggregate stats here (.csv for CSV, otherwise JSON, - for stdout)")
+	verbose := flag.Bool("v", false, "log simulator output")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	err := run(&config{
+		teams:       []string{*p1Team, *p2Team},
+		players:     []string{*p1Player, *p2Player},
+		format:      sim.Format(*format),
+		battles:     *battles,
+		seed:        *seedFlag,
+		concurrency: *concurrency,
+		timeout:     *timeout,
+		results:     *results,
+		summary:     *summary,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// config is our parsed command line
+type config struct {
+	teams       []string
+	players     []string
+	format      sim.Format
+	battles     int
+	seed        string
+	concurrency int
+	timeout     time.Duration
+	results     string
+	summary     string
+}
+
+func run(cfg *config) error {
+	master := sim.RandomSeed()
+	if cfg.seed != "" {
+		var err error
+		master, err = sim.ParseSeed(cfg.seed)
+		if err != nil {
+			return err
+		}
+	}
+
+	var teams [][]*sim.PokemonSpec
+	if !sim.IsRandom(cfg.format) {
+		for i, path := range cfg.teams {
+			if path == "" {
+				return fmt.Errorf("a team file is required for p%d in %s", i+1, cfg.format)
+			}
+			team, err := readTeam(path)
+			if err != nil {
+				return err
+			}
+			teams = append(teams, team)
+		}
+	}
+
+	jobs := []*match.Job{}
+	for i := 0; i < cfg.battles; i++ {
+		seed := master.Derive(i)
+
+		players := []player.Player{}
+		for j, name := range cfg.players {
+			// each player gets it's own RNG, derived from the battle seed
+			p, err := player.ByName(name, int64(seed.Derive(j).Uint64()))
+			if err != nil {
+				return err
+			}
+			players = append(players, p)
+		}
+
+		jobs = append(jobs, &match.Job{
+			Spec:    &sim.BattleSpec{Format: cfg.format, Players: teamsFor(teams), Seed: seed},
+			Players: players,
+		})
+	}
+
+	fmt.Fprintf(os.Stderr, "running %d battles, master seed %s\n", cfg.battles, master)
+	res := match.Run(jobs, cfg.concurrency, match.Timeout(cfg.timeout))
+
+	if cfg.results != "" {
+		err := write(cfg.results, res, func(w io.Writer) error { return match.WriteCSV(w, res) })
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.summary != "" {
+		sum := match.Summarise(res)
+		return write(cfg.summary, sum, sum.WriteCSV)
+	}
+	return nil
+}
+
+// readTeam reads a team from the given file
+func readTeam(path string) ([]*sim.PokemonSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	team, err := sim.ReadTeam(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, path)
+	}
+	return team, nil
+}
+
+// teamsFor returns a copy of the given teams for a single battle, since
+// packing a team can change it & battles run concurrently
+func teamsFor(in [][]*sim.PokemonSpec) [][]*sim.PokemonSpec {
+	if in == nil {
+		return nil
+	}
+	out := [][]*sim.PokemonSpec{}
+	for _, team := range in {
+		out = append(out, sim.CopyTeam(team))
+	}
+	return out
+}
+
+// write writes the given value to path as CSV (if the path ends in .csv)
+// or JSON, where "-" is stdout
+func write(path string, value interface{}, toCSV func(io.Writer) error) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		return toCSV(w)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
@@ -0,0 +1,37 @@
+/* pokeserver hosts battles over HTTP & websockets (see pkg/server).
+
+	pokeserver -addr :8080
+*/
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/voidshard/poke-showdown-go/pkg/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	verbose := flag.Bool("v", false, "log simulator output")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	srv := server.New()
+
+	logger.Printf("listening on %s", *addr)
+	err := http.ListenAndServe(*addr, srv)
+
+	// nb. logger.Fatal exits without running deferred calls
+	srv.Close()
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/voidshard/poke-showdown-go/pkg/event"
 	"github.com/voidshard/poke-showdown-go/pkg/field"
+	"github.com/voidshard/poke-showdown-go/pkg/narrate"
 	"github.com/voidshard/poke-showdown-go/pkg/sim"
 
 	"github.com/rivo/tview"
@@ -14,6 +15,7 @@ import (
 type game struct {
 	proc  sim.SimulatorStream
 	field field.Watcher
+	narr  *narrate.Narrator
 
 	p1a *tview.TextView
 	p1b *tview.TextView
@@ -33,8 +35,11 @@ func (g *game) run() {
 		g.field.Update(u)
 
 		if u.Event != nil {
-			fmt.Fprintf(g.output, "%s\n", u.Event.String())
-			if u.Event.Type == event.Win {
+			text := g.narr.Narrate(u.Event)
+			if text != "" {
+				fmt.Fprintf(g.output, "%s\n", text)
+			}
+			if u.Event.Type == event.Win || u.Event.Type == event.Tie {
 				return
 			}
 		} else if u.Error != nil {
@@ -103,6 +108,11 @@ func (g *game) updateSide(side *sim.Side, views ...*tview.TextView) {
 }
 
 func newGame(spec *sim.BattleSpec) (*game, error) {
+	narr, err := narrate.New()
+	if err != nil {
+		return nil, err
+	}
+
 	proc, err := sim.NewSimulatorStream(spec)
 	if err != nil {
 		return nil, err
@@ -115,6 +125,7 @@ func newGame(spec *sim.BattleSpec) (*game, error) {
 	g := &game{
 		proc:   proc,
 		field:  field.NewWatcher(),
+		narr:   narr,
 		p1a:    tview.NewTextView().SetWordWrap(true),
 		p1b:    tview.NewTextView().SetWordWrap(true),
 		p2a:    tview.NewTextView().SetWordWrap(true),
@@ -3,6 +3,7 @@ module github.com/voidshard/poke-showdown-go
 go 1.14
 
 require (
+	github.com/gorilla/websocket v1.4.2
 	github.com/rivo/tview v0.0.0-20210312174852-ae9464cc3598
 	github.com/stretchr/testify v1.7.0
 )
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// skip are lines we don't pass on, as the simulator stream doesn't
+var skip = map[string]bool{
+	"init":        true,
+	"title":       true,
+	"j":           true,
+	"l":           true,
+	"t:":          true,
+	"start":       true,
+	"player":      true,
+	"teamsize":    true,
+	"gametype":    true,
+	"gen":         true,
+	"tier":        true,
+	"rule":        true,
+	"rated":       true,
+	"clearpoke":   true,
+	"poke":        true,
+	"teampreview": true,
+	"updatepoke":  true,
+	"split":       true,
+}
+
+// Battle is a battle room we've joined. It implements sim.SimulatorStream.
+// Winners are given by player (p1, p2) rather than user name, as they
+// are locally.
+type Battle struct {
+	// ID of the battle room ie. battle-gen8randombattle-123
+	ID string
+
+	client *Client
+	out    chan *sim.Update
+	done   chan struct{}
+	once   sync.Once
+
+	lock    sync.Mutex
+	player  string
+	rqid    int
+	players map[string]string
+
+	// slock is held while sending so that we never send on a closed chan
+	slock  sync.Mutex
+	number int
+	over   bool
+}
+
+func newBattle(c *Client, id string, buffer int) *Battle {
+	return &Battle{
+		ID:      id,
+		client:  c,
+		out:     make(chan *sim.Update, buffer),
+		done:    make(chan struct{}),
+		players: map[string]string{},
+	}
+}
+
+// Player returns which player we are (p1, p2), once we've been sent
+// our first request
+func (b *Battle) Player() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.player
+}
+
+// Write sends our choice for the latest request.
+// The action's player is ignored, we can only choose for ourselves.
+func (b *Battle) Write(act *sim.Action) error {
+	b.lock.Lock()
+	rqid := b.rqid
+	b.lock.Unlock()
+
+	return b.client.send(b.ID, fmt.Sprintf("/choose %s|%d", act.Choice(), rqid))
+}
+
+// Updates returns updates from the battle, the chan is closed when the
+// battle is over (or we leave)
+func (b *Battle) Updates() <-chan *sim.Update {
+	return b.out
+}
+
+// Forfeit the battle
+func (b *Battle) Forfeit() error {
+	return b.client.send(b.ID, "/forfeit")
+}
+
+// Stop leaves the battle room.
+// Nb. this doesn't forfeit (see Forfeit) though the server may end the
+// battle if we don't come back.
+func (b *Battle) Stop() {
+	b.once.Do(func() {
+		close(b.done)
+		b.client.forget(b.ID)
+		b.client.send("", "/leave "+b.ID)
+	})
+	b.end()
+}
+
+// handle deals with lines sent to the battle room
+func (b *Battle) handle(lines []string) {
+	for _, line := range lines {
+		bits := strings.Split(line, "|")
+		if len(bits) < 2 || skip[bits[1]] {
+			if len(bits) > 3 && bits[1] == "player" {
+				//|player|PLAYER|USERNAME|AVATAR|RATING
+				b.lock.Lock()
+				b.players[userID(bits[3])] = bits[2]
+				b.lock.Unlock()
+			}
+			continue
+		}
+
+		switch bits[1] {
+		case "request":
+			b.request(strings.Join(bits[2:], "|"))
+		case "error":
+			b.send(&sim.Update{Error: parse.LineError(line)})
+		case "deinit", "noinit":
+			b.end()
+		case event.Win:
+			//|win|USER
+			b.lock.Lock()
+			player, ok := b.players[userID(strings.Join(bits[2:], "|"))]
+			b.lock.Unlock()
+			if ok {
+				line = "|win|" + player
+			}
+			fallthrough
+		default:
+			evt := event.Parse(line)
+			if evt == nil {
+				continue
+			}
+			b.send(&sim.Update{Event: evt})
+			if evt.Type == event.Win || evt.Type == event.Tie {
+				b.client.forget(b.ID)
+				b.end()
+			}
+		}
+	}
+}
+
+// request passes on a request for us to make a choice
+func (b *Battle) request(raw string) {
+	if raw == "" {
+		// the server clears requests when they're answered
+		return
+	}
+
+	head := struct {
+		RQID        int  `json:"rqid"`
+		TeamPreview bool `json:"teamPreview"`
+	}{}
+	err := json.Unmarshal([]byte(raw), &head)
+	if err != nil {
+		b.send(&sim.Update{Error: err})
+		return
+	}
+
+	b.lock.Lock()
+	b.rqid = head.RQID
+	b.lock.Unlock()
+
+	if head.TeamPreview {
+		// as with the simulator stream, we leave our team in the order given
+		b.client.send(b.ID, fmt.Sprintf("/choose default|%d", head.RQID))
+		return
+	}
+
+	side, err := sim.DecodeSide([]byte(raw))
+	if err != nil {
+		b.send(&sim.Update{Error: err})
+		return
+	}
+
+	b.lock.Lock()
+	b.player = side.Player
+	b.lock.Unlock()
+
+	b.send(&sim.Update{Side: side})
+}
+
+// send passes on an update, unless the battle is over
+func (b *Battle) send(u *sim.Update) {
+	b.slock.Lock()
+	defer b.slock.Unlock()
+
+	if b.over {
+		return
+	}
+	u.Number = b.number
+	b.number++
+
+	select {
+	case b.out <- u:
+	case <-b.done:
+	case <-b.client.done:
+	}
+}
+
+// end closes our updates chan
+func (b *Battle) end() {
+	b.slock.Lock()
+	defer b.slock.Unlock()
+	if b.over {
+		return
+	}
+	b.over = true
+	close(b.out)
+}
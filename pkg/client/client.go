@@ -0,0 +1,425 @@
+/*
+Package client connects to a Pokemon Showdown server (or a local test
+server) as a user, so bots can play on a server rather than only against
+an offline simulator.
+
+	c, _ := client.Dial("ws://localhost:8000/showdown/websocket")
+	c.Login("mybot", "")
+	c.Search(sim.FormatGen8Random)
+
+	b := <-c.Battles()
+	for u := range b.Updates() {
+		...
+		b.Write(action)
+	}
+
+Battles implement sim.SimulatorStream, so players & match.Play work as they
+do locally.
+*/
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// ErrTimeout is returned if the server doesn't reply in time
+	ErrTimeout = errors.New("timed out waiting for server")
+
+	// ErrLogin is returned if we can't log in
+	ErrLogin = errors.New("login failed")
+
+	// ErrClosed is returned if the connection is closed
+	ErrClosed = errors.New("connection closed")
+)
+
+// Option is some option for Dial
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Timeout     time.Duration
+	LoginServer string
+	Buffer      int
+}
+
+func buildOpts(in []Option) *opts {
+	cfg := &opts{
+		Timeout:     30 * time.Second,
+		LoginServer: "https://play.pokemonshowdown.com/action.php",
+		Buffer:      1024,
+	}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Timeout sets how long we wait for the server to reply when connecting
+// & logging in. Defaults to 30s.
+func Timeout(t time.Duration) Option {
+	return func(o *opts) {
+		o.Timeout = t
+	}
+}
+
+// LoginServer sets the URL of the login server's action.php, which
+// provides assertions that we own a name. Defaults to the main server's.
+func LoginServer(addr string) Option {
+	return func(o *opts) {
+		o.LoginServer = addr
+	}
+}
+
+// Buffer sets how many updates a battle holds before we stop reading from
+// the server (until they're read). Defaults to 1024.
+func Buffer(n int) Option {
+	return func(o *opts) {
+		o.Buffer = n
+	}
+}
+
+// Challenge is a challenge from another user
+type Challenge struct {
+	From   string
+	Format string
+}
+
+// Client is a connection to a showdown server
+type Client struct {
+	cfg  *opts
+	conn *websocket.Conn
+
+	wlock sync.Mutex
+
+	lock       sync.Mutex
+	challstr   string
+	user       string
+	battles    map[string]*Battle
+	challenged map[string]bool
+
+	ready      chan struct{}
+	login      chan error
+	newBattles chan *Battle
+	challenges chan *Challenge
+	done       chan struct{}
+	once       sync.Once
+}
+
+// Dial connects to the server at the given websocket address
+// (ie. ws://localhost:8000/showdown/websocket) & waits for it to be ready
+// for us to log in
+func Dial(addr string, options ...Option) (*Client, error) {
+	cfg := buildOpts(options)
+
+	conn, _, err := websocket.DefaultDialer.Dial(addr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		cfg:        cfg,
+		conn:       conn,
+		battles:    map[string]*Battle{},
+		challenged: map[string]bool{},
+		ready:      make(chan struct{}),
+		login:      make(chan error, 1),
+		newBattles: make(chan *Battle, 16),
+		challenges: make(chan *Challenge, 16),
+		done:       make(chan struct{}),
+	}
+	go c.read()
+
+	select {
+	case <-c.ready:
+		return c, nil
+	case <-c.done:
+		return nil, ErrClosed
+	case <-time.After(cfg.Timeout):
+		c.Close()
+		return nil, fmt.Errorf("%w for challstr", ErrTimeout)
+	}
+}
+
+// Login as the given user, the password can be empty for unregistered names
+func (c *Client) Login(name, password string) error {
+	c.lock.Lock()
+	challstr := c.challstr
+	c.lock.Unlock()
+
+	assertion, err := getAssertion(c.cfg.LoginServer, name, password, challstr)
+	if err != nil {
+		return err
+	}
+
+	// clear any earlier result
+	select {
+	case <-c.login:
+	default:
+	}
+
+	err = c.send("", fmt.Sprintf("/trn %s,0,%s", name, assertion))
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-c.login:
+		return err
+	case <-c.done:
+		return ErrClosed
+	case <-time.After(c.cfg.Timeout):
+		return fmt.Errorf("%w for login", ErrTimeout)
+	}
+}
+
+// User returns the name we're logged in as (or the guest name we're given)
+func (c *Client) User() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.user
+}
+
+// SetTeam sets the team we'll use for future battles, nil for random formats
+func (c *Client) SetTeam(team []*sim.PokemonSpec) error {
+	packed := "null"
+	if len(team) > 0 {
+		var err error
+		packed, err = sim.PackTeam(team)
+		if err != nil {
+			return err
+		}
+	}
+	return c.send("", "/utm "+packed)
+}
+
+// Search looks for a battle in the given format (on the ladder)
+func (c *Client) Search(format sim.Format) error {
+	return c.send("", "/search "+formatID(format))
+}
+
+// Cancel stops searching for a battle
+func (c *Client) Cancel() error {
+	return c.send("", "/cancelsearch")
+}
+
+// Challenge asks the given user for a battle in the given format
+func (c *Client) Challenge(user string, format sim.Format) error {
+	return c.send("", fmt.Sprintf("/challenge %s, %s", user, formatID(format)))
+}
+
+// Accept a challenge from the given user
+func (c *Client) Accept(user string) error {
+	return c.send("", "/accept "+user)
+}
+
+// Reject a challenge from the given user
+func (c *Client) Reject(user string) error {
+	return c.send("", "/reject "+user)
+}
+
+// Battles returns battles as we join them
+func (c *Client) Battles() <-chan *Battle {
+	return c.newBattles
+}
+
+// Challenges returns challenges from other users as they arrive
+func (c *Client) Challenges() <-chan *Challenge {
+	return c.challenges
+}
+
+// Close disconnects from the server, ending any battles
+func (c *Client) Close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// send writes a command to the given room ("" for the global room)
+func (c *Client) send(room, text string) error {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(room+"|"+text))
+}
+
+// read handles messages from the server until the connection closes
+func (c *Client) read() {
+	defer func() {
+		c.Close()
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		for id, b := range c.battles {
+			b.end()
+			delete(c.battles, id)
+		}
+		close(c.newBattles)
+		close(c.challenges)
+	}()
+
+	for {
+		_, raw, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		room := ""
+		lines := strings.Split(strings.TrimRight(string(raw), "\n"), "\n")
+		if strings.HasPrefix(lines[0], ">") {
+			room = strings.TrimSpace(lines[0][1:])
+			lines = lines[1:]
+		}
+
+		b := c.battle(room, lines)
+		if b != nil {
+			b.handle(lines)
+			continue
+		}
+		for _, line := range lines {
+			c.handle(line)
+		}
+	}
+}
+
+// battle returns the battle for a room, starting a new one if the lines
+// are the start of a battle
+func (c *Client) battle(room string, lines []string) *Battle {
+	if room == "" {
+		return nil
+	}
+
+	c.lock.Lock()
+	b, ok := c.battles[room]
+	c.lock.Unlock()
+	if ok {
+		return b
+	}
+
+	for _, line := range lines {
+		if line != "|init|battle" {
+			continue
+		}
+		b = newBattle(c, room, c.cfg.Buffer)
+
+		c.lock.Lock()
+		c.battles[room] = b
+		c.lock.Unlock()
+
+		select {
+		case c.newBattles <- b:
+		case <-c.done:
+		}
+		return b
+	}
+	return nil
+}
+
+// forget stops passing messages to a battle
+func (c *Client) forget(room string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.battles, room)
+}
+
+// handle deals with a message from the global room
+func (c *Client) handle(line string) {
+	bits := strings.Split(line, "|")
+	if len(bits) < 2 {
+		return
+	}
+
+	switch bits[1] {
+	case "challstr":
+		//|challstr|CHALLSTR (which includes a "|")
+		c.lock.Lock()
+		first := c.challstr == ""
+		c.challstr = strings.Join(bits[2:], "|")
+		c.lock.Unlock()
+		if first {
+			close(c.ready)
+		}
+	case "updateuser":
+		//|updateuser|USER|NAMED|AVATAR|SETTINGS
+		if len(bits) < 4 {
+			return
+		}
+		name := strings.TrimSpace(bits[2])
+		c.lock.Lock()
+		c.user = name
+		c.lock.Unlock()
+		if bits[3] == "1" {
+			c.loggedIn(nil)
+		}
+	case "nametaken":
+		//|nametaken|USER|MESSAGE
+		c.loggedIn(fmt.Errorf("%w: %s", ErrLogin, strings.Join(bits[2:], " ")))
+	case "updatechallenges":
+		//|updatechallenges|JSON
+		c.updateChallenges(strings.Join(bits[2:], "|"))
+	case "popup":
+		log.Printf("popup: %s\n", strings.Join(bits[2:], "|"))
+	}
+}
+
+// loggedIn passes on the result of a login
+func (c *Client) loggedIn(err error) {
+	select {
+	case c.login <- err:
+	default:
+	}
+}
+
+// updateChallenges passes on challenges we haven't seen before
+func (c *Client) updateChallenges(raw string) {
+	in := struct {
+		From map[string]string `json:"challengesFrom"`
+	}{}
+	err := json.Unmarshal([]byte(raw), &in)
+	if err != nil {
+		log.Printf("bad challenges: %v\n", err)
+		return
+	}
+
+	c.lock.Lock()
+	fresh := []*Challenge{}
+	for user, format := range in.From {
+		if !c.challenged[user] {
+			fresh = append(fresh, &Challenge{From: user, Format: format})
+		}
+	}
+	c.challenged = map[string]bool{}
+	for user := range in.From {
+		c.challenged[user] = true
+	}
+	c.lock.Unlock()
+
+	for _, ch := range fresh {
+		select {
+		case c.challenges <- ch:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// formatID returns the ID showdown uses for a format
+// ie. [Gen 8] Random Battle -> gen8randombattle
+func formatID(f sim.Format) string {
+	return data.Strip(string(f))
+}
+
+// userID returns the ID showdown uses for a user
+func userID(name string) string {
+	return data.Strip(name)
+}
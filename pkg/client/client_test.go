@@ -0,0 +1,310 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// fakeServer is a showdown server (& login server) that answers logins
+// itself & passes everything else to the test
+type fakeServer struct {
+	http *httptest.Server
+	recv chan string
+
+	lock sync.Mutex
+	conn *websocket.Conn
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	fs := &fakeServer{recv: make(chan string, 100)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/action.php", fs.action)
+	mux.HandleFunc("/showdown/websocket", fs.websocket)
+	fs.http = httptest.NewServer(mux)
+
+	t.Cleanup(fs.http.Close)
+	return fs
+}
+
+// dial connects a client to the server
+func (fs *fakeServer) dial(t *testing.T) *Client {
+	c, err := Dial(
+		"ws"+strings.TrimPrefix(fs.http.URL, "http")+"/showdown/websocket",
+		LoginServer(fs.http.URL+"/action.php"),
+		Timeout(2*time.Second),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(c.Close)
+	return c
+}
+
+// action is the login server, the user "taken" is registered with the
+// password "hunter2"
+func (fs *fakeServer) action(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	switch r.Form.Get("act") {
+	case "getassertion":
+		if r.Form.Get("userid") == "taken" {
+			fmt.Fprint(w, ";")
+			return
+		}
+		fmt.Fprintf(w, "valid-%s", r.Form.Get("userid"))
+	case "login":
+		if r.Form.Get("pass") != "hunter2" {
+			fmt.Fprint(w, `]{"actionsuccess":false,"assertion":";;Wrong password."}`)
+			return
+		}
+		fmt.Fprintf(w, `]{"actionsuccess":true,"assertion":"valid-%s"}`, userID(r.Form.Get("name")))
+	}
+}
+
+func (fs *fakeServer) websocket(w http.ResponseWriter, r *http.Request) {
+	up := websocket.Upgrader{}
+	conn, err := up.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fs.lock.Lock()
+	fs.conn = conn
+	fs.lock.Unlock()
+
+	fs.send("|updateuser| Guest 1|0|1|{}")
+	fs.send("|challstr|4|abcdef")
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg := string(raw)
+
+		if !strings.HasPrefix(msg, "|/trn ") {
+			fs.recv <- msg
+			continue
+		}
+
+		// |/trn NAME,0,ASSERTION
+		bits := strings.Split(strings.TrimPrefix(msg, "|/trn "), ",")
+		if bits[2] != "valid-"+userID(bits[0]) {
+			fs.send(fmt.Sprintf("|nametaken|%s|Your assertion is invalid.", bits[0]))
+			continue
+		}
+		fs.send(fmt.Sprintf("|updateuser| %s|1|1|{}", bits[0]))
+	}
+}
+
+// send writes a message to the client
+func (fs *fakeServer) send(msg string) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	fs.conn.WriteMessage(websocket.TextMessage, []byte(msg))
+}
+
+// expect returns the next message from the client
+func (fs *fakeServer) expect(t *testing.T) string {
+	select {
+	case msg := <-fs.recv:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for client")
+	}
+	return ""
+}
+
+// next returns the next update of a battle
+func next(t *testing.T, b *Battle) *sim.Update {
+	select {
+	case u, ok := <-b.Updates():
+		if !ok {
+			t.Fatal("updates closed")
+		}
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+	return nil
+}
+
+const testRequest = `{"active":[{"moves":[{"move":"Tackle","id":"tackle","pp":56,"maxpp":56,"target":"normal","disabled":false}]}],"side":{"name":"Alice","id":"p1","pokemon":[{"ident":"p1: Eevee","details":"Eevee, L50, F","condition":"130/130","active":true,"stats":{"atk":75,"def":70,"spa":65,"spd":85,"spe":75},"moves":["tackle"],"baseAbility":"adaptability","item":"","pokeball":"pokeball","ability":"adaptability"}]},"rqid":2}`
+
+// startBattle has the server put Alice (p1) & Bob (p2) in a battle
+func startBattle(t *testing.T, fs *fakeServer, c *Client) *Battle {
+	fs.send(strings.Join([]string{
+		">battle-gen8randombattle-1",
+		"|init|battle",
+		"|title|Alice vs. Bob",
+		"|j|☆Alice",
+	}, "\n"))
+	fs.send(">battle-gen8randombattle-1\n|request|" + testRequest)
+	fs.send(strings.Join([]string{
+		">battle-gen8randombattle-1",
+		"|player|p1|Alice|1|",
+		"|player|p2|Bob|2|",
+		"|teamsize|p1|1",
+		"|gametype|singles",
+		"|start",
+		"|switch|p1a: Eevee|Eevee, L50, F|130/130",
+		"|switch|p2a: Pikachu|Pikachu, L50, M|100/100",
+		"|turn|1",
+	}, "\n"))
+
+	select {
+	case b := <-c.Battles():
+		return b
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for battle")
+	}
+	return nil
+}
+
+func TestLogin(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Password string
+		Err      bool
+	}{
+		{"Alice", "", false},
+		{"taken", "hunter2", false},
+		{"taken", "", true},
+		{"taken", "wrong", true},
+	}
+
+	fs := newFakeServer(t)
+	for _, tt := range cases {
+		t.Run(fmt.Sprintf("%s:%s", tt.Name, tt.Password), func(t *testing.T) {
+			c := fs.dial(t)
+			assert.Equal(t, "Guest 1", c.User())
+
+			err := c.Login(tt.Name, tt.Password)
+
+			if tt.Err {
+				assert.ErrorIs(t, err, ErrLogin)
+				assert.Equal(t, "Guest 1", c.User())
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.Name, c.User())
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+
+	assert.Nil(t, c.SetTeam(nil))
+	assert.Equal(t, "|/utm null", fs.expect(t))
+
+	assert.Nil(t, c.Search(sim.FormatGen8Random))
+	assert.Equal(t, "|/search gen8randombattle", fs.expect(t))
+
+	assert.Nil(t, c.Challenge("Bob", sim.FormatGen7Random))
+	assert.Equal(t, "|/challenge Bob, gen7randombattle", fs.expect(t))
+
+	assert.Nil(t, c.Accept("Bob"))
+	assert.Equal(t, "|/accept Bob", fs.expect(t))
+}
+
+func TestChallenges(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+
+	fs.send(`|updatechallenges|{"challengesFrom":{"bob":"gen8randombattle"},"challengeTo":null}`)
+	fs.send(`|updatechallenges|{"challengesFrom":{"bob":"gen8randombattle","carol":"gen7randombattle"},"challengeTo":null}`)
+
+	for _, want := range []*Challenge{{"bob", "gen8randombattle"}, {"carol", "gen7randombattle"}} {
+		select {
+		case ch := <-c.Challenges():
+			assert.Equal(t, want, ch)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for challenge")
+		}
+	}
+}
+
+func TestBattle(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+
+	b := startBattle(t, fs, c)
+	assert.Equal(t, "battle-gen8randombattle-1", b.ID)
+
+	u := next(t, b)
+	assert.Equal(t, "p1", u.Side.Player)
+	assert.Equal(t, "p1a", u.Side.Field[0].ID)
+	assert.Equal(t, "tackle", u.Side.Field[0].Options.Moves[0].ID)
+	assert.Equal(t, "p1", b.Player())
+
+	for _, kind := range []string{event.Switch, event.Switch, event.Turn} {
+		u = next(t, b)
+		assert.Equal(t, kind, u.Event.Type)
+	}
+
+	err := b.Write(&sim.Action{Player: "p1", Specs: []*sim.ActionSpec{{Type: sim.ActionMove, ID: "tackle"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "battle-gen8randombattle-1|/choose move tackle|2", fs.expect(t))
+
+	fs.send(">battle-gen8randombattle-1\n|error|[Invalid choice] Can't move: Eevee doesn't have a move matching tackle")
+	u = next(t, b)
+	assert.True(t, sim.IsInvalidChoice(u.Error))
+
+	fs.send(">battle-gen8randombattle-1\n|\n|faint|p2a: Pikachu\n|win|Alice")
+	u = next(t, b)
+	assert.Equal(t, event.Faint, u.Event.Type)
+	u = next(t, b)
+	assert.Equal(t, &event.WinEvent{Player: "p1"}, u.Event.Payload)
+
+	_, ok := <-b.Updates()
+	assert.False(t, ok)
+}
+
+func TestBattleTeamPreview(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+
+	fs.send(">battle-gen8ou-7\n|init|battle")
+	fs.send(`>battle-gen8ou-7` + "\n" + `|request|{"teamPreview":true,"maxTeamSize":6,"side":{"name":"Alice","id":"p2","pokemon":[]},"rqid":1}`)
+
+	assert.Equal(t, "battle-gen8ou-7|/choose default|1", fs.expect(t))
+	b := <-c.Battles()
+	assert.Equal(t, "battle-gen8ou-7", b.ID)
+}
+
+func TestBattleStop(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+	b := startBattle(t, fs, c)
+
+	b.Stop()
+
+	assert.Equal(t, "|/leave battle-gen8randombattle-1", fs.expect(t))
+	for range b.Updates() {
+		// drain anything already given
+	}
+}
+
+func TestClose(t *testing.T) {
+	fs := newFakeServer(t)
+	c := fs.dial(t)
+	b := startBattle(t, fs, c)
+
+	c.Close()
+
+	for range b.Updates() {
+	}
+	_, ok := <-c.Battles()
+	assert.False(t, ok)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// getAssertion asks the login server for proof that we own a name.
+// Registered names need a password, others don't.
+func getAssertion(server, name, password, challstr string) (string, error) {
+	if password == "" {
+		resp, err := http.Get(server + "?" + url.Values{
+			"act":      {"getassertion"},
+			"userid":   {userID(name)},
+			"challstr": {challstr},
+		}.Encode())
+		if err != nil {
+			return "", err
+		}
+		body, err := readBody(resp)
+		if err != nil {
+			return "", err
+		}
+		return checkAssertion(name, body)
+	}
+
+	resp, err := http.PostForm(server, url.Values{
+		"act":      {"login"},
+		"name":     {name},
+		"pass":     {password},
+		"challstr": {challstr},
+	})
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return "", err
+	}
+
+	// nb. replies are prefixed with "]" to stop them being used as JSONP
+	result := struct {
+		Assertion string `json:"assertion"`
+	}{}
+	err = json.Unmarshal([]byte(strings.TrimPrefix(body, "]")), &result)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrLogin, err)
+	}
+	return checkAssertion(name, result.Assertion)
+}
+
+// checkAssertion returns an error if the login server refused to give us
+// an assertion (these start with ";")
+func checkAssertion(name, assertion string) (string, error) {
+	assertion = strings.TrimSpace(assertion)
+	switch {
+	case assertion == "":
+		return "", fmt.Errorf("%w: no assertion for %s", ErrLogin, name)
+	case strings.HasPrefix(assertion, ";;"):
+		return "", fmt.Errorf("%w: %s", ErrLogin, strings.TrimPrefix(assertion, ";;"))
+	case strings.HasPrefix(assertion, ";"):
+		return "", fmt.Errorf("%w: %s requires a password", ErrLogin, name)
+	}
+	return assertion, nil
+}
+
+// readBody reads a response, returning an error if it isn't a 200
+func readBody(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: login server replied %s", ErrLogin, resp.Status)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,325 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Action index space.
+//
+// Each slot on the field has SlotActions possible actions
+//
+//	move m (0-3), variant v, target t   m*20 + v*5 + t
+//	switch to team position p (0-5)     80 + p
+//	pass                                86
+//
+// where variants are (normal, mega, zmove, max) and targets are
+// (none, 1, 2, -1, -2) as given to the simulator.
+//
+// In doubles the action for both slots is a single index
+// (first * SlotActions + second).
+const (
+	variants = 4
+	targets  = 5
+
+	// SlotActions is the number of actions available to a single slot
+	SlotActions = MaxMoves*variants*targets + TeamSize + 1
+
+	switchOffset = MaxMoves * variants * targets
+	passIndex    = SlotActions - 1
+)
+
+var (
+	// ErrInvalidAction is returned if an action or index doesn't
+	// make sense for the given side
+	ErrInvalidAction = errors.New("invalid action")
+)
+
+// targetValues are simulator target values, by target index
+var targetValues = []int{0, 1, 2, -1, -2}
+
+// ActionSize returns the size of the action space
+func ActionSize(doubles bool) int {
+	if doubles {
+		return SlotActions * SlotActions
+	}
+	return SlotActions
+}
+
+// EncodeAction returns the index of the given action for the given side
+func EncodeAction(side *sim.Side, a *sim.Action, doubles bool) (int, error) {
+	idxs := []int{}
+	for i, spec := range a.Specs {
+		idx, err := encodeSpec(side, i, spec)
+		if err != nil {
+			return -1, err
+		}
+		idxs = append(idxs, idx)
+	}
+
+	if !doubles {
+		if len(idxs) != 1 {
+			return -1, fmt.Errorf("%w expected 1 action spec, got %d", ErrInvalidAction, len(idxs))
+		}
+		return idxs[0], nil
+	}
+
+	for len(idxs) < 2 {
+		idxs = append(idxs, passIndex)
+	}
+	return idxs[0]*SlotActions + idxs[1], nil
+}
+
+// DecodeAction returns the action for the given index & side
+func DecodeAction(side *sim.Side, index int, doubles bool) (*sim.Action, error) {
+	if index < 0 || index >= ActionSize(doubles) {
+		return nil, fmt.Errorf("%w index %d out of range", ErrInvalidAction, index)
+	}
+
+	idxs := []int{index}
+	if doubles {
+		idxs = []int{index / SlotActions, index % SlotActions}
+	}
+
+	act := &sim.Action{Player: side.Player, Specs: []*sim.ActionSpec{}}
+	for i, idx := range idxs {
+		if i >= len(side.Field) {
+			if idx != passIndex {
+				return nil, fmt.Errorf("%w no slot %d to act", ErrInvalidAction, i)
+			}
+			continue
+		}
+		spec, err := decodeSpec(side, i, idx)
+		if err != nil {
+			return nil, err
+		}
+		act.Specs = append(act.Specs, spec)
+	}
+
+	return act, nil
+}
+
+// encodeSpec returns the index of an action spec for the given slot
+func encodeSpec(side *sim.Side, slot int, spec *sim.ActionSpec) (int, error) {
+	switch spec.Type {
+	case sim.ActionPass:
+		return passIndex, nil
+	case sim.ActionSwitch:
+		pos, err := strconv.Atoi(spec.ID)
+		if err != nil || pos < 1 || pos > TeamSize {
+			return -1, fmt.Errorf("%w switch to '%s'", ErrInvalidAction, spec.ID)
+		}
+		return switchOffset + pos - 1, nil
+	}
+
+	if slot >= len(side.Field) || side.Field[slot].Options == nil {
+		return -1, fmt.Errorf("%w slot %d has no moves", ErrInvalidAction, slot)
+	}
+
+	m := moveIndex(side.Field[slot].Options.Moves, spec.ID)
+	if m < 0 {
+		return -1, fmt.Errorf("%w unknown move '%s'", ErrInvalidAction, spec.ID)
+	}
+
+	v := 0
+	switch {
+	case spec.Mega:
+		v = 1
+	case spec.ZMove:
+		v = 2
+	case spec.Max:
+		v = 3
+	}
+
+	t := -1
+	for i, value := range targetValues {
+		if value == spec.Target {
+			t = i
+		}
+	}
+	if t < 0 {
+		return -1, fmt.Errorf("%w target %d", ErrInvalidAction, spec.Target)
+	}
+
+	return m*variants*targets + v*targets + t, nil
+}
+
+// decodeSpec returns the action spec for an index for the given slot
+func decodeSpec(side *sim.Side, slot, idx int) (*sim.ActionSpec, error) {
+	switch {
+	case idx == passIndex:
+		return &sim.ActionSpec{Type: sim.ActionPass}, nil
+	case idx >= switchOffset:
+		return &sim.ActionSpec{Type: sim.ActionSwitch, ID: fmt.Sprintf("%d", idx-switchOffset+1)}, nil
+	}
+
+	opts := side.Field[slot].Options
+	m := idx / (variants * targets)
+	if opts == nil || m >= len(opts.Moves) || opts.Moves[m] == nil {
+		return nil, fmt.Errorf("%w slot %d has no move %d", ErrInvalidAction, slot, m)
+	}
+
+	v := (idx / targets) % variants
+	return &sim.ActionSpec{
+		Type:   sim.ActionMove,
+		ID:     opts.Moves[m].ID,
+		Target: targetValues[idx%targets],
+		Mega:   v == 1,
+		ZMove:  v == 2,
+		Max:    v == 3,
+	}, nil
+}
+
+// moveIndex returns the index of a move given either it's ID or
+// position (1-4) as accepted by the simulator
+func moveIndex(moves []*sim.Move, id string) int {
+	pos, err := strconv.Atoi(id)
+	if err == nil {
+		if pos < 1 || pos > len(moves) {
+			return -1
+		}
+		return pos - 1
+	}
+
+	for i, m := range moves {
+		if m != nil && strings.EqualFold(m.ID, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Mask returns a mask (1 legal, 0 not) over the action space for the
+// given side.
+func Mask(side *sim.Side, doubles bool) []float64 {
+	slots := [][]float64{}
+	for i := range side.Field {
+		slots = append(slots, slotMask(side, i))
+	}
+
+	if !doubles {
+		if len(slots) == 0 {
+			return passOnly()
+		}
+		return slots[0]
+	}
+
+	for len(slots) < 2 {
+		slots = append(slots, passOnly())
+	}
+
+	mask := make([]float64, ActionSize(true))
+	for a, ok := range slots[0] {
+		if ok == 0 {
+			continue
+		}
+		for b, ok := range slots[1] {
+			if ok == 0 || (a == b && a >= switchOffset && a != passIndex) {
+				// both slots can't switch to the same pokemon
+				continue
+			}
+			mask[a*SlotActions+b] = 1
+		}
+	}
+	return mask
+}
+
+// slotMask returns legal actions for a single slot
+func slotMask(side *sim.Side, i int) []float64 {
+	if side.Wait {
+		return passOnly()
+	}
+
+	slot := side.Field[i]
+	mask := make([]float64, SlotActions)
+
+	forced := false
+	for _, s := range side.Field {
+		forced = forced || s.Switch
+	}
+
+	bench := []int{}
+	for p, pkm := range side.Pokemon {
+		if p >= TeamSize || p < len(side.Field) || fainted(pkm) {
+			continue
+		}
+		bench = append(bench, p)
+	}
+
+	if forced {
+		if !slot.Switch || len(bench) == 0 {
+			return passOnly()
+		}
+		for _, p := range bench {
+			mask[switchOffset+p] = 1
+		}
+		return mask
+	}
+
+	if i < len(side.Pokemon) && fainted(side.Pokemon[i]) {
+		return passOnly()
+	}
+
+	if !slot.Trapped {
+		for _, p := range bench {
+			mask[switchOffset+p] = 1
+		}
+	}
+
+	opts := slot.Options
+	if opts == nil {
+		mask[passIndex] = 1
+		return mask
+	}
+
+	doubles := len(side.Field) > 1
+	for m, mv := range opts.Moves {
+		if m >= MaxMoves || mv == nil || mv.Disabled {
+			continue
+		}
+
+		tgts := []int{0}
+		if doubles && sim.RequiresDoublesTarget(mv.Target) {
+			tgts = sim.ValidDoublesTargets(mv.Target)
+		}
+
+		allowed := []bool{
+			true,
+			opts.CanMegaEvolve,
+			opts.CanZMove && m < len(opts.ZMoves) && opts.ZMoves[m] != nil,
+			opts.CanDynamax,
+		}
+		for v, ok := range allowed {
+			if !ok {
+				continue
+			}
+			for _, tv := range tgts {
+				for t, value := range targetValues {
+					if value == tv {
+						mask[m*variants*targets+v*targets+t] = 1
+					}
+				}
+			}
+		}
+	}
+
+	return mask
+}
+
+// fainted returns if the pokemon has fainted
+func fainted(p *sim.Pokemon) bool {
+	if p.Status != nil && p.Status.IsFainted {
+		return true
+	}
+	return strings.HasSuffix(p.Condition, "fnt")
+}
+
+func passOnly() []float64 {
+	mask := make([]float64, SlotActions)
+	mask[passIndex] = 1
+	return mask
+}
@@ -0,0 +1,123 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// testSide returns a side with the given number of active slots,
+// each with the given moves
+func testSide(active int, moves ...*sim.Move) *sim.Side {
+	side := &sim.Side{Player: "p1", Field: []*sim.Slot{}, Pokemon: []*sim.Pokemon{}}
+	for i := 0; i < 4; i++ {
+		side.Pokemon = append(side.Pokemon, &sim.Pokemon{
+			Index:  i,
+			Active: i < active,
+			Status: &structs.Status{HPNow: 100, HPMax: 100},
+		})
+	}
+	for i := 0; i < active; i++ {
+		side.Field = append(side.Field, &sim.Slot{
+			ID:      []string{"p1a", "p1b"}[i],
+			Index:   i,
+			Options: &sim.Options{Moves: moves},
+		})
+	}
+	return side
+}
+
+func TestActionRoundTrip(t *testing.T) {
+	side := testSide(2, &sim.Move{ID: "tackle", Target: sim.Normal}, &sim.Move{ID: "earthquake", Target: sim.Adj})
+
+	cases := []*sim.Action{
+		&sim.Action{Player: "p1", Specs: []*sim.ActionSpec{
+			&sim.ActionSpec{Type: sim.ActionMove, ID: "tackle", Target: 2},
+			&sim.ActionSpec{Type: sim.ActionMove, ID: "earthquake", Max: true},
+		}},
+		&sim.Action{Player: "p1", Specs: []*sim.ActionSpec{
+			&sim.ActionSpec{Type: sim.ActionSwitch, ID: "3"},
+			&sim.ActionSpec{Type: sim.ActionPass},
+		}},
+	}
+
+	for _, act := range cases {
+		idx, err := EncodeAction(side, act, true)
+		assert.Nil(t, err)
+
+		result, err := DecodeAction(side, idx, true)
+		assert.Nil(t, err)
+		assert.Equal(t, act, result)
+	}
+}
+
+func TestEncodeActionByPosition(t *testing.T) {
+	side := testSide(1, &sim.Move{ID: "tackle"}, &sim.Move{ID: "growl"})
+
+	a, err := EncodeAction(side, &sim.Action{Specs: []*sim.ActionSpec{&sim.ActionSpec{ID: "2"}}}, false)
+	assert.Nil(t, err)
+
+	b, err := EncodeAction(side, &sim.Action{Specs: []*sim.ActionSpec{&sim.ActionSpec{ID: "growl"}}}, false)
+	assert.Nil(t, err)
+
+	assert.Equal(t, a, b)
+	assert.Equal(t, 20, a)
+}
+
+func TestEncodeActionInvalid(t *testing.T) {
+	side := testSide(1, &sim.Move{ID: "tackle"})
+
+	_, err := EncodeAction(side, &sim.Action{Specs: []*sim.ActionSpec{&sim.ActionSpec{ID: "surf"}}}, false)
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	_, err = DecodeAction(side, 30, false)
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	_, err = DecodeAction(side, SlotActions, false)
+	assert.ErrorIs(t, err, ErrInvalidAction)
+}
+
+func TestMaskSingles(t *testing.T) {
+	side := testSide(1, &sim.Move{ID: "tackle"}, &sim.Move{ID: "growl", Disabled: true})
+	side.Pokemon[2].Status.IsFainted = true
+
+	mask := Mask(side, false)
+
+	assert.Equal(t, SlotActions, len(mask))
+	assert.Equal(t, 1.0, mask[0])         // tackle
+	assert.Equal(t, 0.0, mask[20])        // growl (disabled)
+	assert.Equal(t, 0.0, mask[80])        // switch to self
+	assert.Equal(t, 1.0, mask[81])        // switch to 2
+	assert.Equal(t, 0.0, mask[82])        // switch to 3 (fainted)
+	assert.Equal(t, 1.0, mask[83])        // switch to 4
+	assert.Equal(t, 0.0, mask[passIndex]) // pass
+}
+
+func TestMaskForcedSwitch(t *testing.T) {
+	side := testSide(2)
+	side.Field[0].Switch = true
+
+	mask := Mask(side, true)
+
+	// first slot must switch to 3 or 4, the second must pass
+	legal := []int{}
+	for i, ok := range mask {
+		if ok == 1 {
+			legal = append(legal, i)
+		}
+	}
+	assert.Equal(t, []int{82*SlotActions + passIndex, 83*SlotActions + passIndex}, legal)
+}
+
+func TestMaskDoublesNoSameSwitch(t *testing.T) {
+	side := testSide(2, &sim.Move{ID: "tackle"})
+	side.Pokemon = side.Pokemon[:3]
+
+	mask := Mask(side, true)
+
+	assert.Equal(t, 0.0, mask[82*SlotActions+82])
+	assert.Equal(t, 1.0, mask[82*SlotActions])
+	assert.Equal(t, 1.0, mask[82])
+}
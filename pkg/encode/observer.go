@@ -0,0 +1,490 @@
+package encode
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Observation is what a player can see of the battle, encoded
+type Observation struct {
+	// Features of the battle (see Schema)
+	Features []float64
+
+	// Mask of legal actions (see EncodeAction)
+	Mask []float64
+}
+
+// foe is what we've seen of an opposing pokemon
+type foe struct {
+	species string
+	level   int
+	hp      float64
+	status  string
+	fainted bool
+	moves   []string
+}
+
+// Observer watches updates from the point of view of one player, keeping
+// track of what they've been shown so it can be encoded.
+//
+// Nb. only side updates for our player are considered, so it's safe
+// to give an Observer all updates from a stream.
+type Observer struct {
+	player  string
+	doubles bool
+
+	turn int
+	side *sim.Side
+
+	// foes by ident in the order they were revealed
+	foes  map[string]*foe
+	order []string
+
+	// slots maps slot IDs (p2a) to the ident of the pokemon in them
+	slots map[string]string
+
+	// boosts by slot ID
+	boosts map[string]map[string]int
+
+	weather string
+	terrain map[string]bool
+
+	// conditions are side conditions (with layers) by player
+	conditions map[string]map[string]int
+}
+
+// NewObserver returns an Observer for the given player
+func NewObserver(player string, doubles bool) *Observer {
+	return &Observer{
+		player:     player,
+		doubles:    doubles,
+		foes:       map[string]*foe{},
+		order:      []string{},
+		slots:      map[string]string{},
+		boosts:     map[string]map[string]int{},
+		terrain:    map[string]bool{},
+		conditions: map[string]map[string]int{},
+	}
+}
+
+// Update Observer info based on update event
+func (o *Observer) Update(u *sim.Update) {
+	if u.Side != nil {
+		if u.Side.Player == o.player {
+			o.side = u.Side
+		}
+		return
+	}
+	if u.Event != nil {
+		o.event(u.Event)
+	}
+}
+
+// event updates our info from the given event
+func (o *Observer) event(e *event.Event) {
+	switch p := e.Payload.(type) {
+	case *event.TurnEvent:
+		o.turn = p.Number
+	case *event.SwitchEvent:
+		slot := p.Pokemon.String()
+		delete(o.boosts, slot)
+		o.slots[slot] = p.Pokemon.Ident()
+		if f := o.foe(p.Pokemon); f != nil {
+			f.species = p.Species
+			f.level = p.Level
+			f.hp = public(p.Percent)
+			f.status = p.Status
+		}
+	case *event.FormeEvent:
+		if f := o.foe(p.Pokemon); f != nil && p.Species != "" && e.Type != event.Replace {
+			f.species = p.Species
+		}
+	case *event.DamageEvent:
+		if f := o.foe(p.Target); f != nil {
+			f.hp = public(p.Percent)
+			f.status = p.Status
+		}
+	case *event.HealEvent:
+		if f := o.foe(p.Target); f != nil {
+			f.hp = public(p.Percent)
+			f.status = p.Status
+		}
+	case *event.StatusEvent:
+		if f := o.foe(p.Pokemon); f != nil {
+			f.status = p.Status
+			if p.Cured {
+				f.status = ""
+			}
+		}
+	case *event.FaintEvent:
+		if f := o.foe(p.Pokemon); f != nil {
+			f.fainted = true
+			f.hp = 0
+		}
+	case *event.MoveEvent:
+		f := o.foe(p.User)
+		if f == nil || e.Cause != nil {
+			// nb. moves called by other moves aren't in the moveset
+			return
+		}
+		id := data.Strip(p.Move)
+		for _, m := range f.moves {
+			if m == id {
+				return
+			}
+		}
+		if len(f.moves) < MaxMoves {
+			f.moves = append(f.moves, id)
+		}
+	case *event.BoostEvent:
+		b := o.stages(p.Pokemon)
+		if p.Set {
+			b[p.Stat] = p.Stages
+		} else {
+			b[p.Stat] = clamp(b[p.Stat]+p.Stages, -6, 6)
+		}
+	case *event.ClearBoostEvent:
+		o.clearBoosts(p)
+	case *event.InvertBoostEvent:
+		b := o.stages(p.Pokemon)
+		for k, v := range b {
+			b[k] = -v
+		}
+	case *event.SwapBoostEvent:
+		a, b := o.stages(p.Source), o.stages(p.Target)
+		for _, stat := range statsOrAll(p.Stats) {
+			a[stat], b[stat] = b[stat], a[stat]
+		}
+	case *event.CopyBoostEvent:
+		a, b := o.stages(p.Source), o.stages(p.Target)
+		for _, stat := range statsOrAll(p.Stats) {
+			a[stat] = b[stat]
+		}
+	case *event.WeatherEvent:
+		o.weather = data.Strip(p.Weather)
+	case *event.FieldEvent:
+		o.terrain[data.Strip(effectName(p.Condition))] = !p.Ended
+	case *event.SideConditionEvent:
+		c, ok := o.conditions[p.Side]
+		if !ok {
+			c = map[string]int{}
+			o.conditions[p.Side] = c
+		}
+		name := data.Strip(effectName(p.Condition))
+		if p.Ended {
+			delete(c, name)
+		} else {
+			c[name]++
+		}
+	}
+}
+
+// foe returns the foe for the given subject (creating it if needed) or nil
+// if the subject is one of ours
+func (o *Observer) foe(s *event.Subject) *foe {
+	if s == nil || s.Player == o.player {
+		return nil
+	}
+
+	ident := s.Ident()
+	f, ok := o.foes[ident]
+	if !ok {
+		f = &foe{hp: 1, moves: []string{}}
+		o.foes[ident] = f
+		o.order = append(o.order, ident)
+	}
+	return f
+}
+
+// stages returns the stat stages of the pokemon in the given slot
+func (o *Observer) stages(s *event.Subject) map[string]int {
+	if s == nil {
+		return map[string]int{}
+	}
+	b, ok := o.boosts[s.String()]
+	if !ok {
+		b = map[string]int{}
+		o.boosts[s.String()] = b
+	}
+	return b
+}
+
+// clearBoosts resets stat stages
+func (o *Observer) clearBoosts(p *event.ClearBoostEvent) {
+	if p.Pokemon == nil {
+		o.boosts = map[string]map[string]int{}
+		return
+	}
+	b := o.stages(p.Pokemon)
+	for k, v := range b {
+		if (p.Positive && v > 0) || (p.Negative && v < 0) || (!p.Positive && !p.Negative) {
+			b[k] = 0
+		}
+	}
+}
+
+// Observe returns the current observation
+func (o *Observer) Observe() *Observation {
+	vec := make([]float64, Size)
+
+	offset := 0
+	if o.side != nil {
+		for i, p := range o.side.Pokemon {
+			if i >= TeamSize {
+				break
+			}
+			o.encodeOwn(vec[offset+i*PokemonSize:], i, p)
+		}
+	}
+	offset += TeamSize * PokemonSize
+
+	for i, ident := range o.foeOrder() {
+		if i >= TeamSize {
+			break
+		}
+		o.encodeFoe(vec[offset+i*PokemonSize:], ident)
+	}
+	offset += TeamSize * PokemonSize
+
+	o.encodeField(vec[offset:])
+
+	mask := passOnly()
+	if o.doubles {
+		mask = make([]float64, ActionSize(true))
+		mask[passIndex*SlotActions+passIndex] = 1
+	}
+	if o.side != nil {
+		mask = Mask(o.side, o.doubles)
+	}
+
+	return &Observation{Features: vec, Mask: mask}
+}
+
+// foeOrder returns foe idents, active pokemon first
+func (o *Observer) foeOrder() []string {
+	active := map[string]bool{}
+	order := []string{}
+	slots := o.slotIDs()
+	for _, pos := range []string{"a", "b", "c", "d"} {
+		for _, slot := range slots {
+			ident := o.slots[slot]
+			if strings.HasSuffix(slot, pos) && !strings.HasPrefix(slot, o.player) && !active[ident] {
+				active[ident] = true
+				order = append(order, ident)
+			}
+		}
+	}
+	for _, ident := range o.order {
+		if !active[ident] {
+			order = append(order, ident)
+		}
+	}
+	return order
+}
+
+// slotIDs returns the IDs of slots we've seen pokemon in, sorted so that
+// features are always written in the same order
+func (o *Observer) slotIDs() []string {
+	out := make([]string, 0, len(o.slots))
+	for slot := range o.slots {
+		out = append(out, slot)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// encodeOwn writes one of our pokemon into vec
+func (o *Observer) encodeOwn(vec []float64, i int, p *sim.Pokemon) {
+	hp := 0.0
+	status := ""
+	if p.Status != nil {
+		if p.Status.HPMax > 0 {
+			hp = float64(p.Status.HPNow) / float64(p.Status.HPMax)
+		}
+		switch {
+		case p.Status.IsBurned:
+			status = "brn"
+		case p.Status.IsParalyzed:
+			status = "par"
+		case p.Status.IsAsleep:
+			status = "slp"
+		case p.Status.IsFrozen:
+			status = "frz"
+		case p.Status.IsToxiced:
+			status = "tox"
+		case p.Status.IsPoisoned:
+			status = "psn"
+		}
+	}
+
+	var boosts map[string]int
+	var opts *sim.Options
+	if p.Active && o.side != nil && i < len(o.side.Field) {
+		boosts = o.boosts[o.side.Field[i].ID]
+		opts = o.side.Field[i].Options
+	}
+
+	encodeMon(vec, p.Active, hp, fainted(p), status, p.Dex, boosts, p.Level)
+
+	for m, mv := range p.Moves {
+		if m >= MaxMoves || mv == nil {
+			continue
+		}
+		pp := 1.0
+		if opts != nil && m < len(opts.Moves) && opts.Moves[m] != nil && opts.Moves[m].MaxPP > 0 {
+			pp = float64(opts.Moves[m].PP) / float64(opts.Moves[m].MaxPP)
+		}
+		dex, err := data.MoveDex(mv.ID)
+		if err != nil {
+			continue
+		}
+		encodeMove(vec[MonSize+m*MoveSize:], pp, dex)
+	}
+}
+
+// encodeFoe writes an opposing pokemon into vec
+func (o *Observer) encodeFoe(vec []float64, ident string) {
+	f := o.foes[ident]
+
+	active := ""
+	for _, slot := range o.slotIDs() {
+		if o.slots[slot] == ident && !strings.HasPrefix(slot, o.player) {
+			active = slot
+			break
+		}
+	}
+
+	dex, _ := data.Species(f.species)
+	encodeMon(vec, active != "", f.hp, f.fainted, f.status, dex, o.boosts[active], f.level)
+
+	for m, id := range f.moves {
+		dex, err := data.MoveDex(id)
+		if err != nil {
+			continue
+		}
+		encodeMove(vec[MonSize+m*MoveSize:], 1, dex)
+	}
+}
+
+// encodeField writes field conditions into vec
+func (o *Observer) encodeField(vec []float64) {
+	oneHot(vec, Weathers, o.weather)
+	offset := len(Weathers)
+
+	for i, t := range Terrains {
+		if o.terrain[t] {
+			vec[offset+i] = 1
+		}
+	}
+	offset += len(Terrains)
+
+	for _, own := range []bool{true, false} {
+		for player, conds := range o.conditions {
+			if (player == o.player) != own {
+				continue
+			}
+			for i, c := range SideConditions {
+				layers, ok := conds[c]
+				if !ok {
+					continue
+				}
+				max, ok := maxLayers[c]
+				if !ok {
+					max = 1
+				}
+				vec[offset+i] = float64(clamp(layers, 0, max)) / float64(max)
+			}
+		}
+		offset += len(SideConditions)
+	}
+
+	vec[offset] = float64(o.turn) / 100
+}
+
+// encodeMon writes the (non move) features of a pokemon
+func encodeMon(vec []float64, active bool, hp float64, fnt bool, status string, dex *data.PokeDexItem, boosts map[string]int, level int) {
+	vec[0] = 1
+	if active {
+		vec[1] = 1
+	}
+	vec[2] = hp
+	if fnt {
+		vec[3] = 1
+	}
+	offset := 4
+
+	oneHot(vec[offset:], Statuses, status)
+	offset += len(Statuses)
+
+	if dex != nil {
+		for _, t := range dex.Types {
+			oneHot(vec[offset:], Types, t)
+		}
+		st := dex.Stats
+		for i, v := range []int{st.HP, st.Atk, st.Def, st.Spa, st.Spd, st.Spe} {
+			vec[offset+len(Types)+i] = float64(v) / 255
+		}
+	}
+	offset += len(Types) + 6
+
+	for i, b := range Boosts {
+		vec[offset+i] = float64(boosts[b]) / 6
+	}
+	offset += len(Boosts)
+
+	vec[offset] = float64(level) / 100
+}
+
+// encodeMove writes a move into vec
+func encodeMove(vec []float64, pp float64, dex *data.MoveDexItem) {
+	vec[0] = 1
+	vec[1] = pp
+	vec[2] = float64(dex.Power) / 250
+	vec[3] = float64(clamp(dex.Accuracy, 0, 100)) / 100
+	oneHot(vec[4:], Types, dex.Type)
+	oneHot(vec[4+len(Types):], Categories, dex.Category)
+	vec[4+len(Types)+len(Categories)] = float64(dex.Priority) / 5
+}
+
+// oneHot sets vec[i] where keys[i] == value
+func oneHot(vec []float64, keys []string, value string) {
+	for i, k := range keys {
+		if strings.EqualFold(k, value) {
+			vec[i] = 1
+		}
+	}
+}
+
+// statsOrAll returns the given stats, or all stats with stages
+func statsOrAll(in []string) []string {
+	if len(in) == 0 {
+		return Boosts
+	}
+	return in
+}
+
+// effectName returns the name of an effect without it's kind
+// ie. "move: Stealth Rock" -> "Stealth Rock"
+func effectName(in string) string {
+	bits := strings.SplitN(in, ":", 2)
+	return strings.TrimSpace(bits[len(bits)-1])
+}
+
+// public returns the HP other players are shown (a percentage) as a fraction
+func public(pct int) float64 {
+	return float64(clamp(pct, 0, 100)) / 100
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
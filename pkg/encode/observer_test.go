@@ -0,0 +1,130 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// feature returns the named feature from an observation
+func feature(t *testing.T, obs *Observation, name string) []float64 {
+	for _, f := range Schema() {
+		if f.Name == name {
+			return obs.Features[f.Offset : f.Offset+f.Size]
+		}
+	}
+	t.Fatalf("no feature %s", name)
+	return nil
+}
+
+func TestSchemaSize(t *testing.T) {
+	total := 0
+	for _, f := range Schema() {
+		assert.Equal(t, total, f.Offset)
+		total += f.Size
+	}
+	assert.Equal(t, Size, total)
+}
+
+func TestObserveFoe(t *testing.T) {
+	o := NewObserver("p1", false)
+
+	for _, line := range []string{
+		"|switch|p2a: Umbreon|Umbreon, L50|100/100",
+		"|turn|1",
+		"|move|p2a: Umbreon|Toxic|p1a: Lugia",
+		"|-damage|p2a: Umbreon|40/100 brn",
+		"|-unboost|p2a: Umbreon|atk|2",
+		"|-weather|Sandstorm",
+		"|-sidestart|p2: Red|move: Spikes",
+		"|-sidestart|p2: Red|move: Spikes",
+		"|switch|p2a: Gengar|Gengar, L50|100/100",
+		"|switch|p2a: Umbreon|Umbreon, L50|40/100 brn",
+	} {
+		o.Update(&sim.Update{Event: event.Parse(line)})
+	}
+
+	obs := o.Observe()
+	assert.Equal(t, Size, len(obs.Features))
+	assert.Equal(t, SlotActions, len(obs.Mask))
+
+	// umbreon is active so should be first
+	assert.Equal(t, []float64{1}, feature(t, obs, "foe.0.active"))
+	assert.Equal(t, []float64{0.4}, feature(t, obs, "foe.0.hp"))
+	assert.Equal(t, []float64{1, 0, 0, 0, 0, 0}, feature(t, obs, "foe.0.status"))
+	assert.Equal(t, 1.0, feature(t, obs, "foe.0.types")[15]) // dark
+	assert.Equal(t, []float64{0.5}, feature(t, obs, "foe.0.level"))
+	assert.Equal(t, []float64{1}, feature(t, obs, "foe.0.move.0.present"))
+	assert.Equal(t, []float64{0}, feature(t, obs, "foe.0.move.1.present"))
+
+	// boosts are cleared by switching out
+	assert.Equal(t, 0.0, feature(t, obs, "foe.0.boosts")[0])
+
+	assert.Equal(t, []float64{1}, feature(t, obs, "foe.1.present"))
+	assert.Equal(t, []float64{0}, feature(t, obs, "foe.1.active"))
+	assert.Equal(t, []float64{0}, feature(t, obs, "foe.2.present"))
+
+	assert.Equal(t, 1.0, feature(t, obs, "field.weather")[4])
+	assert.InDelta(t, 2.0/3.0, feature(t, obs, "field.foe.sideconditions")[1], 0.001)
+	assert.Equal(t, []float64{0.01}, feature(t, obs, "field.turn"))
+}
+
+func TestObserveIgnoresFoeSide(t *testing.T) {
+	o := NewObserver("p1", false)
+
+	side := testSide(1, &sim.Move{ID: "tackle"})
+	side.Player = "p2"
+	o.Update(&sim.Update{Side: side})
+
+	obs := o.Observe()
+	assert.Equal(t, []float64{0}, feature(t, obs, "own.0.present"))
+
+	side.Player = "p1"
+	o.Update(&sim.Update{Side: side})
+
+	obs = o.Observe()
+	assert.Equal(t, []float64{1}, feature(t, obs, "own.0.present"))
+	assert.Equal(t, []float64{1}, feature(t, obs, "own.0.hp"))
+	assert.Equal(t, 1.0, obs.Mask[0])
+}
+
+func TestObserveFoeUsesPublicHP(t *testing.T) {
+	o := NewObserver("p1", false)
+
+	o.Update(&sim.Update{Event: event.ParseSplit(
+		"|switch|p2a: Umbreon|Umbreon, L50|131/262",
+		"|switch|p2a: Umbreon|Umbreon, L50|51/100",
+	)})
+	o.Update(&sim.Update{Event: event.ParseSplit(
+		"|-damage|p2a: Umbreon|100/262",
+		"|-damage|p2a: Umbreon|39/100",
+	)})
+
+	obs := o.Observe()
+	assert.Equal(t, []float64{0.39}, feature(t, obs, "foe.0.hp"))
+}
+
+func TestObserveIsDeterministic(t *testing.T) {
+	lines := []string{
+		"|switch|p2a: Umbreon|Umbreon, L50|100/100",
+		"|switch|p4a: Gengar|Gengar, L50|100/100",
+		"|switch|p2b: Lapras|Lapras, L50|100/100",
+		"|switch|p4b: Snorlax|Snorlax, L50|100/100",
+	}
+
+	var expect []float64
+	for i := 0; i < 20; i++ {
+		o := NewObserver("p1", true)
+		for _, line := range lines {
+			o.Update(&sim.Update{Event: event.Parse(line)})
+		}
+
+		obs := o.Observe()
+		if expect == nil {
+			expect = obs.Features
+		}
+		assert.Equal(t, expect, obs.Features)
+	}
+}
@@ -0,0 +1,156 @@
+// Package encode turns what a player can observe of a battle into a fixed
+// shape vector of floats (for machine learning agents) and provides a
+// canonical index space for actions.
+//
+// The observation is laid out as
+//
+//	own team   TeamSize x PokemonSize
+//	foe team   TeamSize x PokemonSize
+//	field      FieldSize
+//
+// where each pokemon is
+//
+//	present, active, hp (fraction), fainted       4
+//	status (one hot; brn par slp frz psn tox)     6
+//	types (multi hot, see Types)                 18
+//	base stats / 255 (hp atk def spa spd spe)     6
+//	stat stages / 6 (see Boosts)                  7
+//	level / 100                                   1
+//	moves                                         MaxMoves x MoveSize
+//
+// and each move is
+//
+//	present, pp (fraction), power / 250, accuracy 4
+//	type (one hot, see Types)                    18
+//	category (one hot; physical special status)   3
+//	priority / 5                                  1
+//
+// and the field is
+//
+//	weather (one hot, see Weathers)               8
+//	terrain & rooms (see Terrains)                8
+//	own side conditions (see SideConditions)     10
+//	foe side conditions                          10
+//	turn / 100                                    1
+//
+// Own pokemon are given in the simulator's current team order (active
+// pokemon first), foe pokemon as they are revealed (active first).
+// Anything unknown (unrevealed foes, moves etc) is left as zero.
+// Schema() returns the same information programmatically.
+package encode
+
+import (
+	"fmt"
+)
+
+const (
+	TeamSize  = 6
+	MaxMoves  = 4
+	MoveSize  = 4 + 18 + 3 + 1
+	MonSize   = 4 + 6 + 18 + 6 + 7 + 1
+	FieldSize = 8 + 8 + 10 + 10 + 1
+
+	// PokemonSize is the number of features per pokemon
+	PokemonSize = MonSize + MaxMoves*MoveSize
+
+	// Size is the total length of an observation
+	Size = 2*TeamSize*PokemonSize + FieldSize
+)
+
+var (
+	// Statuses are the major status conditions, in encoded order
+	Statuses = []string{"brn", "par", "slp", "frz", "psn", "tox"}
+
+	// Types are pokemon & move types, in encoded order
+	Types = []string{
+		"Normal", "Fire", "Water", "Electric", "Grass", "Ice",
+		"Fighting", "Poison", "Ground", "Flying", "Psychic", "Bug",
+		"Rock", "Ghost", "Dragon", "Dark", "Steel", "Fairy",
+	}
+
+	// Categories are move categories, in encoded order
+	Categories = []string{"Physical", "Special", "Status"}
+
+	// Boosts are stats that have stages, in encoded order
+	Boosts = []string{"atk", "def", "spa", "spd", "spe", "accuracy", "evasion"}
+
+	// Weathers in encoded order
+	Weathers = []string{
+		"raindance", "primordialsea", "sunnyday", "desolateland",
+		"sandstorm", "hail", "snow", "deltastream",
+	}
+
+	// Terrains are terrains & other field wide conditions, in encoded order
+	Terrains = []string{
+		"electricterrain", "grassyterrain", "mistyterrain", "psychicterrain",
+		"trickroom", "gravity", "magicroom", "wonderroom",
+	}
+
+	// SideConditions in encoded order. Conditions with layers (spikes)
+	// are encoded as a fraction of the max layers.
+	SideConditions = []string{
+		"stealthrock", "spikes", "toxicspikes", "stickyweb", "reflect",
+		"lightscreen", "auroraveil", "tailwind", "safeguard", "mist",
+	}
+
+	// maxLayers of side conditions that stack
+	maxLayers = map[string]int{"spikes": 3, "toxicspikes": 2}
+)
+
+// Feature is a named range of an observation
+type Feature struct {
+	Name   string
+	Offset int
+	Size   int
+}
+
+// Schema returns the features of an observation, in order.
+func Schema() []*Feature {
+	features := []*Feature{}
+	offset := 0
+	add := func(name string, size int) {
+		features = append(features, &Feature{Name: name, Offset: offset, Size: size})
+		offset += size
+	}
+
+	for _, team := range []string{"own", "foe"} {
+		for i := 0; i < TeamSize; i++ {
+			prefix := teamPrefix(team, i)
+			add(prefix+".present", 1)
+			add(prefix+".active", 1)
+			add(prefix+".hp", 1)
+			add(prefix+".fainted", 1)
+			add(prefix+".status", len(Statuses))
+			add(prefix+".types", len(Types))
+			add(prefix+".stats", 6)
+			add(prefix+".boosts", len(Boosts))
+			add(prefix+".level", 1)
+			for m := 0; m < MaxMoves; m++ {
+				mp := movePrefix(prefix, m)
+				add(mp+".present", 1)
+				add(mp+".pp", 1)
+				add(mp+".power", 1)
+				add(mp+".accuracy", 1)
+				add(mp+".type", len(Types))
+				add(mp+".category", len(Categories))
+				add(mp+".priority", 1)
+			}
+		}
+	}
+
+	add("field.weather", len(Weathers))
+	add("field.terrain", len(Terrains))
+	add("field.own.sideconditions", len(SideConditions))
+	add("field.foe.sideconditions", len(SideConditions))
+	add("field.turn", 1)
+
+	return features
+}
+
+func teamPrefix(team string, i int) string {
+	return fmt.Sprintf("%s.%d", team, i)
+}
+
+func movePrefix(prefix string, m int) string {
+	return fmt.Sprintf("%s.move.%d", prefix, m)
+}
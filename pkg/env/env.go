@@ -0,0 +1,367 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// indirection allows for easier testing
+	newStream = sim.NewSimulatorStream
+
+	// ErrDone is returned if Step is called after the battle has finished
+	ErrDone = errors.New("battle is over, call Reset")
+
+	// ErrNotStarted is returned if Step is called before Reset
+	ErrNotStarted = errors.New("battle not started, call Reset")
+
+	// ErrOpponentRetries is returned if the opponent's choices keep
+	// being rejected
+	ErrOpponentRetries = errors.New("too many invalid opponent choices")
+)
+
+// maxRetries is how many rejected choices we replace for the opponent
+// (with a random one) before giving up
+const maxRetries = 10
+
+// Info is additional information about a step
+type Info struct {
+	Turn   int
+	Winner string
+	Tie    bool
+	Stats  *Stats
+
+	// Terminal is the final observation of a battle that a VecEnv has
+	// automatically reset (if any)
+	Terminal *encode.Observation
+}
+
+// Option is some option for New
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Player string
+	Reward RewardFunc
+}
+
+func buildOpts(in []Option) *opts {
+	cfg := &opts{Player: "p1", Reward: WinLoss}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Player sets which player (p1, p2) the agent controls. Defaults to p1.
+func Player(name string) Option {
+	return func(o *opts) {
+		o.Player = name
+	}
+}
+
+// Reward sets the reward function. Defaults to WinLoss.
+func Reward(fn RewardFunc) Option {
+	return func(o *opts) {
+		o.Reward = fn
+	}
+}
+
+// Env is a reinforcement learning environment where an agent battles
+// against the given opponent. Requests for the opponent, forced switches
+// & requests where the agent has nothing to do are handled internally.
+//
+// The simulator sends requests before the events that lead up to them, so
+// control is given to the agent once the turn starts (or end of turn
+// upkeep) after it's request. Mid-turn switches (ie. U-turn) have no such
+// marker, so the agent is given control as soon as the request arrives
+// & the events leading up to it are in the next observation.
+type Env struct {
+	spec     *sim.BattleSpec
+	opponent player.Player
+	cfg      *opts
+	doubles  bool
+
+	stream   sim.SimulatorStream
+	obs      *encode.Observer
+	sides    map[string]*sim.Side
+	request  *sim.Side
+	settled  bool
+	fallback player.Player
+	retries  int
+
+	turn   int
+	done   bool
+	winner string
+	tie    bool
+}
+
+// New returns a new environment for the given battle spec.
+// Nb. the battle doesn't start until Reset is called.
+func New(spec *sim.BattleSpec, opponent player.Player, in ...Option) *Env {
+	return &Env{
+		spec:     spec,
+		opponent: opponent,
+		cfg:      buildOpts(in),
+		doubles:  sim.IsDoubles(spec.Format),
+	}
+}
+
+// ActionSize returns the size of the action space
+func (e *Env) ActionSize() int {
+	return encode.ActionSize(e.doubles)
+}
+
+// Reset starts a new battle with the given seed & returns the first
+// observation.
+func (e *Env) Reset(seed sim.Seed) (*encode.Observation, error) {
+	e.Close()
+
+	spec := *e.spec
+	spec.Seed = seed
+
+	stream, err := newStream(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	e.stream = stream
+	e.obs = encode.NewObserver(e.cfg.Player, e.doubles)
+	e.sides = map[string]*sim.Side{}
+	e.request = nil
+	e.settled = false
+	e.fallback = player.NewRandom(int64(seed.Uint64()))
+	e.retries = 0
+	e.turn = 0
+	e.done = false
+	e.winner = ""
+	e.tie = false
+
+	err = e.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.obs.Observe(), nil
+}
+
+// Step sends the given action (see encode.DecodeAction) to the simulator and
+// returns the resulting observation, reward, if the battle is over & info.
+//
+// If the action is rejected by the simulator the returned error will match
+// sim.IsInvalidChoice or sim.IsUnavailableChoice & the agent should choose
+// again.
+func (e *Env) Step(action int) (*encode.Observation, float64, bool, *Info, error) {
+	if e.stream == nil {
+		return nil, 0, false, nil, ErrNotStarted
+	}
+	if e.done || e.request == nil {
+		return nil, 0, true, e.info(), ErrDone
+	}
+
+	act, err := encode.DecodeAction(e.request, action, e.doubles)
+	if err != nil {
+		return nil, 0, false, e.info(), err
+	}
+
+	prev := e.stats()
+
+	err = e.stream.Write(act)
+	if err != nil {
+		return nil, 0, false, e.info(), err
+	}
+
+	request := e.request
+	e.request = nil
+
+	err = e.advance()
+	if err != nil {
+		if e.request == nil && (sim.IsInvalidChoice(err) || sim.IsUnavailableChoice(err)) {
+			// the same request stands
+			e.request = request
+			e.settled = true
+		}
+		return e.obs.Observe(), 0, e.done, e.info(), err
+	}
+
+	next := e.stats()
+	return e.obs.Observe(), e.cfg.Reward(prev, next), e.done, e.info(), nil
+}
+
+// Close stops the running battle (if any)
+func (e *Env) Close() {
+	if e.stream != nil {
+		e.stream.Stop()
+		e.stream = nil
+	}
+}
+
+// advance reads updates until the agent needs to act or the battle ends
+func (e *Env) advance() error {
+	for !e.done && !(e.request != nil && e.settled) {
+		u, ok := <-e.stream.Updates()
+		if !ok {
+			e.done = true
+			return nil
+		}
+		err := e.update(u)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update handles a single update from the simulator
+func (e *Env) update(u *sim.Update) error {
+	e.obs.Update(u)
+
+	if u.Error != nil {
+		if u.Player != "" && u.Player != e.cfg.Player {
+			return e.opponentError(u)
+		}
+		return u.Error
+	}
+
+	if u.Side != nil {
+		e.sides[u.Side.Player] = u.Side
+		if u.Side.Wait {
+			return nil
+		}
+
+		if u.Side.Player == e.cfg.Player {
+			e.request = u.Side
+			e.settled = midTurn(u.Side)
+			return nil
+		}
+
+		e.retries = 0
+		act, err := e.opponent.Choose(u.Side)
+		if err != nil {
+			return fmt.Errorf("%w opponent failed to choose", err)
+		}
+		return e.stream.Write(act)
+	}
+
+	if u.Event == nil {
+		return nil
+	}
+
+	switch u.Event.Type {
+	case event.Turn:
+		e.turn = u.Event.Magnitude
+		e.settled = true
+	case event.Upkeep:
+		e.settled = true
+	case event.Win:
+		e.done = true
+		e.winner = u.Event.Name
+	case event.Tie:
+		e.done = true
+		e.tie = true
+	}
+
+	return nil
+}
+
+// opponentError handles an error for the opponent. Rejected choices are
+// replaced with a random one, since the opponent may well choose the
+// same again.
+func (e *Env) opponentError(u *sim.Update) error {
+	if sim.IsUnavailableChoice(u.Error) {
+		// the simulator sends a new request with what it revealed
+		return nil
+	}
+	if !sim.IsInvalidChoice(u.Error) {
+		return u.Error
+	}
+
+	side, ok := e.sides[u.Player]
+	if !ok {
+		return u.Error
+	}
+	e.retries++
+	if e.retries > maxRetries {
+		return fmt.Errorf("%w %v", ErrOpponentRetries, u.Error)
+	}
+
+	act, err := e.fallback.Choose(side)
+	if err != nil {
+		return fmt.Errorf("%w opponent failed to choose", err)
+	}
+	return e.stream.Write(act)
+}
+
+// midTurn returns if the request is to switch out a pokemon that hasn't
+// fainted (ie. after U-turn), which happens mid-turn
+func midTurn(side *sim.Side) bool {
+	for _, slot := range side.Field {
+		if !slot.Switch || slot.Index >= len(side.Pokemon) {
+			continue
+		}
+		p := side.Pokemon[slot.Index]
+		if p.Status == nil || !p.Status.IsFainted {
+			return true
+		}
+	}
+	return false
+}
+
+// info returns info on the current state
+func (e *Env) info() *Info {
+	return &Info{Turn: e.turn, Winner: e.winner, Tie: e.tie, Stats: e.stats()}
+}
+
+// stats returns the current battle stats
+func (e *Env) stats() *Stats {
+	st := &Stats{Done: e.done, Tie: e.tie}
+
+	for _, team := range e.spec.Players {
+		if len(team) > st.TeamSize {
+			st.TeamSize = len(team)
+		}
+	}
+
+	for name, side := range e.sides {
+		// nb. random battles don't give teams in the spec
+		if len(side.Pokemon) > st.TeamSize {
+			st.TeamSize = len(side.Pokemon)
+		}
+
+		hp, fainted := sideHealth(side)
+		if name == e.cfg.Player {
+			st.OwnHP, st.OwnFainted = hp, fainted
+		} else {
+			st.FoeHP, st.FoeFainted = hp, fainted
+		}
+	}
+
+	// nb. the simulator names players by their ID (p1, p2)
+	st.Won = e.done && !e.tie && e.winner == e.cfg.Player
+
+	return st
+}
+
+// sideHealth returns the total HP (as fractions) & number of fainted pokemon
+func sideHealth(side *sim.Side) (float64, int) {
+	hp := 0.0
+	fainted := 0
+	for _, p := range side.Pokemon {
+		if p.Status == nil {
+			continue
+		}
+		if p.Status.IsFainted {
+			fainted++
+			continue
+		}
+		if p.Status.HPMax > 0 {
+			hp += float64(p.Status.HPNow) / float64(p.Status.HPMax)
+		}
+	}
+	return hp, fainted
+}
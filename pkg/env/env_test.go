@@ -0,0 +1,181 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/simtest"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+func withStream(t *testing.T, fn func(spec *sim.BattleSpec) (sim.SimulatorStream, error)) {
+	orig := newStream
+	newStream = fn
+	t.Cleanup(func() { newStream = orig })
+}
+
+func TestEnvPlaysBattle(t *testing.T) {
+	var seed sim.Seed
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		seed = spec.Seed
+		return simtest.Battle(), nil
+	})
+
+	e := New(simtest.Spec(), player.NewRandom(0), Reward(Sum(WinLoss, HPDifferential)))
+
+	obs, err := e.Reset(sim.Seed{1, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.Equal(t, sim.Seed{1, 2, 3, 4}, seed)
+	assert.Equal(t, encode.Size, len(obs.Features))
+	assert.Equal(t, e.ActionSize(), len(obs.Mask))
+
+	_, reward, done, info, err := e.Step(0)
+	assert.Nil(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 0.5, reward)
+	assert.Equal(t, 2, info.Turn)
+
+	_, reward, done, info, err = e.Step(0)
+	assert.Nil(t, err)
+	assert.True(t, done)
+	assert.Equal(t, 1.5, reward)
+	assert.Equal(t, "p1", info.Winner)
+	assert.True(t, info.Stats.Won)
+
+	_, _, _, _, err = e.Step(0)
+	assert.ErrorIs(t, err, ErrDone)
+}
+
+func TestEnvInvalidChoiceKeepsRequest(t *testing.T) {
+	stream := simtest.Battle()
+	stream.Reject("p1", 1)
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return stream, nil
+	})
+
+	e := New(simtest.Spec(), player.NewRandom(0))
+	_, err := e.Reset(sim.NewSeed(1))
+	assert.Nil(t, err)
+
+	_, _, _, _, err = e.Step(0)
+	assert.True(t, sim.IsInvalidChoice(err))
+
+	_, _, done, info, err := e.Step(0)
+	assert.Nil(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 2, info.Turn)
+}
+
+func TestEnvStepBeforeReset(t *testing.T) {
+	e := New(simtest.Spec(), player.NewRandom(0))
+
+	_, _, _, _, err := e.Step(0)
+
+	assert.ErrorIs(t, err, ErrNotStarted)
+}
+
+func TestEnvRetriesOpponent(t *testing.T) {
+	stream := simtest.Battle()
+	stream.Reject("p2", 1)
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return stream, nil
+	})
+
+	e := New(simtest.Spec(), player.NewRandom(0))
+	_, err := e.Reset(sim.NewSeed(1))
+	assert.Nil(t, err)
+
+	// the opponent's choice is rejected, which isn't the agent's fault
+	_, _, done, info, err := e.Step(0)
+	assert.Nil(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 2, info.Turn)
+}
+
+func TestEnvOpponentRetriesExhausted(t *testing.T) {
+	stream := simtest.Battle()
+	stream.Reject("p2", maxRetries+1)
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return stream, nil
+	})
+
+	e := New(simtest.Spec(), player.NewRandom(0))
+	_, err := e.Reset(sim.NewSeed(1))
+	assert.Nil(t, err)
+
+	_, _, _, _, err = e.Step(0)
+	assert.ErrorIs(t, err, ErrOpponentRetries)
+}
+
+func TestEnvMidTurnSwitch(t *testing.T) {
+	// p1 has to switch out mid-turn (ie. after U-turn), so there's no turn
+	// or upkeep to wait for
+	uturn := simtest.Request("p1", 100)
+	uturn.Side.Field[0].Switch = true
+	uturn.Side.Field[0].Options = nil
+	uturn.Side.Pokemon = append(uturn.Side.Pokemon, &sim.Pokemon{
+		Status: &structs.Status{HPNow: 100, HPMax: 100},
+	})
+
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return simtest.NewStream(
+			[]*sim.Update{
+				simtest.Request("p1", 100),
+				simtest.Request("p2", 100),
+				&sim.Update{Event: event.Parse("|turn|1")},
+			},
+			[]*sim.Update{
+				uturn,
+				simtest.Request("p2", 0),
+				&sim.Update{Event: event.Parse("|move|p1a: Lugia|U-turn|p2a: Umbreon")},
+			},
+		), nil
+	})
+
+	e := New(simtest.Spec(), player.NewRandom(0))
+	_, err := e.Reset(sim.NewSeed(1))
+	assert.Nil(t, err)
+
+	_, _, done, info, err := e.Step(0)
+	assert.Nil(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 1, info.Turn)
+	assert.Equal(t, uturn.Side, e.request)
+}
+
+func TestVecEnvAutoResets(t *testing.T) {
+	seeds := make(chan sim.Seed, 10)
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		seeds <- spec.Seed
+		return simtest.Battle(), nil
+	})
+
+	v := NewVecEnv(2, simtest.Spec(), func() player.Player { return player.NewRandom(0) })
+	defer v.Close()
+
+	_, err := v.Reset([]sim.Seed{sim.NewSeed(1), sim.NewSeed(2)})
+	assert.Nil(t, err)
+
+	v.Step([]int{0, 0})
+	results := v.Step([]int{0, 0})
+
+	for _, r := range results {
+		assert.Nil(t, r.Err)
+		assert.True(t, r.Done)
+		assert.NotNil(t, r.Info.Terminal)
+		assert.NotNil(t, r.Obs)
+	}
+
+	close(seeds)
+	found := []sim.Seed{}
+	for s := range seeds {
+		found = append(found, s)
+	}
+	assert.ElementsMatch(t, []sim.Seed{
+		sim.NewSeed(1), sim.NewSeed(2), sim.NewSeed(1).Derive(1), sim.NewSeed(2).Derive(1),
+	}, found)
+}
@@ -0,0 +1,72 @@
+package env
+
+// Stats summarise a battle at some point in time, for reward functions.
+// HP is the sum of each pokemon's HP as a fraction of it's max HP.
+type Stats struct {
+	OwnHP      float64
+	FoeHP      float64
+	OwnFainted int
+	FoeFainted int
+
+	// TeamSize is the larger of the two team sizes
+	TeamSize int
+
+	Done bool
+	Won  bool
+	Tie  bool
+}
+
+// RewardFunc returns the reward for moving from prev to next
+type RewardFunc func(prev, next *Stats) float64
+
+// WinLoss rewards 1 for a win, -1 for a loss & 0 otherwise
+func WinLoss(prev, next *Stats) float64 {
+	switch {
+	case !next.Done || next.Tie:
+		return 0
+	case next.Won:
+		return 1
+	}
+	return -1
+}
+
+// HPDifferential rewards damage dealt & penalises damage taken, as a
+// fraction of the whole team's HP
+func HPDifferential(prev, next *Stats) float64 {
+	if next.TeamSize == 0 {
+		return 0
+	}
+	own := next.OwnHP - prev.OwnHP
+	foe := next.FoeHP - prev.FoeHP
+	return (own - foe) / float64(next.TeamSize)
+}
+
+// FaintDifferential rewards fainting foes & penalises our pokemon fainting,
+// as a fraction of the team size
+func FaintDifferential(prev, next *Stats) float64 {
+	if next.TeamSize == 0 {
+		return 0
+	}
+	own := next.OwnFainted - prev.OwnFainted
+	foe := next.FoeFainted - prev.FoeFainted
+	return float64(foe-own) / float64(next.TeamSize)
+}
+
+// Scale returns a reward function that multiplies the result of fn by w
+func Scale(w float64, fn RewardFunc) RewardFunc {
+	return func(prev, next *Stats) float64 {
+		return w * fn(prev, next)
+	}
+}
+
+// Sum returns a reward function that adds up the results of the given
+// functions. Ie. Sum(WinLoss, Scale(0.1, HPDifferential))
+func Sum(fns ...RewardFunc) RewardFunc {
+	return func(prev, next *Stats) float64 {
+		total := 0.0
+		for _, fn := range fns {
+			total += fn(prev, next)
+		}
+		return total
+	}
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestRewards = []struct {
+	Name   string
+	Fn     RewardFunc
+	Prev   *Stats
+	Next   *Stats
+	Expect float64
+}{
+	{
+		"win",
+		WinLoss,
+		&Stats{},
+		&Stats{Done: true, Won: true},
+		1,
+	},
+	{
+		"loss",
+		WinLoss,
+		&Stats{},
+		&Stats{Done: true},
+		-1,
+	},
+	{
+		"tie",
+		WinLoss,
+		&Stats{},
+		&Stats{Done: true, Tie: true},
+		0,
+	},
+	{
+		"hp",
+		HPDifferential,
+		&Stats{OwnHP: 2, FoeHP: 2, TeamSize: 2},
+		&Stats{OwnHP: 1.5, FoeHP: 1, TeamSize: 2},
+		0.25,
+	},
+	{
+		"faint",
+		FaintDifferential,
+		&Stats{TeamSize: 4},
+		&Stats{FoeFainted: 2, OwnFainted: 1, TeamSize: 4},
+		0.25,
+	},
+	{
+		"sum",
+		Sum(WinLoss, Scale(2, FaintDifferential)),
+		&Stats{TeamSize: 2},
+		&Stats{FoeFainted: 2, TeamSize: 2, Done: true, Won: true},
+		3,
+	},
+}
+
+func TestRewards(t *testing.T) {
+	for _, tt := range dataTestRewards {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expect, tt.Fn(tt.Prev, tt.Next))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package env
+
+import (
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// StepResult is the result of a single environment in a VecEnv
+type StepResult struct {
+	Obs    *encode.Observation
+	Reward float64
+	Done   bool
+	Info   *Info
+	Err    error
+}
+
+// VecEnv runs a number of environments in parallel. Environments that finish
+// are automatically reset; the final observation is given in Info.Terminal.
+type VecEnv struct {
+	envs     []*Env
+	seeds    []sim.Seed
+	episodes []int
+}
+
+// NewVecEnv returns n environments for the given spec. Opponent is called
+// once per environment since players need not be safe for concurrent use.
+func NewVecEnv(n int, spec *sim.BattleSpec, opponent func() player.Player, in ...Option) *VecEnv {
+	envs := make([]*Env, n)
+	for i := range envs {
+		envs[i] = New(spec, opponent(), in...)
+	}
+	return &VecEnv{envs: envs, seeds: make([]sim.Seed, n), episodes: make([]int, n)}
+}
+
+// Len returns the number of environments
+func (v *VecEnv) Len() int {
+	return len(v.envs)
+}
+
+// Reset resets all environments, each with the matching seed.
+// Battles started by automatic resets use seeds derived from these.
+func (v *VecEnv) Reset(seeds []sim.Seed) ([]*encode.Observation, error) {
+	obs := make([]*encode.Observation, len(v.envs))
+	errs := make([]error, len(v.envs))
+
+	v.parallel(func(i int, e *Env) {
+		v.seeds[i] = seeds[i]
+		v.episodes[i] = 0
+		obs[i], errs[i] = e.Reset(seeds[i])
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return obs, err
+		}
+	}
+	return obs, nil
+}
+
+// Step steps each environment with the matching action.
+func (v *VecEnv) Step(actions []int) []*StepResult {
+	results := make([]*StepResult, len(v.envs))
+
+	v.parallel(func(i int, e *Env) {
+		obs, reward, done, info, err := e.Step(actions[i])
+		result := &StepResult{Obs: obs, Reward: reward, Done: done, Info: info, Err: err}
+		results[i] = result
+
+		if !done || err != nil {
+			return
+		}
+
+		v.episodes[i]++
+		next, err := e.Reset(v.seeds[i].Derive(v.episodes[i]))
+		if info != nil {
+			info.Terminal = obs
+		}
+		result.Obs = next
+		result.Err = err
+	})
+
+	return results
+}
+
+// Close stops all environments
+func (v *VecEnv) Close() {
+	for _, e := range v.envs {
+		e.Close()
+	}
+}
+
+// parallel calls fn for each env & waits for them all to finish
+func (v *VecEnv) parallel(fn func(i int, e *Env)) {
+	var wg sync.WaitGroup
+	for i, e := range v.envs {
+		wg.Add(1)
+		go func(i int, e *Env) {
+			defer wg.Done()
+			fn(i, e)
+		}(i, e)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,182 @@
+package event
+
+import (
+	"strings"
+)
+
+// CauseKind is the kind of effect that caused an event
+type CauseKind string
+
+const (
+	CauseAbility CauseKind = "ability"
+	CauseItem    CauseKind = "item"
+	CauseMove    CauseKind = "move"
+	CauseStatus  CauseKind = "status"
+	CauseWeather CauseKind = "weather"
+
+	// CauseOther is anything else (side conditions, confusion, recoil ..)
+	CauseOther CauseKind = "other"
+)
+
+// statuses are major status conditions as written by the simulator
+var statuses = map[string]bool{
+	"brn": true,
+	"par": true,
+	"slp": true,
+	"frz": true,
+	"psn": true,
+	"tox": true,
+}
+
+// weathers are weather conditions as written by the simulator
+var weathers = map[string]bool{
+	"raindance":     true,
+	"primordialsea": true,
+	"sunnyday":      true,
+	"desolateland":  true,
+	"sandstorm":     true,
+	"hail":          true,
+	"snow":          true,
+	"deltastream":   true,
+}
+
+// Cause is what caused an event to happen, as given by the [from] & [of] tags
+// ie. "|-damage|p2a: Umbreon|50/100|[from] ability: Rough Skin|[of] p1a: Garchomp"
+// is caused by the ability Rough Skin, of the pokemon in p1a.
+type Cause struct {
+	Kind CauseKind
+
+	// Name of the ability, item, move etc
+	Name string
+
+	// Source is the pokemon responsible (if given)
+	Source *Subject
+}
+
+// String returns the cause as the simulator would write it (without [of])
+func (c *Cause) String() string {
+	switch c.Kind {
+	case CauseAbility, CauseItem, CauseMove:
+		return string(c.Kind) + ": " + c.Name
+	}
+	return c.Name
+}
+
+// Flags are [tag]s given on events that don't carry a value (or carry a
+// value that is best represented more specifically).
+type Flags struct {
+	// Silent indicates the event should not be announced
+	Silent bool
+
+	// Still indicates a move has no animation (ie. it failed, or is the
+	// first turn of a charge move)
+	Still bool
+
+	// Miss indicates a move missed
+	Miss bool
+
+	// Spread holds the targets of a spread move
+	Spread []*Subject
+
+	// ZEffect indicates an effect was caused by a Z-move
+	ZEffect bool
+
+	// Anim is the animation to use for a move, if not the move's own
+	Anim string
+
+	// Upkeep indicates a residual (end of turn) effect
+	Upkeep bool
+
+	// Msg indicates the event should be announced with a message
+	Msg bool
+
+	// Fatigue indicates an effect ended due to fatigue (outrage confusion)
+	Fatigue bool
+
+	// Eat indicates a berry was eaten
+	Eat bool
+
+	// Consumed indicates an item was used up (rather than eaten)
+	Consumed bool
+
+	// Weaken indicates a berry weakened an attack
+	Weaken bool
+
+	// Damage indicates an effect that deals damage (ie. partial trapping)
+	Damage bool
+
+	// NoTarget indicates a move had no target
+	NoTarget bool
+}
+
+// parseCause builds the cause of an event from it's metadata (if any)
+func parseCause(e *Event) *Cause {
+	from, ok := e.Metadata["from"]
+	if !ok || from == "" {
+		return nil
+	}
+
+	c := &Cause{Kind: CauseOther, Name: from}
+
+	bits := strings.SplitN(from, ":", 2)
+	if len(bits) == 2 {
+		switch CauseKind(strings.ToLower(strings.TrimSpace(bits[0]))) {
+		case CauseAbility:
+			c.Kind = CauseAbility
+			c.Name = strings.TrimSpace(bits[1])
+		case CauseItem:
+			c.Kind = CauseItem
+			c.Name = strings.TrimSpace(bits[1])
+		case CauseMove:
+			c.Kind = CauseMove
+			c.Name = strings.TrimSpace(bits[1])
+		}
+	} else if statuses[from] {
+		c.Kind = CauseStatus
+	} else if weathers[strings.ToLower(strings.Replace(from, " ", "", -1))] {
+		c.Kind = CauseWeather
+	}
+
+	of, ok := e.Metadata["of"]
+	if ok {
+		c.Source = e.subject(of)
+	}
+
+	return c
+}
+
+// parseFlags builds flags from an event's metadata
+func parseFlags(e *Event) Flags {
+	has := func(key string) bool {
+		_, ok := e.Metadata[key]
+		return ok
+	}
+
+	f := Flags{
+		Silent:   has("silent"),
+		Still:    has("still"),
+		Miss:     has("miss"),
+		ZEffect:  has("zeffect"),
+		Anim:     e.Metadata["anim"],
+		Upkeep:   has("upkeep"),
+		Msg:      has("msg"),
+		Fatigue:  has("fatigue"),
+		Eat:      has("eat"),
+		Consumed: has("consumed"),
+		Weaken:   has("weaken"),
+		Damage:   has("damage"),
+		NoTarget: has("notarget"),
+	}
+
+	spread, ok := e.Metadata["spread"]
+	if ok {
+		for _, slot := range strings.Split(spread, ",") {
+			sub := e.subject(slot)
+			if sub != nil {
+				f.Spread = append(f.Spread, sub)
+			}
+		}
+	}
+
+	return f
+}
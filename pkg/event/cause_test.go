@@ -0,0 +1,107 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestParseCause = []struct {
+	In     string
+	Expect *Cause
+}{
+	{
+		"|-damage|p2a: Umbreon|50/100|[from] ability: Rough Skin|[of] p1a: Garchomp",
+		&Cause{
+			Kind:   CauseAbility,
+			Name:   "Rough Skin",
+			Source: &Subject{Player: "p1", Position: "a", Nickname: "Garchomp"},
+		},
+	},
+	{
+		"|-heal|p2a: Umbreon|100/100|[from] item: Leftovers",
+		&Cause{Kind: CauseItem, Name: "Leftovers"},
+	},
+	{
+		"|-sethp|p2a: Umbreon|50/100|[from] move: Pain Split|[silent]",
+		&Cause{Kind: CauseMove, Name: "Pain Split"},
+	},
+	{
+		"|-damage|p2a: Umbreon|45/100 tox|[from] psn",
+		&Cause{Kind: CauseStatus, Name: "psn"},
+	},
+	{
+		"|-damage|p2a: Umbreon|94/100|[from] Sandstorm",
+		&Cause{Kind: CauseWeather, Name: "Sandstorm"},
+	},
+	{
+		"|-damage|p1a: Lugia|80/100|[from] Stealth Rock",
+		&Cause{Kind: CauseOther, Name: "Stealth Rock"},
+	},
+	{
+		"|-damage|p1a: Lugia|80/100",
+		nil,
+	},
+}
+
+func TestParseCause(t *testing.T) {
+	for _, tt := range dataTestParseCause {
+		t.Run(tt.In, func(t *testing.T) {
+			result := Parse(tt.In)
+
+			assert.Equal(t, tt.Expect, result.Cause)
+		})
+	}
+}
+
+func TestCauseString(t *testing.T) {
+	assert.Equal(t, "item: Leftovers", (&Cause{Kind: CauseItem, Name: "Leftovers"}).String())
+	assert.Equal(t, "psn", (&Cause{Kind: CauseStatus, Name: "psn"}).String())
+}
+
+var dataTestParseFlags = []struct {
+	In     string
+	Expect Flags
+}{
+	{
+		"|-sethp|p2a: Umbreon|50/100|[from] move: Pain Split|[silent]",
+		Flags{Silent: true},
+	},
+	{
+		"|move|p2a: Umbreon|Protect||[still]",
+		Flags{Still: true},
+	},
+	{
+		"|-clearnegativeboost|p1a: Pikachu|[zeffect]",
+		Flags{ZEffect: true},
+	},
+	{
+		"|move|p1a: Necrozma|Photon Geyser|p2a: Umbreon|[anim] Prismatic Laser",
+		Flags{Anim: "Prismatic Laser"},
+	},
+	{
+		"|-enditem|p1a: Pikachu|Sitrus Berry|[eat]",
+		Flags{Eat: true},
+	},
+	{
+		"|-start|p1a: Pikachu|confusion|[fatigue]",
+		Flags{Fatigue: true},
+	},
+	{
+		"|move|p1a: Lugia|Earthquake|p2a: Umbreon|[spread] p2a,p2b",
+		Flags{Spread: []*Subject{
+			&Subject{Player: "p2", Position: "a"},
+			&Subject{Player: "p2", Position: "b"},
+		}},
+	},
+}
+
+func TestParseFlags(t *testing.T) {
+	for _, tt := range dataTestParseFlags {
+		t.Run(tt.In, func(t *testing.T) {
+			result := Parse(tt.In)
+
+			assert.Equal(t, tt.Expect, result.Flags)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package event
+
+import (
+	"reflect"
+)
+
+// Copy returns a deep copy of the event, including it's cause & payload.
+// Subjects shared by the event & it's payload are shared in the copy.
+func (e *Event) Copy() *Event {
+	subjects := map[*Subject]*Subject{}
+	subject := func(s *Subject) *Subject {
+		if s == nil {
+			return nil
+		}
+		c, ok := subjects[s]
+		if !ok {
+			cp := *s
+			c = &cp
+			subjects[s] = c
+		}
+		return c
+	}
+
+	causes := map[*Cause]*Cause{}
+	cause := func(c *Cause) *Cause {
+		if c == nil {
+			return nil
+		}
+		cp, ok := causes[c]
+		if !ok {
+			v := *c
+			v.Source = subject(c.Source)
+			cp = &v
+			causes[c] = cp
+		}
+		return cp
+	}
+
+	out := *e
+	out.Subject = subject(e.Subject)
+	out.Targets = subjectList(e.Targets, subject)
+	out.Cause = cause(e.Cause)
+	out.Flags.Spread = subjectList(e.Flags.Spread, subject)
+	if e.Metadata != nil {
+		out.Metadata = make(map[string]string, len(e.Metadata))
+		for k, v := range e.Metadata {
+			out.Metadata[k] = v
+		}
+	}
+	out.Payload = copyPayload(e.Payload, subject, cause)
+	return &out
+}
+
+// Subjects returns every subject the event refers to (including those of
+// it's cause & payload), once each.
+func (e *Event) Subjects() []*Subject {
+	seen := map[*Subject]bool{}
+	found := []*Subject{}
+	subject := func(s *Subject) *Subject {
+		if s != nil && !seen[s] {
+			seen[s] = true
+			found = append(found, s)
+		}
+		return s
+	}
+	cause := func(c *Cause) *Cause {
+		if c != nil {
+			subject(c.Source)
+		}
+		return c
+	}
+
+	subject(e.Subject)
+	subjectList(e.Targets, subject)
+	cause(e.Cause)
+	subjectList(e.Flags.Spread, subject)
+	copyPayload(e.Payload, subject, cause)
+	return found
+}
+
+// subjectList returns a new list of the given subjects, passed through fn
+func subjectList(in []*Subject, fn func(*Subject) *Subject) []*Subject {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Subject, len(in))
+	for i, s := range in {
+		out[i] = fn(s)
+	}
+	return out
+}
+
+// copyPayload returns a copy of a payload (a pointer to one of the structs
+// in payload.go) with it's subjects & causes passed through the given funcs
+func copyPayload(payload interface{}, subject func(*Subject) *Subject, cause func(*Cause) *Cause) interface{} {
+	v := reflect.ValueOf(payload)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return payload
+	}
+
+	out := reflect.New(v.Elem().Type())
+	out.Elem().Set(v.Elem())
+	for i := 0; i < out.Elem().NumField(); i++ {
+		f := out.Elem().Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch value := f.Interface().(type) {
+		case *Subject:
+			f.Set(reflect.ValueOf(subject(value)))
+		case []*Subject:
+			f.Set(reflect.ValueOf(subjectList(value, subject)))
+		case *Cause:
+			f.Set(reflect.ValueOf(cause(value)))
+		case []string:
+			if value != nil {
+				f.Set(reflect.ValueOf(append([]string{}, value...)))
+			}
+		}
+	}
+	return out.Interface()
+}
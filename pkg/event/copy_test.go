@@ -0,0 +1,54 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestCopy = []string{
+	"|move|p1a: Lugia|Surf|p2a: Umbreon|[spread] p2a,p2b",
+	"|-damage|p2a: Umbreon|50/100|[from] ability: Rough Skin|[of] p1a: Garchomp",
+	"|switch|p2a: Umbreon|Umbreon, L50|200/200",
+	"|turn|3",
+}
+
+func TestCopy(t *testing.T) {
+	for _, line := range dataTestCopy {
+		t.Run(line, func(t *testing.T) {
+			evt := Parse(line)
+
+			cp := evt.Copy()
+
+			assert.Equal(t, evt, cp)
+			assert.Equal(t, len(evt.Subjects()), len(cp.Subjects()))
+			for i, sub := range cp.Subjects() {
+				assert.False(t, sub == evt.Subjects()[i])
+			}
+		})
+	}
+}
+
+func TestCopySharesSubjects(t *testing.T) {
+	evt := Parse("|move|p1a: Lugia|Surf|p2a: Umbreon|[spread] p2a,p2b")
+
+	cp := evt.Copy()
+	cp.Subject.ID = "lugia"
+
+	p := cp.Payload.(*MoveEvent)
+	assert.Equal(t, "lugia", p.User.ID)
+	assert.True(t, p.Target == cp.Targets[0])
+	assert.Equal(t, "", evt.Subject.ID)
+	assert.Equal(t, "", evt.Payload.(*MoveEvent).User.ID)
+}
+
+func TestSubjects(t *testing.T) {
+	evt := Parse("|-damage|p2a: Umbreon|50/100|[from] ability: Rough Skin|[of] p1a: Garchomp")
+
+	result := []string{}
+	for _, sub := range evt.Subjects() {
+		result = append(result, sub.Ident())
+	}
+
+	assert.Equal(t, []string{"p2: Umbreon", "p1: Garchomp"}, result)
+}
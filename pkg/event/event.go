@@ -2,6 +2,7 @@ package event
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -70,8 +71,56 @@ const (
 	HitCount           = "-hitcount"
 	SingleMove         = "-singlemove"
 	SingleTurn         = "-singleturn"
+
+	// Gen 9 & misc minor actions
+	SwapSideConditions = "-swapsideconditions"
+	FieldActivate      = "-fieldactivate"
+	Anim               = "-anim"
+	Nothing            = "-nothing"
+	OHKO               = "-ohko"
+	CanDynamax         = "-candynamax"
+	Terastallize       = "-terastallize"
+
+	// battle progress
+	Tie         = "tie"
+	Upkeep      = "upkeep"
+	Inactive    = "inactive"
+	InactiveOff = "inactiveoff"
+
+	// battle initialisation
+	Player      = "player"
+	TeamSize    = "teamsize"
+	GameType    = "gametype"
+	Gen         = "gen"
+	Tier        = "tier"
+	Rated       = "rated"
+	Rule        = "rule"
+	ClearPoke   = "clearpoke"
+	Poke        = "poke"
+	UpdatePoke  = "updatepoke"
+	TeamPreview = "teampreview"
+	BattleStart = "start"
+
+	// messages not specific to the battle itself
+	Raw         = "raw"
+	HTML        = "html"
+	UHTML       = "uhtml"
+	UHTMLChange = "uhtmlchange"
+	BigError    = "bigerror"
+	Error       = "error"
+	Debug       = "debug"
+	Title       = "title"
+	Join        = "j"
+	Leave       = "l"
+	Rename      = "n"
+	Chat        = "c"
+	ChatAt      = "c:"
+	ChatMessage = "chat"
 )
 
+// minFields is the fewest number of fields we'll parse from a line
+const minFields = 6
+
 // all known events
 var events = map[string]bool{
 	Win:                true,
@@ -137,6 +186,65 @@ var events = map[string]bool{
 	HitCount:           true,
 	SingleMove:         true,
 	SingleTurn:         true,
+	SwapSideConditions: true,
+	FieldActivate:      true,
+	Anim:               true,
+	Nothing:            true,
+	OHKO:               true,
+	CanDynamax:         true,
+	Terastallize:       true,
+	Tie:                true,
+	Upkeep:             true,
+	Inactive:           true,
+	InactiveOff:        true,
+	Player:             true,
+	TeamSize:           true,
+	GameType:           true,
+	Gen:                true,
+	Tier:               true,
+	Rated:              true,
+	Rule:               true,
+	ClearPoke:          true,
+	Poke:               true,
+	UpdatePoke:         true,
+	TeamPreview:        true,
+	BattleStart:        true,
+	Raw:                true,
+	HTML:               true,
+	UHTML:              true,
+	UHTMLChange:        true,
+	BigError:           true,
+	Error:              true,
+	Debug:              true,
+	Title:              true,
+	Join:               true,
+	Leave:              true,
+	Rename:             true,
+	Chat:               true,
+	ChatAt:             true,
+	ChatMessage:        true,
+}
+
+// text events hold free text (chat, html etc) where we should not look for
+// subjects or [tags]
+var text = map[string]bool{
+	Raw:         true,
+	HTML:        true,
+	UHTML:       true,
+	UHTMLChange: true,
+	BigError:    true,
+	Error:       true,
+	Debug:       true,
+	Title:       true,
+	Join:        true,
+	Leave:       true,
+	Rename:      true,
+	Chat:        true,
+	ChatAt:      true,
+	ChatMessage: true,
+	Inactive:    true,
+	InactiveOff: true,
+	Message:     true,
 }
 
 // Event represents some event that has happened during a turn
@@ -149,6 +257,16 @@ type Event struct {
 	Targets []*Subject
 
 	Metadata map[string]string
+
+	// Cause is what caused this event, if given ([from] & [of] tags)
+	Cause *Cause
+
+	// Flags are any other [tags] given with the event
+	Flags Flags
+
+	// Payload holds a typed struct specific to the event type (if known)
+	// ie. *DamageEvent, *MoveEvent, *BoostEvent (see payload.go)
+	Payload interface{}
 }
 
 // String returns a string representation of this event for debugging
@@ -187,6 +305,9 @@ func extractMetadata(line string) map[string]string {
 		in = in[idx:]
 
 		idx = strings.Index(in, "]")
+		if idx == -1 {
+			return found
+		}
 		name := in[1:idx]
 
 		in = in[idx+1:]
@@ -200,8 +321,27 @@ func extractMetadata(line string) map[string]string {
 			found[name] = strings.TrimSpace(in)
 		}
 	}
+}
 
-	return found
+// ParseSplit returns the event for the pair of lines following a
+// "|split|PLAYER" line; the secret line (exact HP, as the player sees it)
+// & the public line (HP as a percentage, as everyone else sees it).
+// The event is as the secret line, with Percent from the public line.
+func ParseSplit(secret, public string) *Event {
+	e := Parse(secret)
+	pub := Parse(public)
+	if e == nil {
+		return pub
+	}
+	if pub == nil || pub.Type != e.Type {
+		return e
+	}
+
+	pct, ok := getPercent(pub.Payload)
+	if ok {
+		setPercent(e.Payload, pct)
+	}
+	return e
 }
 
 // Parse returns an event (if possible) from a string
@@ -219,12 +359,26 @@ func Parse(line string) *Event {
 		return nil
 	}
 
+	if text[bits[1]] {
+		return &Event{
+			Type:     bits[1],
+			Name:     strings.Join(bits[2:], "|"),
+			Metadata: map[string]string{},
+			Payload:  parseText(bits),
+		}
+	}
+
+	// pad out fields so that short (or malformed) lines can't cause a panic
+	for len(bits) < minFields {
+		bits = append(bits, "")
+	}
+
 	e := &Event{
 		Metadata: extractMetadata(line),
 		Type:     bits[1],
 	}
 
-	subjects := ParseSubjects(line)
+	subjects := parseEventSubjects(e.Type, bits)
 	if len(subjects) > 0 {
 		// the first is always the source or principal subject
 		// ie. the pokemon getting healed / damaged, the source
@@ -260,11 +414,30 @@ func Parse(line string) *Event {
 		//|move|p1a: Ninetales|Inferno|p2a: Umbreon|[miss]
 		//|-start|p2a: Cinccino|Disable|Rock Blast|[from] ability: Cursed Body|[of] p1b: Froslass
 		e.Name = bits[3]
-	case Win, Activate, Weather, FieldStart, FieldEnd:
+	case Win, Activate, Weather, FieldStart, FieldEnd, FieldActivate, CanDynamax, GameType, Tier, Rule, Rated:
 		//|win|USER
 		//|-fieldstart|CONDITION
 		//|-weather|WEATHER
-		e.Name = bits[2]
+		//|tier|FORMATNAME
+		if len(bits) >= 3 {
+			e.Name = bits[2]
+		}
+	case Terastallize, Anim:
+		//|-terastallize|POKEMON|TYPE
+		//|-anim|POKEMON|MOVE|TARGET
+		e.Name = bits[3]
+	case Gen:
+		//|gen|GENNUM
+		e.Magnitude = parseint(bits[2])
+	case Player, Poke, UpdatePoke:
+		//|player|PLAYER|USERNAME|AVATAR|RATING
+		//|poke|PLAYER|DETAILS|ITEM
+		e.Name = bits[3]
+		e.Metadata["player"] = bits[2]
+	case TeamSize:
+		//|teamsize|PLAYER|NUMBER
+		e.Magnitude = parseint(bits[3])
+		e.Metadata["player"] = bits[2]
 	case Turn:
 		// |turn|NUMBER
 		e.Magnitude = parseint(bits[2])
@@ -304,6 +477,11 @@ func Parse(line string) *Event {
 		//|-sidestart|SIDE|CONDITION
 		e.Name = bits[3]
 		e.Metadata["side"] = bits[2]
+	case Primal:
+		//|-primal|POKEMON or |-primal|POKEMON|ITEM
+		if len(bits) >= 4 {
+			e.Name = bits[3]
+		}
 	case Burst:
 		//|-burst|POKEMON|SPECIES|ITEM
 		e.Name = bits[4]
@@ -313,9 +491,25 @@ func Parse(line string) *Event {
 		e.Magnitude = parseint(bits[3])
 	}
 
+	e.Cause = parseCause(e)
+	e.Flags = parseFlags(e)
+	e.Payload = parsePayload(e, bits)
 	return e
 }
 
+// percent returns HP as showdown shows it to other players; a percentage
+// rounded up, that's only 100 at full HP
+func percent(hp, max int) int {
+	if max <= 0 {
+		return 0
+	}
+	pct := int(math.Ceil(float64(hp) * 100 / float64(max)))
+	if pct == 100 && hp < max {
+		pct = 99
+	}
+	return pct
+}
+
 // parseCondition parses a showdown style pokemon 'condition' string
 func parseCondition(condition string) (int, int, string, error) {
 	if strings.Contains(condition, "fnt") {
@@ -1,8 +1,10 @@
 package event
 
 import (
-	"github.com/stretchr/testify/assert"
+	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var dataTestParseEvent = []struct {
@@ -30,9 +32,9 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:    Move,
 			Name:    "Explosion",
-			Subject: &Subject{Player: "p1", Position: "a"},
+			Subject: &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
 			Targets: []*Subject{
-				&Subject{Player: "p2", Position: "b"},
+				&Subject{Player: "p2", Position: "b", Nickname: "Umbreon"},
 				&Subject{Player: "p1", Position: "b"},
 				&Subject{Player: "p2", Position: "a"},
 				&Subject{Player: "p2", Position: "b"},
@@ -40,6 +42,13 @@ var dataTestParseEvent = []struct {
 			Metadata: map[string]string{
 				"spread": "p1b,p2a,p2b",
 			},
+			Flags: Flags{
+				Spread: []*Subject{
+					&Subject{Player: "p1", Position: "b"},
+					&Subject{Player: "p2", Position: "a"},
+					&Subject{Player: "p2", Position: "b"},
+				},
+			},
 		},
 	},
 	{
@@ -47,13 +56,14 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:    Move,
 			Name:    "Inferno",
-			Subject: &Subject{Player: "p1", Position: "a"},
+			Subject: &Subject{Player: "p1", Position: "a", Nickname: "Ninetales"},
 			Targets: []*Subject{
-				&Subject{Player: "p2", Position: "a"},
+				&Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
 			},
 			Metadata: map[string]string{
 				"miss": "",
 			},
+			Flags: Flags{Miss: true},
 		},
 	},
 	{
@@ -62,6 +72,7 @@ var dataTestParseEvent = []struct {
 			Type:     Weather,
 			Name:     "SunnyDay",
 			Metadata: map[string]string{"upkeep": ""},
+			Flags:    Flags{Upkeep: true},
 		},
 	},
 	{
@@ -69,8 +80,9 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:      Heal,
 			Name:      "4/13 brn",
-			Subject:   &Subject{Player: "p2", Position: "a"},
+			Subject:   &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
 			Metadata:  map[string]string{"from": "item: Leftovers"},
+			Cause:     &Cause{Kind: CauseItem, Name: "Leftovers"},
 			Magnitude: 4,
 		},
 	},
@@ -79,7 +91,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:      Switch,
 			Name:      "Umbreon, L5, M",
-			Subject:   &Subject{Player: "p2", Position: "a"},
+			Subject:   &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
 			Metadata:  map[string]string{"status": ""},
 			Magnitude: 100,
 		},
@@ -89,7 +101,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     DetailsChange,
 			Name:     "Gallade-Mega, L50, M",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Gallade"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -98,7 +110,24 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     Mega,
 			Name:     "Galladite",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Gallade"},
+			Metadata: map[string]string{},
+		},
+	},
+	{
+		"|-primal|p1a: Groudon",
+		&Event{
+			Type:     Primal,
+			Subject:  &Subject{Player: "p1", Position: "a", Nickname: "Groudon"},
+			Metadata: map[string]string{},
+		},
+	},
+	{
+		"|-primal|p1a: Groudon|Red Orb",
+		&Event{
+			Type:     Primal,
+			Name:     "Red Orb",
+			Subject:  &Subject{Player: "p1", Position: "a", Nickname: "Groudon"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -108,7 +137,7 @@ var dataTestParseEvent = []struct {
 			Type:      Boost,
 			Name:      "atk",
 			Magnitude: 2,
-			Subject:   &Subject{Player: "p2", Position: "a"},
+			Subject:   &Subject{Player: "p2", Position: "a", Nickname: "Gallade"},
 			Metadata:  map[string]string{},
 		},
 	},
@@ -118,7 +147,7 @@ var dataTestParseEvent = []struct {
 			Type:      HitCount,
 			Magnitude: 3,
 			Metadata:  map[string]string{},
-			Subject:   &Subject{Player: "p1", Position: "a"},
+			Subject:   &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
 		},
 	},
 	{
@@ -126,7 +155,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     End,
 			Name:     "Illusion",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Zoroark"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -135,7 +164,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     Replace,
 			Name:     "Zoroark, L5, M",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Zoroark"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -144,8 +173,9 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     EndAbility,
 			Name:     "Limber",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Zoroark"},
 			Metadata: map[string]string{"from": "move: Transform"},
+			Cause:    &Cause{Kind: CauseMove, Name: "Transform"},
 		},
 	},
 	{
@@ -153,9 +183,9 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:    Transform,
 			Name:    "p1a: Lugia",
-			Subject: &Subject{Player: "p2", Position: "a"},
+			Subject: &Subject{Player: "p2", Position: "a", Nickname: "Zoroark"},
 			Targets: []*Subject{
-				&Subject{Player: "p1", Position: "a"},
+				&Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
 			},
 			Metadata: map[string]string{},
 		},
@@ -165,7 +195,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     Fail,
 			Name:     "heal",
-			Subject:  &Subject{Player: "p1", Position: "a"},
+			Subject:  &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -174,7 +204,7 @@ var dataTestParseEvent = []struct {
 		&Event{
 			Type:     Ability,
 			Name:     "Intimidate",
-			Subject:  &Subject{Player: "p2", Position: "a"},
+			Subject:  &Subject{Player: "p2", Position: "a", Nickname: "Zoroark"},
 			Metadata: map[string]string{},
 		},
 	},
@@ -184,6 +214,10 @@ func TestParseEvent(t *testing.T) {
 	for _, tt := range dataTestParseEvent {
 		t.Run(tt.In, func(t *testing.T) {
 			result := Parse(tt.In)
+			if result != nil {
+				// payloads are checked in payload_test.go
+				result.Payload = nil
+			}
 
 			assert.Equal(t, tt.Expect, result)
 		})
@@ -214,13 +248,17 @@ var dataTestExtractSubjects = []struct {
 	},
 	{
 		"|blah|BLAH: what|p1a: foobar|[zap]",
-		[]*Subject{&Subject{Player: "p1", Position: "a"}},
+		[]*Subject{&Subject{Player: "p1", Position: "a", Nickname: "foobar"}},
+	},
+	{
+		"|-message|p1a: Team p2a rocks",
+		[]*Subject{&Subject{Player: "p1", Position: "a", Nickname: "Team p2a rocks"}},
 	},
 	{
 		"|move|p1a: Lugia|Explosion|p2b: Umbreon|[spread] p1b,p2a,p2b",
 		[]*Subject{
-			&Subject{Player: "p1", Position: "a"},
-			&Subject{Player: "p2", Position: "b"},
+			&Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
+			&Subject{Player: "p2", Position: "b", Nickname: "Umbreon"},
 			&Subject{Player: "p1", Position: "b"},
 			&Subject{Player: "p2", Position: "a"},
 			&Subject{Player: "p2", Position: "b"},
@@ -228,6 +266,15 @@ var dataTestExtractSubjects = []struct {
 	},
 }
 
+func TestParseEventSubjectsByField(t *testing.T) {
+	// nb. the nickname looks like a slot, but it isn't in a POKEMON field
+	result := Parse("|-damage|p1a: p2b Slayer|50/100|[from] ability: Rough Skin|[of] p2a: Garchomp")
+
+	assert.Equal(t, &Subject{Player: "p1", Position: "a", Nickname: "p2b Slayer"}, result.Subject)
+	assert.Equal(t, []*Subject{&Subject{Player: "p2", Position: "a", Nickname: "Garchomp"}}, result.Targets)
+	assert.Equal(t, "p1: p2b Slayer", result.Subject.Ident())
+}
+
 func TestExtractSubjects(t *testing.T) {
 	for _, tt := range dataTestExtractSubjects {
 		t.Run(tt.In, func(t *testing.T) {
@@ -281,3 +328,67 @@ func TestExtractMetadata(t *testing.T) {
 		})
 	}
 }
+
+var dataTestParseSplit = []struct {
+	Secret  string
+	Public  string
+	HP      int
+	MaxHP   int
+	Percent int
+}{
+	{"|switch|p1a: Pincurchin|Pincurchin, L88, M|228/228", "|switch|p1a: Pincurchin|Pincurchin, L88, M|100/100", 228, 228, 100},
+	{"|-damage|p2a: Liepard|130/256", "|-damage|p2a: Liepard|51/100", 130, 256, 51},
+	{"|-heal|p2a: Liepard|255/256|[from] item: Leftovers", "|-heal|p2a: Liepard|99/100|[from] item: Leftovers", 255, 256, 99},
+	{"|-damage|p2a: Liepard|0 fnt", "|-damage|p2a: Liepard|0 fnt", 0, -1, 0},
+	{"|-damage|p2a: Liepard|130/256", "", 130, 256, 51},
+}
+
+func TestParseSplit(t *testing.T) {
+	for _, tt := range dataTestParseSplit {
+		t.Run(tt.Secret, func(t *testing.T) {
+			result := ParseSplit(tt.Secret, tt.Public)
+
+			hp, max, pct := -2, -2, -2
+			switch p := result.Payload.(type) {
+			case *SwitchEvent:
+				hp, max, pct = p.HP, p.MaxHP, p.Percent
+			case *DamageEvent:
+				hp, max, pct = p.HP, p.MaxHP, p.Percent
+			case *HealEvent:
+				hp, max, pct = p.HP, p.MaxHP, p.Percent
+			}
+			assert.Equal(t, tt.HP, hp)
+			assert.Equal(t, tt.MaxHP, max)
+			assert.Equal(t, tt.Percent, pct)
+		})
+	}
+}
+
+func TestParseSplitUsesPublicPercent(t *testing.T) {
+	// nb. showdown's percentage is given, even if we'd have rounded
+	// differently
+	result := ParseSplit("|-damage|p2a: Liepard|130/256", "|-damage|p2a: Liepard|50/100")
+
+	assert.Equal(t, 50, result.Payload.(*DamageEvent).Percent)
+	assert.Equal(t, 130, result.Magnitude)
+}
+
+var dataTestPercent = []struct {
+	HP, Max int
+	Expect  int
+}{
+	{262, 262, 100},
+	{261, 262, 99},
+	{131, 262, 50},
+	{1, 262, 1},
+	{0, 262, 0},
+	{0, -1, 0},
+}
+
+func TestPercent(t *testing.T) {
+	for _, tt := range dataTestPercent {
+		t.Run(fmt.Sprintf("%d/%d", tt.HP, tt.Max), func(t *testing.T) {
+			assert.Equal(t, tt.Expect, percent(tt.HP, tt.Max))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package event
+
+import (
+	"encoding/json"
+)
+
+// UnmarshalJSON decodes an event, including it's typed Payload (which is
+// decided by the event Type).
+// Nb. decoded payloads hold copies of the event's subjects, not the same
+// pointers as Subject & Targets.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type plain Event
+	in := struct {
+		*plain
+		Payload json.RawMessage
+	}{plain: (*plain)(e)}
+
+	err := json.Unmarshal(data, &in)
+	if err != nil {
+		return err
+	}
+
+	e.Payload = nil
+	if len(in.Payload) == 0 || string(in.Payload) == "null" {
+		return nil
+	}
+
+	payload := emptyPayload(e.Type)
+	if payload == nil {
+		return nil
+	}
+	err = json.Unmarshal(in.Payload, payload)
+	if err != nil {
+		return err
+	}
+	e.Payload = payload
+	return nil
+}
+
+// emptyPayload returns an empty payload of the type used for the given
+// event type, or nil if the event type has none
+func emptyPayload(kind string) interface{} {
+	bits := make([]string, minFields)
+	bits[1] = kind
+	if text[kind] {
+		return parseText(bits)
+	}
+	return parsePayload(&Event{Type: kind, Metadata: map[string]string{}}, bits)
+}
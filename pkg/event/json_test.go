@@ -0,0 +1,52 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestEventJSON = []string{
+	"|switch|p1a: Ninetales|Ninetales, L50, F|150/150",
+	"|move|p1a: Ninetales|Inferno|p2a: Umbreon",
+	"|-damage|p2a: Umbreon|20/100 brn|[from] brn",
+	"|-boost|p1a: Ninetales|spa|2",
+	"|-start|p2a: Umbreon|confusion|[fatigue]",
+	"|-sidestart|p1: Alice|move: Stealth Rock",
+	"|turn|4",
+	"|win|p1",
+	"|c|~Alice|hello | world",
+	"|-clearallboost",
+}
+
+func TestEventJSON(t *testing.T) {
+	for _, line := range dataTestEventJSON {
+		t.Run(line, func(t *testing.T) {
+			in := Parse(line)
+			if in.Subject != nil {
+				in.Subject.ID = "some-id"
+				in.Subject.Index = 2
+			}
+
+			data, err := json.Marshal(in)
+			assert.Nil(t, err)
+
+			result := &Event{}
+			err = json.Unmarshal(data, result)
+
+			assert.Nil(t, err)
+			assert.Equal(t, in, result)
+		})
+	}
+}
+
+func TestEventJSONUnknownType(t *testing.T) {
+	result := &Event{}
+
+	err := json.Unmarshal([]byte(`{"Type": "nonsense", "Payload": {"Text": "hi"}}`), result)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "nonsense", result.Type)
+	assert.Nil(t, result.Payload)
+}
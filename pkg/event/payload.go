@@ -0,0 +1,755 @@
+package event
+
+import (
+	"strings"
+)
+
+// Typed event payloads.
+//
+// Each event parsed has it's information available generically (Name,
+// Magnitude, Metadata) but we also attach a specific struct to the
+// event's Payload field, which can be retrieved with a type switch. Ie.
+//
+//	switch p := evt.Payload.(type) {
+//	case *event.DamageEvent:
+//		fmt.Println(p.Target, p.HP, p.MaxHP)
+//	case *event.MoveEvent:
+//		...
+//	}
+//
+// Subjects in payloads are the same pointers as used in the Event's
+// Subject & Targets fields.
+
+// SwitchEvent is a pokemon entering the field (switch, drag)
+type SwitchEvent struct {
+	Pokemon *Subject
+	Details string
+	Species string
+	Level   int
+	Gender  string
+	Shiny   bool
+	HP      int
+	MaxHP   int
+	Status  string
+
+	// Percent is HP as other players see it (0-100). Where the simulator
+	// gives both exact & public HP (|split| lines) it's from the public line.
+	Percent int
+
+	// Forced is true if the pokemon was dragged in (roar, whirlwind etc)
+	Forced bool
+}
+
+// MoveEvent is a pokemon using a move
+type MoveEvent struct {
+	User   *Subject
+	Move   string
+	Target *Subject
+
+	// Missed is true if the move missed the target
+	Missed bool
+
+	// Spread holds all targets of a spread move (if any)
+	Spread []*Subject
+}
+
+// DamageEvent is a pokemon losing HP
+type DamageEvent struct {
+	Target *Subject
+	HP     int
+	MaxHP  int
+	Status string
+
+	// Percent is HP as other players see it (see SwitchEvent)
+	Percent int
+
+	// Source is the cause of the damage if not a direct attack
+	// ie. "brn", "item: Life Orb"
+	Source *Cause
+}
+
+// HealEvent is a pokemon regaining HP (or having it set directly)
+type HealEvent struct {
+	Target *Subject
+	HP     int
+	MaxHP  int
+	Status string
+
+	// Percent is HP as other players see it (see SwitchEvent)
+	Percent int
+
+	// Source is the cause of the healing, ie. "item: Leftovers"
+	Source *Cause
+}
+
+// FaintEvent is a pokemon fainting
+type FaintEvent struct {
+	Pokemon *Subject
+}
+
+// StatusEvent is a pokemon gaining (or being cured of) a major status
+type StatusEvent struct {
+	Pokemon *Subject
+	Status  string
+	Cured   bool
+	Source  *Cause
+}
+
+// CureTeamEvent is a whole team being cured of major statuses
+type CureTeamEvent struct {
+	Pokemon *Subject
+	Source  *Cause
+}
+
+// BoostEvent is a change to a pokemon's stat stages.
+type BoostEvent struct {
+	Pokemon *Subject
+	Stat    string
+
+	// Stages is the change in stages (negative for unboosts) or, if Set,
+	// the new value of the stat stage
+	Stages int
+	Set    bool
+	Source *Cause
+}
+
+// SwapBoostEvent is two pokemon swapping stat stages (Heart Swap etc)
+type SwapBoostEvent struct {
+	Source *Subject
+	Target *Subject
+
+	// Stats swapped (if empty, all stats are swapped)
+	Stats []string
+}
+
+// CopyBoostEvent is a pokemon copying another's stat stages (Psych Up)
+type CopyBoostEvent struct {
+	Source *Subject
+	Target *Subject
+
+	// Stats copied (if empty, all stats are copied)
+	Stats []string
+}
+
+// InvertBoostEvent is a pokemon's stat stages being inverted (Topsy-Turvy)
+type InvertBoostEvent struct {
+	Pokemon *Subject
+}
+
+// ClearBoostEvent is stat stages being reset.
+type ClearBoostEvent struct {
+	// Pokemon is nil if all pokemon have their stages cleared (Haze)
+	Pokemon *Subject
+
+	// Only clear positive or negative stages
+	Positive bool
+	Negative bool
+
+	// Source is the pokemon responsible (if any)
+	Source *Subject
+	Effect string
+}
+
+// TurnEvent is the start of a new turn
+type TurnEvent struct {
+	Number int
+}
+
+// WinEvent is the end of the battle
+type WinEvent struct {
+	// Player (username) that won, if any
+	Player string
+
+	// Tie is true if the battle ended in a draw
+	Tie bool
+}
+
+// WeatherEvent is a change of weather (or the weather continuing)
+type WeatherEvent struct {
+	Weather string
+	Upkeep  bool
+	Source  *Cause
+}
+
+// FieldEvent is a field condition starting or ending (terrains, trick room)
+type FieldEvent struct {
+	Condition string
+	Ended     bool
+	Source    *Cause
+}
+
+// SideConditionEvent is a side condition starting or ending
+// (reflect, stealth rock, tailwind)
+type SideConditionEvent struct {
+	Side      string
+	Condition string
+	Ended     bool
+}
+
+// VolatileEvent is a volatile status starting or ending on a pokemon
+// (confusion, substitute, leech seed, taunt)
+type VolatileEvent struct {
+	Pokemon *Subject
+	Effect  string
+	Ended   bool
+
+	// Detail holds additional effect specific information
+	// ie. the move disabled by Disable
+	Detail []string
+	Source *Cause
+}
+
+// SingleEvent is an effect that lasts a single turn or move (protect)
+type SingleEvent struct {
+	Pokemon *Subject
+	Effect  string
+
+	// Turn is true if the effect lasts the turn, otherwise until the
+	// pokemon next moves
+	Turn bool
+}
+
+// ItemEvent is an item being revealed, changed or used up
+type ItemEvent struct {
+	Pokemon *Subject
+	Item    string
+	Ended   bool
+	Source  *Cause
+}
+
+// AbilityEvent is an ability being revealed, changed or suppressed
+type AbilityEvent struct {
+	Pokemon *Subject
+	Ability string
+	Ended   bool
+	Source  *Cause
+}
+
+// CantEvent is a pokemon being unable to act
+type CantEvent struct {
+	Pokemon *Subject
+	Reason  string
+	Move    string
+}
+
+// FormeEvent is a pokemon changing forme
+type FormeEvent struct {
+	Pokemon *Subject
+
+	// Species is the new forme; Details are the full details if given.
+	// Nb. mega evolution & primal reversion don't tell us the new forme
+	// (the simulator follows up with a detailschange)
+	Species string
+	Details string
+
+	// Item is the stone / orb responsible for a mega / primal (if any)
+	Item string
+
+	// Permanent is true if the forme will persist after switching out
+	Permanent bool
+}
+
+// TransformEvent is a pokemon transforming into another
+type TransformEvent struct {
+	Pokemon *Subject
+	Into    *Subject
+}
+
+// MoveResultEvent is some notable result of a move on a target
+// (critical hit, super effective, miss, fail etc). Result is the event Type.
+type MoveResultEvent struct {
+	Target *Subject
+	Result string
+	Detail string
+}
+
+// ActivateEvent is some miscellaneous effect activating
+type ActivateEvent struct {
+	Pokemon *Subject
+	Effect  string
+	Source  *Cause
+}
+
+// PrepareEvent is a pokemon preparing a charge move (solar beam)
+type PrepareEvent struct {
+	User   *Subject
+	Move   string
+	Target *Subject
+}
+
+// HitCountEvent is the number of times a multi-hit move hit
+type HitCountEvent struct {
+	Target *Subject
+	Hits   int
+}
+
+// MessageEvent is some text from the simulator (-message, -hint, raw,
+// bigerror, inactive etc)
+type MessageEvent struct {
+	Text string
+}
+
+// ChatEvent is a user joining, leaving or talking in a room
+type ChatEvent struct {
+	User string
+	Text string
+}
+
+// SwapEvent is a pokemon moving to a new position (triples)
+type SwapEvent struct {
+	Pokemon  *Subject
+	Position int
+}
+
+// TerastallizeEvent is a pokemon terastallizing
+type TerastallizeEvent struct {
+	Pokemon *Subject
+	Type    string
+}
+
+// AnimEvent is a move animation with no other effect
+type AnimEvent struct {
+	User   *Subject
+	Move   string
+	Target *Subject
+}
+
+// UpkeepEvent marks the end of turn residual effects
+type UpkeepEvent struct{}
+
+// PlayerEvent is a player joining the battle
+type PlayerEvent struct {
+	Player   string
+	Username string
+	Avatar   string
+	Rating   int
+}
+
+// TeamSizeEvent is the number of pokemon a player has
+type TeamSizeEvent struct {
+	Player string
+	Size   int
+}
+
+// PreviewEvent is a pokemon shown in team preview
+type PreviewEvent struct {
+	Player  string
+	Details string
+	Species string
+	Level   int
+	Gender  string
+
+	// HasItem indicates the pokemon holds an item
+	HasItem bool
+}
+
+// InfoEvent is some information about the battle itself (format, rules,
+// generation etc) or a marker with no further detail (start, teampreview).
+// Key is the event Type.
+type InfoEvent struct {
+	Key   string
+	Value string
+}
+
+// parsePayload builds the typed payload for an event
+func parsePayload(e *Event, bits []string) interface{} {
+	from := e.Metadata["from"]
+
+	switch e.Type {
+	case Switch, Drag:
+		//|switch|POKEMON|DETAILS|HP STATUS
+		hp, max, status, _ := parseCondition(field(bits, 4))
+		species, level, gender, shiny := parseDetails(field(bits, 3))
+		return &SwitchEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Details: field(bits, 3),
+			Species: species,
+			Level:   level,
+			Gender:  gender,
+			Shiny:   shiny,
+			HP:      hp,
+			MaxHP:   max,
+			Status:  status,
+			Percent: percent(hp, max),
+			Forced:  e.Type == Drag,
+		}
+	case Move:
+		//|move|POKEMON|MOVE|TARGET
+		return &MoveEvent{
+			User:   e.subject(field(bits, 2)),
+			Move:   field(bits, 3),
+			Target: e.subject(field(bits, 4)),
+			Missed: e.Flags.Miss,
+			Spread: e.Flags.Spread,
+		}
+	case Damage:
+		//|-damage|POKEMON|HP STATUS
+		hp, max, status, _ := parseCondition(field(bits, 3))
+		return &DamageEvent{
+			Target:  e.subject(field(bits, 2)),
+			HP:      hp,
+			MaxHP:   max,
+			Status:  status,
+			Percent: percent(hp, max),
+			Source:  e.Cause,
+		}
+	case Heal, SetHP:
+		//|-heal|POKEMON|HP STATUS
+		hp, max, status, _ := parseCondition(field(bits, 3))
+		return &HealEvent{
+			Target:  e.subject(field(bits, 2)),
+			HP:      hp,
+			MaxHP:   max,
+			Status:  status,
+			Percent: percent(hp, max),
+			Source:  e.Cause,
+		}
+	case Faint:
+		return &FaintEvent{Pokemon: e.subject(field(bits, 2))}
+	case Status, CureStatus:
+		//|-status|POKEMON|STATUS
+		return &StatusEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Status:  field(bits, 3),
+			Cured:   e.Type == CureStatus,
+			Source:  e.Cause,
+		}
+	case CureTeam:
+		return &CureTeamEvent{Pokemon: e.subject(field(bits, 2)), Source: e.Cause}
+	case Boost, Unboost, SetBoost:
+		//|-boost|POKEMON|STAT|AMOUNT
+		stages := parseint(field(bits, 4))
+		if e.Type == Unboost {
+			stages = -stages
+		}
+		return &BoostEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Stat:    field(bits, 3),
+			Stages:  stages,
+			Set:     e.Type == SetBoost,
+			Source:  e.Cause,
+		}
+	case SwapBoost:
+		//|-swapboost|SOURCE|TARGET|STATS
+		return &SwapBoostEvent{
+			Source: e.subject(field(bits, 2)),
+			Target: e.subject(field(bits, 3)),
+			Stats:  parseStats(field(bits, 4)),
+		}
+	case CopyBoost:
+		//|-copyboost|SOURCE|TARGET
+		return &CopyBoostEvent{
+			Source: e.subject(field(bits, 2)),
+			Target: e.subject(field(bits, 3)),
+			Stats:  parseStats(field(bits, 4)),
+		}
+	case InvertBoost:
+		return &InvertBoostEvent{Pokemon: e.subject(field(bits, 2))}
+	case ClearBoost:
+		//|-clearboost|POKEMON
+		return &ClearBoostEvent{Pokemon: e.subject(field(bits, 2)), Effect: from}
+	case ClearAllBoost:
+		return &ClearBoostEvent{Effect: from}
+	case ClearPositiveBoost:
+		//|-clearpositiveboost|TARGET|POKEMON|EFFECT
+		return &ClearBoostEvent{
+			Pokemon:  e.subject(field(bits, 2)),
+			Positive: true,
+			Source:   e.subject(field(bits, 3)),
+			Effect:   field(bits, 4),
+		}
+	case ClearNegativeBoost:
+		return &ClearBoostEvent{Pokemon: e.subject(field(bits, 2)), Negative: true, Effect: from}
+	case Turn:
+		return &TurnEvent{Number: parseint(field(bits, 2))}
+	case Win:
+		return &WinEvent{Player: field(bits, 2)}
+	case Weather:
+		//|-weather|WEATHER
+		return &WeatherEvent{Weather: field(bits, 2), Upkeep: e.Flags.Upkeep, Source: e.Cause}
+	case FieldStart, FieldEnd:
+		//|-fieldstart|CONDITION
+		return &FieldEvent{Condition: field(bits, 2), Ended: e.Type == FieldEnd, Source: e.Cause}
+	case SideStart, SideEnd:
+		//|-sidestart|SIDE|CONDITION
+		return &SideConditionEvent{
+			Side:      strings.Split(field(bits, 2), ":")[0],
+			Condition: field(bits, 3),
+			Ended:     e.Type == SideEnd,
+		}
+	case Start, End:
+		//|-start|POKEMON|EFFECT|...
+		detail := []string{}
+		for i := 4; i < len(bits); i++ {
+			if bits[i] != "" && !strings.HasPrefix(bits[i], "[") {
+				detail = append(detail, bits[i])
+			}
+		}
+		return &VolatileEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Effect:  field(bits, 3),
+			Ended:   e.Type == End,
+			Detail:  detail,
+			Source:  e.Cause,
+		}
+	case SingleTurn, SingleMove:
+		return &SingleEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Effect:  field(bits, 3),
+			Turn:    e.Type == SingleTurn,
+		}
+	case Item, EndItem:
+		//|-item|POKEMON|ITEM
+		return &ItemEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Item:    field(bits, 3),
+			Ended:   e.Type == EndItem,
+			Source:  e.Cause,
+		}
+	case Ability, EndAbility:
+		//|-ability|POKEMON|ABILITY
+		return &AbilityEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Ability: field(bits, 3),
+			Ended:   e.Type == EndAbility,
+			Source:  e.Cause,
+		}
+	case Cant:
+		//|cant|POKEMON|REASON|MOVE
+		return &CantEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Reason:  field(bits, 3),
+			Move:    field(bits, 4),
+		}
+	case DetailsChange, FormeChange, Replace:
+		//|detailschange|POKEMON|DETAILS
+		//|-formechange|POKEMON|SPECIES
+		species, _, _, _ := parseDetails(field(bits, 3))
+		return &FormeEvent{
+			Pokemon:   e.subject(field(bits, 2)),
+			Species:   species,
+			Details:   field(bits, 3),
+			Permanent: e.Type != FormeChange,
+		}
+	case Mega:
+		//|-mega|POKEMON|SPECIES|MEGASTONE
+		return &FormeEvent{
+			Pokemon:   e.subject(field(bits, 2)),
+			Item:      field(bits, 4),
+			Permanent: true,
+		}
+	case Primal:
+		//|-primal|POKEMON|ITEM
+		return &FormeEvent{
+			Pokemon:   e.subject(field(bits, 2)),
+			Item:      field(bits, 3),
+			Permanent: true,
+		}
+	case Transform:
+		//|-transform|POKEMON|SPECIES
+		return &TransformEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Into:    e.subject(field(bits, 3)),
+		}
+	case Crit, SuperEffective, Resisted, Immune, Miss, Fail, Block, NoTarget:
+		//|-crit|POKEMON or |-fail|POKEMON|ACTION
+		return &MoveResultEvent{
+			Target: e.subject(field(bits, 2)),
+			Result: e.Type,
+			Detail: field(bits, 3),
+		}
+	case Activate:
+		//|-activate|POKEMON|EFFECT
+		return &ActivateEvent{
+			Pokemon: e.subject(field(bits, 2)),
+			Effect:  field(bits, 3),
+			Source:  e.Cause,
+		}
+	case Prepare:
+		//|-prepare|ATTACKER|MOVE|DEFENDER
+		return &PrepareEvent{
+			User:   e.subject(field(bits, 2)),
+			Move:   field(bits, 3),
+			Target: e.subject(field(bits, 4)),
+		}
+	case HitCount:
+		return &HitCountEvent{Target: e.subject(field(bits, 2)), Hits: parseint(field(bits, 3))}
+	case Hint:
+		return &MessageEvent{Text: field(bits, 2)}
+	case Swap:
+		//|swap|POKEMON|POSITION
+		return &SwapEvent{Pokemon: e.subject(field(bits, 2)), Position: parseint(field(bits, 3))}
+	case Terastallize:
+		//|-terastallize|POKEMON|TYPE
+		return &TerastallizeEvent{Pokemon: e.subject(field(bits, 2)), Type: field(bits, 3)}
+	case Anim:
+		//|-anim|POKEMON|MOVE|TARGET
+		return &AnimEvent{
+			User:   e.subject(field(bits, 2)),
+			Move:   field(bits, 3),
+			Target: e.subject(field(bits, 4)),
+		}
+	case FieldActivate:
+		//|-fieldactivate|EFFECT
+		return &ActivateEvent{Effect: field(bits, 2), Source: e.Cause}
+	case ZPower:
+		//|-zpower|POKEMON
+		return &ActivateEvent{Pokemon: e.subject(field(bits, 2)), Effect: "zpower"}
+	case Waiting:
+		//|-waiting|SOURCE|TARGET
+		return &ActivateEvent{Pokemon: e.subject(field(bits, 2)), Effect: "waiting"}
+	case Combine, Center, SwapSideConditions, ClearPoke, TeamPreview, BattleStart, CanDynamax, GameType, Gen, Tier, Rated, Rule:
+		//|-combine or |tier|FORMATNAME
+		return &InfoEvent{Key: e.Type, Value: field(bits, 2)}
+	case Nothing, OHKO, ZBroken:
+		//|-ohko or |-zbroken|POKEMON
+		return &MoveResultEvent{Target: e.subject(field(bits, 2)), Result: e.Type}
+	case MustRecharge:
+		//|-mustrecharge|POKEMON
+		return &VolatileEvent{Pokemon: e.subject(field(bits, 2)), Effect: "mustrecharge", Detail: []string{}}
+	case Burst:
+		//|-burst|POKEMON|SPECIES|ITEM
+		return &FormeEvent{
+			Pokemon:   e.subject(field(bits, 2)),
+			Species:   field(bits, 3),
+			Item:      field(bits, 4),
+			Permanent: true,
+		}
+	case Tie:
+		return &WinEvent{Tie: true}
+	case Upkeep:
+		return &UpkeepEvent{}
+	case Player:
+		//|player|PLAYER|USERNAME|AVATAR|RATING
+		return &PlayerEvent{
+			Player:   field(bits, 2),
+			Username: field(bits, 3),
+			Avatar:   field(bits, 4),
+			Rating:   parseint(field(bits, 5)),
+		}
+	case TeamSize:
+		//|teamsize|PLAYER|NUMBER
+		return &TeamSizeEvent{Player: field(bits, 2), Size: parseint(field(bits, 3))}
+	case Poke, UpdatePoke:
+		//|poke|PLAYER|DETAILS|ITEM
+		species, level, gender, _ := parseDetails(field(bits, 3))
+		return &PreviewEvent{
+			Player:  field(bits, 2),
+			Details: field(bits, 3),
+			Species: species,
+			Level:   level,
+			Gender:  gender,
+			HasItem: field(bits, 4) != "",
+		}
+	}
+
+	return nil
+}
+
+// parseText builds the payload of free text events
+func parseText(bits []string) interface{} {
+	switch bits[1] {
+	case Join, Leave, Rename:
+		//|j|USER or |n|USER|OLDID
+		return &ChatEvent{User: strings.TrimSpace(field(bits, 2))}
+	case Chat, ChatMessage:
+		//|c|USER|MESSAGE
+		return &ChatEvent{User: strings.TrimSpace(field(bits, 2)), Text: strings.Join(bits[3:], "|")}
+	case ChatAt:
+		//|c:|TIMESTAMP|USER|MESSAGE
+		if len(bits) < 4 {
+			return &ChatEvent{}
+		}
+		return &ChatEvent{User: strings.TrimSpace(bits[3]), Text: strings.Join(bits[4:], "|")}
+	}
+	return &MessageEvent{Text: strings.Join(bits[2:], "|")}
+}
+
+// subject returns the subject (one of ours, if possible) referred
+// to by the given protocol field. Ie. "p1a: Lugia" or "p1a".
+func (e *Event) subject(in string) *Subject {
+	want := parseSubject(in)
+	if want == nil {
+		return nil
+	}
+
+	all := append([]*Subject{e.Subject}, e.Targets...)
+	for _, sub := range all {
+		if sub != nil && sub.String() == want.String() && sub.Nickname == want.Nickname {
+			return sub
+		}
+	}
+	for _, sub := range all {
+		if sub != nil && sub.String() == want.String() {
+			return sub
+		}
+	}
+
+	return want
+}
+
+// field returns the i'th field of a protocol line, or "" if it's not there
+// or holds [tag] data rather than a value.
+func field(bits []string, i int) string {
+	if i >= len(bits) || strings.HasPrefix(bits[i], "[") {
+		return ""
+	}
+	return bits[i]
+}
+
+// parseDetails reads a pokemon details string
+// ie. "Ninetales, L5, M, shiny"
+func parseDetails(details string) (string, int, string, bool) {
+	bits := strings.Split(details, ", ")
+	level := 100
+	gender := ""
+	shiny := false
+	for _, chunk := range bits[1:] {
+		switch {
+		case chunk == "M", chunk == "F":
+			gender = chunk
+		case chunk == "shiny":
+			shiny = true
+		case strings.HasPrefix(chunk, "L"):
+			level = parseint(chunk[1:])
+		}
+	}
+	return bits[0], level, gender, shiny
+}
+
+// parseStats reads a list of stats ie. "atk, spa"
+func parseStats(in string) []string {
+	stats := []string{}
+	for _, s := range strings.Split(in, ",") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
+
+// setPercent sets the public HP of a payload, if it has HP
+func setPercent(payload interface{}, pct int) {
+	switch p := payload.(type) {
+	case *SwitchEvent:
+		p.Percent = pct
+	case *DamageEvent:
+		p.Percent = pct
+	case *HealEvent:
+		p.Percent = pct
+	}
+}
+
+// getPercent returns the public HP of a payload, if it has HP
+func getPercent(payload interface{}) (int, bool) {
+	switch p := payload.(type) {
+	case *SwitchEvent:
+		return p.Percent, true
+	case *DamageEvent:
+		return p.Percent, true
+	case *HealEvent:
+		return p.Percent, true
+	}
+	return 0, false
+}
@@ -0,0 +1,206 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestParsePayload = []struct {
+	In     string
+	Expect interface{}
+}{
+	{
+		"|switch|p1a: Ninetales|Ninetales, L5, F, shiny|24/27 brn",
+		&SwitchEvent{
+			Pokemon: &Subject{Player: "p1", Position: "a", Nickname: "Ninetales"},
+			Details: "Ninetales, L5, F, shiny",
+			Species: "Ninetales",
+			Level:   5,
+			Gender:  "F",
+			Shiny:   true,
+			HP:      24,
+			MaxHP:   27,
+			Status:  "brn",
+			Percent: 89,
+		},
+	},
+	{
+		"|drag|p2a: Umbreon|Umbreon|100/100",
+		&SwitchEvent{
+			Pokemon: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			Details: "Umbreon",
+			Species: "Umbreon",
+			Level:   100,
+			HP:      100,
+			MaxHP:   100,
+			Percent: 100,
+			Forced:  true,
+		},
+	},
+	{
+		"|move|p1a: Ninetales|Inferno|p2a: Umbreon|[miss]",
+		&MoveEvent{
+			User:   &Subject{Player: "p1", Position: "a", Nickname: "Ninetales"},
+			Move:   "Inferno",
+			Target: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			Missed: true,
+		},
+	},
+	{
+		"|move|p1a: Lugia|Explosion|p2b: Umbreon|[spread] p1b,p2a,p2b",
+		&MoveEvent{
+			User:   &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
+			Move:   "Explosion",
+			Target: &Subject{Player: "p2", Position: "b", Nickname: "Umbreon"},
+			Spread: []*Subject{
+				&Subject{Player: "p1", Position: "b"},
+				&Subject{Player: "p2", Position: "a"},
+				&Subject{Player: "p2", Position: "b"},
+			},
+		},
+	},
+	{
+		"|-damage|p2a: Umbreon|327/348 brn|[from] brn",
+		&DamageEvent{
+			Target:  &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			HP:      327,
+			MaxHP:   348,
+			Status:  "brn",
+			Percent: 94,
+			Source:  &Cause{Kind: CauseStatus, Name: "brn"},
+		},
+	},
+	{
+		"|-heal|p2a: Umbreon|100/100|[from] item: Leftovers",
+		&HealEvent{
+			Target:  &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			HP:      100,
+			MaxHP:   100,
+			Percent: 100,
+			Source:  &Cause{Kind: CauseItem, Name: "Leftovers"},
+		},
+	},
+	{
+		"|-unboost|p1a: Lugia|spd|1",
+		&BoostEvent{
+			Pokemon: &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
+			Stat:    "spd",
+			Stages:  -1,
+		},
+	},
+	{
+		"|-setboost|p1a: Azumarill|atk|6|[from] move: Belly Drum",
+		&BoostEvent{
+			Pokemon: &Subject{Player: "p1", Position: "a", Nickname: "Azumarill"},
+			Stat:    "atk",
+			Stages:  6,
+			Set:     true,
+			Source:  &Cause{Kind: CauseMove, Name: "Belly Drum"},
+		},
+	},
+	{
+		"|-swapboost|p1a: Lugia|p2a: Umbreon|atk, spa|[from] move: Power Swap",
+		&SwapBoostEvent{
+			Source: &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
+			Target: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			Stats:  []string{"atk", "spa"},
+		},
+	},
+	{
+		"|-clearpositiveboost|p2a: Umbreon|p1a: Lugia|move: Spectral Thief",
+		&ClearBoostEvent{
+			Pokemon:  &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"},
+			Positive: true,
+			Source:   &Subject{Player: "p1", Position: "a", Nickname: "Lugia"},
+			Effect:   "move: Spectral Thief",
+		},
+	},
+	{
+		"|-clearallboost",
+		&ClearBoostEvent{},
+	},
+	{
+		"|-start|p2a: Cinccino|Disable|Rock Blast|[from] ability: Cursed Body|[of] p1b: Froslass",
+		&VolatileEvent{
+			Pokemon: &Subject{Player: "p2", Position: "a", Nickname: "Cinccino"},
+			Effect:  "Disable",
+			Detail:  []string{"Rock Blast"},
+			Source: &Cause{
+				Kind:   CauseAbility,
+				Name:   "Cursed Body",
+				Source: &Subject{Player: "p1", Position: "b", Nickname: "Froslass"},
+			},
+		},
+	},
+	{
+		"|-sidestart|p1: Red|move: Stealth Rock",
+		&SideConditionEvent{Side: "p1", Condition: "move: Stealth Rock"},
+	},
+	{
+		"|-weather|SunnyDay|[upkeep]",
+		&WeatherEvent{Weather: "SunnyDay", Upkeep: true},
+	},
+	{
+		"|turn|4",
+		&TurnEvent{Number: 4},
+	},
+	{
+		"|win|p1",
+		&WinEvent{Player: "p1"},
+	},
+	{
+		"|cant|p1a: Lugia|par",
+		&CantEvent{Pokemon: &Subject{Player: "p1", Position: "a", Nickname: "Lugia"}, Reason: "par"},
+	},
+	{
+		"|detailschange|p2a: Gallade|Gallade-Mega, L50, M",
+		&FormeEvent{
+			Pokemon:   &Subject{Player: "p2", Position: "a", Nickname: "Gallade"},
+			Species:   "Gallade-Mega",
+			Details:   "Gallade-Mega, L50, M",
+			Permanent: true,
+		},
+	},
+	{
+		"|-mega|p2a: Gallade|Gallade|Galladite",
+		&FormeEvent{
+			Pokemon:   &Subject{Player: "p2", Position: "a", Nickname: "Gallade"},
+			Item:      "Galladite",
+			Permanent: true,
+		},
+	},
+	{
+		"|-crit|p2a: Umbreon",
+		&MoveResultEvent{Target: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"}, Result: Crit},
+	},
+	{
+		"|-status|p2a: Umbreon|tox",
+		&StatusEvent{Pokemon: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"}, Status: "tox"},
+	},
+	{
+		"|faint|p2a: Umbreon",
+		&FaintEvent{Pokemon: &Subject{Player: "p2", Position: "a", Nickname: "Umbreon"}},
+	},
+}
+
+func TestParsePayload(t *testing.T) {
+	for _, tt := range dataTestParsePayload {
+		t.Run(tt.In, func(t *testing.T) {
+			result := Parse(tt.In)
+
+			assert.NotNil(t, result)
+			assert.Equal(t, tt.Expect, result.Payload)
+		})
+	}
+}
+
+func TestPayloadSharesSubjects(t *testing.T) {
+	result := Parse("|move|p1a: Ninetales|Inferno|p2a: Umbreon")
+
+	mv, ok := result.Payload.(*MoveEvent)
+
+	assert.True(t, ok)
+	assert.True(t, mv.User == result.Subject)
+	assert.True(t, mv.Target == result.Targets[0])
+}
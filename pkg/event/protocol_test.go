@@ -0,0 +1,172 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dataTestProtocol is a corpus of protocol lines (as written by the simulator)
+// covering every message in SIM-PROTOCOL.md that we recognise.
+// github.com/smogon/pokemon-showdown/blob/master/sim/SIM-PROTOCOL.md
+var dataTestProtocol = []string{
+	// battle initialisation
+	"|player|p1|Anonycat|60|1200",
+	"|player|p2|",
+	"|teamsize|p1|6",
+	"|gametype|doubles",
+	"|gen|8",
+	"|tier|[Gen 8] Doubles Ubers",
+	"|rated|",
+	"|rated|Tournament battle",
+	"|rule|Species Clause: Limit one of each Pokémon",
+	"|clearpoke",
+	"|poke|p1|Pikachu, L59, F|item",
+	"|poke|p2|Zoroark, M|",
+	"|updatepoke|p1a: Zoroark|Zoroark, L50, M",
+	"|teampreview",
+	"|teampreview|4",
+	"|start",
+
+	// battle progress
+	"|inactive|Time left: 150 sec this turn | 740 sec total",
+	"|inactiveoff|Battle timer is now OFF.",
+	"|upkeep",
+	"|turn|14",
+	"|win|p1",
+	"|tie",
+
+	// major actions
+	"|move|p1a: Pikachu|Thunderbolt|p2a: Umbreon",
+	"|move|p1a: Lugia|Explosion|p2b: Umbreon|[spread] p1b,p2a,p2b",
+	"|move|p2a: Umbreon|Protect||[still]",
+	"|switch|p1a: Pikachu|Pikachu, L59, F|157/157",
+	"|drag|p2a: Umbreon|Umbreon, L50, M|45/100 tox",
+	"|detailschange|p2a: Gallade|Gallade-Mega, L50, M",
+	"|-formechange|p1a: Aegislash|Aegislash-Blade|[from] ability: Stance Change",
+	"|replace|p2a: Zoroark|Zoroark, L50, M",
+	"|swap|p1a: Pikachu|2|[from] move: Ally Switch",
+	"|cant|p1a: Pikachu|par",
+	"|cant|p2a: Umbreon|Disable|Foul Play",
+	"|faint|p2a: Umbreon",
+
+	// minor actions
+	"|-fail|p1a: Pikachu|tox",
+	"|-fail|p1a: Pikachu",
+	"|-block|p2a: Umbreon|move: Protect|Thunderbolt|p1a: Pikachu",
+	"|-notarget|p1a: Pikachu",
+	"|-miss|p1a: Pikachu|p2a: Umbreon",
+	"|-damage|p2a: Umbreon|45/100 tox|[from] psn",
+	"|-heal|p2a: Umbreon|51/100 tox|[from] item: Leftovers",
+	"|-sethp|p2a: Umbreon|50/100|[from] move: Pain Split|[silent]",
+	"|-status|p2a: Umbreon|tox|[from] item: Toxic Orb",
+	"|-curestatus|p2a: Umbreon|tox|[msg]",
+	"|-cureteam|p1a: Blissey|[from] move: Heal Bell",
+	"|-boost|p1a: Pikachu|spa|2",
+	"|-unboost|p2a: Umbreon|atk|1|[from] ability: Intimidate|[of] p1a: Gyarados",
+	"|-setboost|p1a: Azumarill|atk|6|[from] move: Belly Drum",
+	"|-swapboost|p1a: Pikachu|p2a: Umbreon|atk, spa|[from] move: Power Swap",
+	"|-invertboost|p2a: Umbreon|[from] move: Topsy-Turvy",
+	"|-clearboost|p2a: Umbreon",
+	"|-clearallboost",
+	"|-clearpositiveboost|p2a: Umbreon|p1a: Marshadow|move: Spectral Thief",
+	"|-clearnegativeboost|p1a: Pikachu|[zeffect]",
+	"|-copyboost|p1a: Pikachu|p2a: Umbreon|[from] move: Psych Up",
+	"|-weather|RainDance|[from] ability: Drizzle|[of] p1a: Pelipper",
+	"|-weather|RainDance|[upkeep]",
+	"|-weather|none",
+	"|-fieldstart|move: Electric Terrain|[from] ability: Electric Surge|[of] p1a: Pincurchin",
+	"|-fieldend|move: Trick Room",
+	"|-sidestart|p1: Anonycat|move: Stealth Rock",
+	"|-sideend|p1: Anonycat|Reflect",
+	"|-swapsideconditions",
+	"|-start|p2a: Cinccino|Disable|Rock Blast|[from] ability: Cursed Body|[of] p1b: Froslass",
+	"|-start|p1a: Pikachu|confusion|[fatigue]",
+	"|-end|p2a: Zoroark|Illusion",
+	"|-crit|p2a: Umbreon",
+	"|-supereffective|p2a: Umbreon",
+	"|-resisted|p2a: Umbreon",
+	"|-immune|p2a: Gengar|[from] ability: Levitate",
+	"|-item|p2a: Umbreon|Choice Scarf|[from] move: Trick",
+	"|-enditem|p1a: Pikachu|Sitrus Berry|[eat]",
+	"|-ability|p1a: Gyarados|Intimidate|boost",
+	"|-endability|p2a: Zoroark|[from] move: Gastro Acid",
+	"|-transform|p2a: Ditto|p1a: Pikachu|[from] ability: Imposter",
+	"|-mega|p2a: Gallade|Gallade|Galladite",
+	"|-primal|p1a: Groudon",
+	"|-burst|p1a: Necrozma|Necrozma-Ultra|Ultranecrozium Z",
+	"|-zpower|p1a: Pikachu",
+	"|-zbroken|p2a: Umbreon",
+	"|-activate|p2a: Umbreon|move: Protect",
+	"|-fieldactivate|move: Perish Song",
+	"|-hint|Sleep Clause Mod activated.",
+	"|-center",
+	"|-message|Anonycat forfeited.",
+	"|-combine",
+	"|-waiting|p1a: Pikachu|p1b: Raichu",
+	"|-prepare|p1a: Venusaur|Solar Beam|p2a: Umbreon",
+	"|-mustrecharge|p1a: Snorlax",
+	"|-nothing",
+	"|-hitcount|p2a: Umbreon|5",
+	"|-singlemove|p1a: Pikachu|Destiny Bond",
+	"|-singleturn|p2a: Umbreon|Protect",
+	"|-anim|p1a: Pikachu|Thunderbolt|p2a: Umbreon",
+	"|-ohko",
+	"|-candynamax|p1",
+	"|-terastallize|p1a: Pikachu|Electric",
+
+	// room & server messages
+	"|raw|<div class=\"broadcast-red\">The battle crashed</div>",
+	"|html|<strong>Hi</strong>",
+	"|uhtml|timer|<em>10 seconds left</em>",
+	"|uhtmlchange|timer|",
+	"|bigerror|The simulator process crashed.",
+	"|error|[Invalid choice] Can't move: Pikachu doesn't have a move matching surf",
+	"|debug|move chosen",
+	"|title|Anonycat vs. Zarel",
+	"|j| Anonycat",
+	"|l| Anonycat",
+	"|n| Anonycat|anonycat2",
+	"|c| Anonycat|gl hf | have fun",
+	"|c:|1617349560| Anonycat|gg",
+	"|chat| Zarel|hello",
+}
+
+func TestProtocolConformance(t *testing.T) {
+	seen := map[string]bool{}
+
+	for _, line := range dataTestProtocol {
+		t.Run(line, func(t *testing.T) {
+			result := Parse(line)
+
+			assert.NotNil(t, result)
+			if result == nil {
+				return
+			}
+			assert.NotNil(t, result.Payload, "no payload for %s", result.Type)
+			assert.NotNil(t, result.Metadata)
+			seen[result.Type] = true
+		})
+	}
+
+	for tag := range events {
+		assert.True(t, seen[tag], "no protocol line for %s", tag)
+	}
+}
+
+func TestProtocolTextNotParsed(t *testing.T) {
+	result := Parse("|c| Anonycat|[spread] p1a: Pikachu")
+
+	assert.Nil(t, result.Subject)
+	assert.Equal(t, map[string]string{}, result.Metadata)
+	assert.Equal(t, &ChatEvent{User: "Anonycat", Text: "[spread] p1a: Pikachu"}, result.Payload)
+}
+
+func TestProtocolShortLines(t *testing.T) {
+	// nb. these aren't valid protocol, but they shouldn't panic either
+	for _, line := range []string{"|move", "|-damage|", "|swap", "|cant", "|-mega", "|player"} {
+		t.Run(line, func(t *testing.T) {
+			assert.NotPanics(t, func() { Parse(line) })
+		})
+	}
+}
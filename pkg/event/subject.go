@@ -6,10 +6,42 @@ import (
 	"strings"
 )
 
+// slotPattern matches a slot ID, ie. p1a p2b
+var slotPattern = regexp.MustCompile(`^p[1-4][a-d]$`)
+
+// targetFields are the protocol fields (other than the first) that hold a
+// POKEMON for a given event type. The first field (bits[2]) is always the
+// principal subject, if it names a pokemon.
+var targetFields = map[string][]int{
+	Move:               {4}, //|move|POKEMON|MOVE|TARGET
+	Miss:               {3}, //|-miss|SOURCE|TARGET
+	Block:              {5}, //|-block|POKEMON|EFFECT|MOVE|ATTACKER
+	SwapBoost:          {3}, //|-swapboost|SOURCE|TARGET|STATS
+	CopyBoost:          {3}, //|-copyboost|SOURCE|TARGET
+	ClearPositiveBoost: {3}, //|-clearpositiveboost|TARGET|POKEMON|EFFECT
+	Transform:          {3}, //|-transform|POKEMON|SPECIES
+	Waiting:            {3}, //|-waiting|SOURCE|TARGET
+	Prepare:            {4}, //|-prepare|ATTACKER|MOVE|DEFENDER
+	Anim:               {4}, //|-anim|POKEMON|MOVE|TARGET
+}
+
 // Subject (pokemon) of an event (source, target etc)
 type Subject struct {
 	Player   string
 	Position string
+
+	// Nickname the pokemon was referred to by (if given).
+	// nb. this can be a disguise (Zoroark's Illusion)
+	Nickname string
+
+	// ID is the PokemonSpec ID of the pokemon in the slot at the time
+	// of the event (if known). Set by the simulator stream.
+	ID string
+
+	// Index is the pokemon's position in the team as given in the
+	// BattleSpec, counting from 1 as showdown does (0 if unknown).
+	// Set by the simulator stream.
+	Index int
 }
 
 // String returns the [player][position] string used by pokemon showdown
@@ -18,26 +50,79 @@ func (s *Subject) String() string {
 	return fmt.Sprintf("%s%s", s.Player, s.Position)
 }
 
-// ParseSubjects returns all subjects in a given line
-func ParseSubjects(line string) []*Subject {
-	re := regexp.MustCompile(`[^a-zA-Z0-9]+`)
-	fields := strings.Fields(re.ReplaceAllString(line, " "))
+// Ident returns the pokemon's ident as given in side updates
+// eg. "p2: Umbreon"
+func (s *Subject) Ident() string {
+	return fmt.Sprintf("%s: %s", s.Player, s.Nickname)
+}
+
+// parseSubject reads a single POKEMON protocol field
+// ie. "p1a: Ninetales" or just "p1a" (as given by [spread])
+func parseSubject(in string) *Subject {
+	bits := strings.SplitN(strings.TrimSpace(in), ":", 2)
+	if !slotPattern.MatchString(bits[0]) {
+		return nil
+	}
+
+	s := &Subject{Player: bits[0][0:2], Position: bits[0][2:3]}
+	if len(bits) == 2 {
+		s.Nickname = strings.TrimSpace(bits[1])
+	}
+	return s
+}
+
+// parseTagSubjects returns subjects given in [of] & [spread] tags,
+// in the order they appear.
+func parseTagSubjects(bits []string) []*Subject {
+	subs := []*Subject{}
+	for _, b := range bits {
+		switch {
+		case strings.HasPrefix(b, "[of]"):
+			s := parseSubject(strings.TrimPrefix(b, "[of]"))
+			if s != nil {
+				subs = append(subs, s)
+			}
+		case strings.HasPrefix(b, "[spread]"):
+			for _, slot := range strings.Split(strings.TrimPrefix(b, "[spread]"), ",") {
+				s := parseSubject(slot)
+				if s != nil {
+					subs = append(subs, s)
+				}
+			}
+		}
+	}
+	return subs
+}
 
-	fe := regexp.MustCompile(`p[1-4][a-c]`) // ie: p1a p2c ...
+// parseEventSubjects returns the subjects of an event by reading the protocol
+// fields that hold a POKEMON for the event type, followed by any given
+// by [tag]s.
+func parseEventSubjects(kind string, bits []string) []*Subject {
 	subs := []*Subject{}
-	for _, field := range fields {
-		if !fe.MatchString(field) {
-			continue
+
+	for _, i := range append([]int{2}, targetFields[kind]...) {
+		s := parseSubject(field(bits, i))
+		if s != nil {
+			subs = append(subs, s)
 		}
+	}
 
-		subs = append(
-			subs,
-			&Subject{
-				Player:   field[0:2],
-				Position: field[2:3],
-			},
-		)
+	return append(subs, parseTagSubjects(bits)...)
+}
+
+// ParseSubjects returns all subjects in a given line.
+// Each protocol field is considered as a whole, so slot-like text
+// inside nicknames or messages isn't mistaken for a subject.
+func ParseSubjects(line string) []*Subject {
+	subs := []*Subject{}
+
+	bits := strings.Split(line, "|")
+	for _, b := range bits {
+		s := parseSubject(b)
+		if s != nil {
+			subs = append(subs, s)
+		}
 	}
 
-	return subs
+	return append(subs, parseTagSubjects(bits)...)
 }
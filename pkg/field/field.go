@@ -1,7 +1,11 @@
 package field
 
 import (
+	"log"
+	"strings"
+
 	"github.com/voidshard/poke-showdown-go/pkg/event"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
 	"github.com/voidshard/poke-showdown-go/pkg/sim"
 )
 
@@ -10,6 +14,10 @@ type Field struct {
 	turn  int
 	sides map[string]*sim.Side
 	slots map[string]*sim.Pokemon
+
+	// formes holds the species of pokemon before a temporary forme change
+	// (ie. Stance Change), which they revert to when switched out
+	formes map[*sim.Pokemon]string
 }
 
 // Turn returns the current turn of the game.
@@ -42,6 +50,84 @@ func (f *Field) Update(ud *sim.Update) {
 		switch ud.Event.Type {
 		case event.Turn:
 			f.turn = ud.Event.Magnitude
+		case event.Switch, event.Drag:
+			f.switchIn(ud.Event)
+		case event.DetailsChange, event.FormeChange, event.Mega, event.Primal:
+			f.changeForme(ud.Event)
+		}
+	}
+}
+
+// changeForme updates the pokemon referred to by the event to it's new forme.
+// Nb. the event only gives us a slot, so we rely on the pokemon in that slot
+// being the same team member that we saw in the last side update.
+func (f *Field) changeForme(e *event.Event) {
+	if e.Subject == nil {
+		return
+	}
+
+	pkm := f.WhoIs(e.Subject.String())
+	if pkm == nil {
+		return
+	}
+
+	species := ""
+	switch e.Type {
+	case event.Mega, event.Primal:
+		// we're told the item (if anything) rather than the new forme
+		item := e.Name
+		if item == "" {
+			item = pkm.Item
+		}
+		dex, err := data.MegaForme(pkm.Species, item)
+		if err != nil {
+			log.Println("failed to find mega forme", pkm.Species, item, err)
+			return
+		}
+		species = dex.Name
+	default:
+		// details are given as 'species, level, gender'
+		species = strings.Split(e.Name, ", ")[0]
+	}
+
+	was := pkm.Species
+	err := pkm.SetForme(species)
+	if err != nil {
+		log.Println("failed to change forme", pkm.Ident, species, err)
+		return
+	}
+
+	_, changed := f.formes[pkm]
+	if e.Type == event.FormeChange && !changed {
+		f.formes[pkm] = was
+	}
+}
+
+// switchIn reverts temporary forme changes of the pokemon leaving the
+// event's slot & puts the incoming pokemon in it (if it's one we know of).
+func (f *Field) switchIn(e *event.Event) {
+	if e.Subject == nil {
+		return
+	}
+	slot := e.Subject.String()
+
+	out := f.WhoIs(slot)
+	if species, ok := f.formes[out]; ok {
+		delete(f.formes, out)
+		err := out.SetForme(species)
+		if err != nil {
+			log.Println("failed to revert forme", out.Ident, species, err)
+		}
+	}
+
+	side, ok := f.sides[e.Subject.Player]
+	if !ok {
+		return
+	}
+	for _, pkm := range side.Pokemon {
+		if pkm.Ident == e.Subject.Ident() {
+			f.slots[slot] = pkm
+			return
 		}
 	}
 }
@@ -0,0 +1,115 @@
+package field
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// testField returns a field where p1 has the given pokemon (by species,
+// with an item) with the first of them active
+func testField(team ...[2]string) *Field {
+	side := &sim.Side{
+		Player: "p1",
+		Field:  []*sim.Slot{&sim.Slot{ID: "p1a"}},
+	}
+	for _, p := range team {
+		side.Pokemon = append(side.Pokemon, &sim.Pokemon{
+			Ident:   "p1: " + p[0],
+			Details: p[0] + ", L50",
+			Species: p[0],
+			Item:    p[1],
+		})
+	}
+
+	f := NewWatcher().(*Field)
+	f.Update(&sim.Update{Side: side})
+	return f
+}
+
+var dataTestChangeForme = []struct {
+	Name    string
+	Team    [][2]string
+	Events  []string
+	Species string
+	Forme   string
+}{
+	{
+		"mega",
+		[][2]string{{"Charizard", "charizarditex"}},
+		[]string{"|-mega|p1a: Charizard|Charizard|Charizardite X"},
+		"Charizard-Mega-X",
+		"Mega-X",
+	},
+	{
+		"detailschange",
+		[][2]string{{"Charizard", "charizarditey"}},
+		[]string{"|detailschange|p1a: Charizard|Charizard-Mega-Y, L50"},
+		"Charizard-Mega-Y",
+		"Mega-Y",
+	},
+	{
+		"formechange",
+		[][2]string{{"Aegislash", "leftovers"}},
+		[]string{"|-formechange|p1a: Aegislash|Aegislash-Blade|[from] ability: Stance Change"},
+		"Aegislash-Blade",
+		"Blade",
+	},
+	{
+		"formechange back",
+		[][2]string{{"Aegislash", "leftovers"}},
+		[]string{
+			"|-formechange|p1a: Aegislash|Aegislash-Blade|[from] ability: Stance Change",
+			"|-formechange|p1a: Aegislash|Aegislash|[from] ability: Stance Change",
+		},
+		"Aegislash",
+		"",
+	},
+	{
+		"formechange reverted by switch",
+		[][2]string{{"Aegislash", "leftovers"}, {"Lapras", "leftovers"}},
+		[]string{
+			"|-formechange|p1a: Aegislash|Aegislash-Blade|[from] ability: Stance Change",
+			"|switch|p1a: Lapras|Lapras, L50|100/100",
+		},
+		"Aegislash",
+		"",
+	},
+	{
+		"detailschange kept by switch",
+		[][2]string{{"Charizard", "charizarditey"}, {"Lapras", "leftovers"}},
+		[]string{
+			"|detailschange|p1a: Charizard|Charizard-Mega-Y, L50",
+			"|switch|p1a: Lapras|Lapras, L50|100/100",
+		},
+		"Charizard-Mega-Y",
+		"Mega-Y",
+	},
+}
+
+func TestChangeForme(t *testing.T) {
+	for _, tt := range dataTestChangeForme {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := testField(tt.Team...)
+			pkm := f.WhoIs("p1a")
+
+			for _, line := range tt.Events {
+				f.Update(&sim.Update{Event: event.Parse(line)})
+			}
+
+			assert.Equal(t, tt.Species, pkm.Species)
+			assert.Equal(t, tt.Species+", L50", pkm.Details)
+			assert.Equal(t, tt.Forme, pkm.Dex.Forme)
+		})
+	}
+}
+
+func TestSwitchIn(t *testing.T) {
+	f := testField([2]string{"Aegislash", ""}, [2]string{"Lapras", ""})
+
+	f.Update(&sim.Update{Event: event.Parse("|switch|p1a: Lapras|Lapras, L50|100/100")})
+
+	assert.Equal(t, "Lapras", f.WhoIs("p1a").Species)
+}
@@ -19,7 +19,8 @@ type Watcher interface {
 // NewWatcher returns a default field watcher
 func NewWatcher() Watcher {
 	return &Field{
-		sides: map[string]*sim.Side{},
-		slots: map[string]*sim.Pokemon{},
+		sides:  map[string]*sim.Side{},
+		slots:  map[string]*sim.Pokemon{},
+		formes: map[*sim.Pokemon]string{},
 	}
 }
@@ -0,0 +1,122 @@
+package fork
+
+import (
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// warm is a stream that has been replayed to some snapshot & not yet used
+type warm struct {
+	snap    *Snapshot
+	stream  sim.SimulatorStream
+	history []*sim.Update
+}
+
+// Cache holds battles that have been replayed to some point, so that forks
+// from (or after) that point need only replay the remaining choices.
+//
+// Streams can't be copied, so each warm stream is used by at most one fork.
+// Ie. an MCTS agent might Warm the root of it's search once per candidate
+// action, then Fork each child.
+type Cache struct {
+	lock sync.Mutex
+	size int
+	warm []*warm
+}
+
+// NewCache returns a cache holding at most size warm streams
+func NewCache(size int) *Cache {
+	return &Cache{size: size, warm: []*warm{}}
+}
+
+// Len returns the number of warm streams held
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.warm)
+}
+
+// Warm replays n new battles to the given snapshot & holds them for later
+// forks. If the cache is full the oldest streams are stopped.
+func (c *Cache) Warm(snap *Snapshot, n int) error {
+	for i := 0; i < n; i++ {
+		stream, err := start(snap.Spec, snap.Seed)
+		if err != nil {
+			return err
+		}
+
+		history, err := replay(stream, &Snapshot{Spec: snap.Spec, Seed: snap.Seed}, snap)
+		if err != nil {
+			stream.Stop()
+			return err
+		}
+
+		c.add(&warm{snap: snap.With(), stream: stream, history: history})
+	}
+	return nil
+}
+
+// Fork returns a new battle at the given snapshot along with the updates
+// that lead to it. The longest matching warm stream is used if there is one,
+// otherwise the battle is replayed from the start.
+func (c *Cache) Fork(snap *Snapshot) (*Recorder, []*sim.Update, error) {
+	w := c.take(snap)
+	if w == nil {
+		return Replay(snap)
+	}
+
+	history, err := replay(w.stream, w.snap, snap)
+	if err != nil {
+		w.stream.Stop()
+		return nil, nil, err
+	}
+
+	return newRecorder(w.stream, snap.With()), append(w.history, history...), nil
+}
+
+// Close stops all warm streams
+func (c *Cache) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, w := range c.warm {
+		w.stream.Stop()
+	}
+	c.warm = []*warm{}
+}
+
+// add stores a warm stream, evicting the oldest if required
+func (c *Cache) add(w *warm) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.warm = append(c.warm, w)
+	for len(c.warm) > c.size {
+		c.warm[0].stream.Stop()
+		c.warm = c.warm[1:]
+	}
+}
+
+// take removes & returns the warm stream with the longest prefix of snap
+func (c *Cache) take(snap *Snapshot) *warm {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	best := -1
+	for i, w := range c.warm {
+		if !snap.hasPrefix(w.snap) {
+			continue
+		}
+		if best < 0 || len(w.snap.Choices) > len(c.warm[best].snap.Choices) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	w := c.warm[best]
+	c.warm = append(c.warm[:best], c.warm[best+1:]...)
+	return w
+}
@@ -0,0 +1,206 @@
+// Package fork allows battles to be snapshot & forked into new streams.
+//
+// A SimulatorStream is a one way process, but the simulator is deterministic
+// given a seed & the choices written to it. A Snapshot records both, so a
+// fork is a new simulator that replays the choices to the same point.
+// This is intended for search based agents (ie. MCTS) that want to try
+// a number of candidate actions & compare outcomes.
+package fork
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// indirection allows for easier testing
+	newStream = sim.NewSimulatorStream
+
+	// ErrReplayFailed is returned if a fork ends before reaching the
+	// snapshot point
+	ErrReplayFailed = errors.New("replay ended before reaching snapshot")
+)
+
+// Snapshot is everything required to recreate a battle at some point
+type Snapshot struct {
+	// Spec of the battle (including teams)
+	Spec *sim.BattleSpec
+
+	// Seed of the battle RNG
+	Seed sim.Seed
+
+	// Choices written to the battle, in order
+	Choices []*sim.Action
+
+	// Updates is the number of updates read from the battle
+	Updates int
+}
+
+// With returns a copy of the snapshot with the given choices appended.
+// Nb. the update count is unchanged, since it isn't known what the choices
+// lead to until they're played.
+func (s *Snapshot) With(choices ...*sim.Action) *Snapshot {
+	cpy := *s
+	cpy.Choices = append(append([]*sim.Action{}, s.Choices...), choices...)
+	return &cpy
+}
+
+// hasPrefix returns if the other snapshot is an earlier point in the same battle
+func (s *Snapshot) hasPrefix(other *Snapshot) bool {
+	if s.Spec != other.Spec || s.Seed != other.Seed {
+		return false
+	}
+	if len(other.Choices) > len(s.Choices) || other.Updates > s.Updates {
+		return false
+	}
+	for i, c := range other.Choices {
+		if c.Pack() != s.Choices[i].Pack() {
+			return false
+		}
+	}
+	return true
+}
+
+// Recorder is a SimulatorStream that records choices so it can be snapshot
+type Recorder struct {
+	lock   sync.Mutex
+	stream sim.SimulatorStream
+	out    chan *sim.Update
+	snap   *Snapshot
+}
+
+// NewRecorder starts a new battle for the given spec. If the spec has no seed
+// one is chosen at random, so that it can be recorded.
+func NewRecorder(spec *sim.BattleSpec) (*Recorder, error) {
+	seed := spec.Seed
+	if seed.IsZero() {
+		seed = sim.RandomSeed()
+	}
+
+	stream, err := start(spec, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRecorder(stream, &Snapshot{Spec: spec, Seed: seed}), nil
+}
+
+// newRecorder wraps a stream at the given snapshot point
+func newRecorder(stream sim.SimulatorStream, snap *Snapshot) *Recorder {
+	r := &Recorder{
+		stream: stream,
+		out:    make(chan *sim.Update),
+		snap:   snap,
+	}
+	go r.relay()
+	return r
+}
+
+// start begins a stream for the given spec with the given seed
+func start(spec *sim.BattleSpec, seed sim.Seed) (sim.SimulatorStream, error) {
+	cpy := *spec
+	cpy.Seed = seed
+	return newStream(&cpy)
+}
+
+// relay passes updates on, keeping count as we go
+func (r *Recorder) relay() {
+	defer close(r.out)
+	for u := range r.stream.Updates() {
+		r.lock.Lock()
+		r.snap.Updates++
+		r.lock.Unlock()
+
+		r.out <- u
+	}
+}
+
+// Write some battle instruction to the simulator
+func (r *Recorder) Write(in *sim.Action) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	err := r.stream.Write(in)
+	if err != nil {
+		return err
+	}
+
+	// nb. we record invalid choices too, since replaying them is harmless
+	// & the simulator is deterministic either way
+	r.snap.Choices = append(r.snap.Choices, in)
+	return nil
+}
+
+// Updates returns all events, errors & requests from the simulator
+func (r *Recorder) Updates() <-chan *sim.Update {
+	return r.out
+}
+
+// Stop closes everything
+func (r *Recorder) Stop() {
+	r.stream.Stop()
+}
+
+// Snapshot returns the current point in the battle.
+// This should be taken when the battle is waiting on a choice; updates that
+// are in flight are counted as read.
+func (r *Recorder) Snapshot() *Snapshot {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cpy := *r.snap
+	cpy.Choices = append([]*sim.Action{}, r.snap.Choices...)
+	return &cpy
+}
+
+// Replay starts a new battle & plays it to the snapshot point.
+// Returns a recorder for the new battle along with all of the updates
+// that were replayed to get there.
+func Replay(snap *Snapshot) (*Recorder, []*sim.Update, error) {
+	stream, err := start(snap.Spec, snap.Seed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, err := replay(stream, &Snapshot{Spec: snap.Spec, Seed: snap.Seed}, snap)
+	if err != nil {
+		stream.Stop()
+		return nil, nil, err
+	}
+
+	return newRecorder(stream, snap.With()), history, nil
+}
+
+// replay moves the given stream from one snapshot to another, returning the
+// updates in between.
+func replay(stream sim.SimulatorStream, from, to *Snapshot) ([]*sim.Update, error) {
+	// The simulator processes input in order, so we don't need to wait
+	// for updates between writes; we do have to read updates as we go
+	// so the process doesn't block writing them.
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		for _, c := range to.Choices[len(from.Choices):] {
+			err := stream.Write(c)
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	history := []*sim.Update{}
+	for i := from.Updates; i < to.Updates; i++ {
+		u, ok := <-stream.Updates()
+		if !ok {
+			return nil, fmt.Errorf("%w read %d of %d updates", ErrReplayFailed, i, to.Updates)
+		}
+		history = append(history, u)
+	}
+
+	err := <-errs
+	return history, err
+}
@@ -0,0 +1,181 @@
+package fork
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// fakeStream is a deterministic battle; each write results in an event
+// naming the choice & a running total of the seed + choices so far
+type fakeStream struct {
+	lock    sync.Mutex
+	updates chan *sim.Update
+	total   int
+}
+
+func newFakeStream(seed sim.Seed) *fakeStream {
+	f := &fakeStream{updates: make(chan *sim.Update, 100), total: int(seed.Uint64())}
+	f.updates <- &sim.Update{Event: event.Parse("|turn|1")}
+	return f
+}
+
+func (f *fakeStream) Write(a *sim.Action) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.total += len(a.Specs[0].ID)
+	f.updates <- &sim.Update{Event: event.Parse(fmt.Sprintf("|move|%sa: Lugia|%s", a.Player, a.Specs[0].ID))}
+	f.updates <- &sim.Update{Event: event.Parse(fmt.Sprintf("|turn|%d", f.total))}
+	return nil
+}
+
+func (f *fakeStream) Updates() <-chan *sim.Update {
+	return f.updates
+}
+
+func (f *fakeStream) Stop() {}
+
+// withFakeStreams swaps in fake streams, returning a count of streams made
+func withFakeStreams(t *testing.T) *int {
+	started := 0
+	orig := newStream
+	newStream = func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		started++
+		return newFakeStream(spec.Seed), nil
+	}
+	t.Cleanup(func() { newStream = orig })
+	return &started
+}
+
+func choice(player, move string) *sim.Action {
+	return &sim.Action{Player: player, Specs: []*sim.ActionSpec{&sim.ActionSpec{Type: sim.ActionMove, ID: move}}}
+}
+
+// play writes the choice & reads the resulting updates
+func play(t *testing.T, s sim.SimulatorStream, a *sim.Action) *sim.Update {
+	assert.Nil(t, s.Write(a))
+	<-s.Updates()
+	return <-s.Updates()
+}
+
+func TestRecorderSnapshot(t *testing.T) {
+	withFakeStreams(t)
+	spec := &sim.BattleSpec{Seed: sim.NewSeed(10)}
+
+	r, err := NewRecorder(spec)
+	assert.Nil(t, err)
+	<-r.Updates()
+
+	play(t, r, choice("p1", "tackle"))
+	snap := r.Snapshot()
+
+	assert.Equal(t, spec, snap.Spec)
+	assert.Equal(t, sim.NewSeed(10), snap.Seed)
+	assert.Equal(t, 3, snap.Updates)
+	assert.Equal(t, []*sim.Action{choice("p1", "tackle")}, snap.Choices)
+
+	// later choices don't change the snapshot
+	play(t, r, choice("p2", "growl"))
+	assert.Equal(t, 1, len(snap.Choices))
+}
+
+func TestRecorderSetsSeed(t *testing.T) {
+	withFakeStreams(t)
+
+	r, err := NewRecorder(&sim.BattleSpec{})
+	assert.Nil(t, err)
+
+	assert.False(t, r.Snapshot().Seed.IsZero())
+}
+
+func TestReplayReachesSameState(t *testing.T) {
+	withFakeStreams(t)
+
+	r, err := NewRecorder(&sim.BattleSpec{Seed: sim.NewSeed(10)})
+	assert.Nil(t, err)
+	<-r.Updates()
+	play(t, r, choice("p1", "tackle"))
+	play(t, r, choice("p2", "growl"))
+
+	snap := r.Snapshot()
+	expect := play(t, r, choice("p1", "surf"))
+
+	f, history, err := Replay(snap)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(history))
+	assert.Equal(t, expect, play(t, f, choice("p1", "surf")))
+	assert.Equal(t, snap.Updates+2, f.Snapshot().Updates)
+}
+
+func TestCacheForksFromWarmStream(t *testing.T) {
+	started := withFakeStreams(t)
+
+	r, err := NewRecorder(&sim.BattleSpec{Seed: sim.NewSeed(10)})
+	assert.Nil(t, err)
+	<-r.Updates()
+	play(t, r, choice("p1", "tackle"))
+	root := r.Snapshot()
+
+	c := NewCache(2)
+	defer c.Close()
+
+	assert.Nil(t, c.Warm(root, 2))
+	assert.Equal(t, 3, *started)
+	assert.Equal(t, 2, c.Len())
+
+	results := []*sim.Update{}
+	for _, move := range []string{"surf", "earthquake"} {
+		f, history, err := c.Fork(root.With(choice("p2", move)))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(history))
+
+		<-f.Updates()
+		results = append(results, <-f.Updates())
+	}
+
+	// both forks used warm streams
+	assert.Equal(t, 3, *started)
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, 20, results[0].Event.Magnitude)
+	assert.Equal(t, 26, results[1].Event.Magnitude)
+
+	// so the next fork has to replay from the start
+	_, _, err = c.Fork(root)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, *started)
+}
+
+func TestCacheIgnoresOtherBattles(t *testing.T) {
+	withFakeStreams(t)
+
+	a := &Snapshot{Spec: &sim.BattleSpec{}, Seed: sim.NewSeed(1), Choices: []*sim.Action{choice("p1", "tackle")}}
+	b := a.With()
+	b.Seed = sim.NewSeed(2)
+
+	c := NewCache(1)
+	defer c.Close()
+	assert.Nil(t, c.Warm(a, 1))
+
+	assert.Nil(t, c.take(b))
+	assert.Nil(t, c.take(&Snapshot{Spec: a.Spec, Seed: sim.NewSeed(1), Choices: []*sim.Action{choice("p1", "surf")}}))
+	assert.NotNil(t, c.take(a))
+}
+
+func TestReplayFailsIfBattleEnds(t *testing.T) {
+	withFakeStreams(t)
+	orig := newStream
+	newStream = func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		s, _ := orig(spec)
+		close(s.(*fakeStream).updates)
+		return s, nil
+	}
+
+	_, _, err := Replay(&Snapshot{Spec: &sim.BattleSpec{}, Seed: sim.NewSeed(1), Updates: 200})
+
+	assert.ErrorIs(t, err, ErrReplayFailed)
+}
@@ -1,22 +1,31 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
-	"syscall"
+	"sync"
 )
 
 const (
 	// pokemon-showdown ends unique messages with \n\n
 	msgEnds = "\n\n"
+
+	// stderrLines is how many of the last lines of stderr we keep
+	// to explain why a process exited
+	stderrLines = 10
 )
 
 type Option func(*config)
 
 type config struct {
-	Sep string
+	Sep        string
+	Buffer     int
+	MaxMessage int
 }
 
 func Seperator(s string) Option {
@@ -25,123 +34,295 @@ func Seperator(s string) Option {
 	}
 }
 
+// Buffer sets how many messages (from stdout & stderr each) can be read
+// ahead of the caller. When the buffer is full we stop reading so the
+// process blocks writing.
+func Buffer(n int) Option {
+	return func(c *config) {
+		c.Buffer = n
+	}
+}
+
+// MaxMessage sets the size (in bytes) of the largest message we'll read.
+// A process writing a longer message is killed.
+func MaxMessage(n int) Option {
+	return func(c *config) {
+		c.MaxMessage = n
+	}
+}
+
+// ExitError is given if a process exits by itself with a non zero exit
+// code, or is killed because we couldn't read from / write to it.
+type ExitError struct {
+	// Code the process exited with (-1 if it was killed)
+	Code int
+
+	// Stderr holds the last few lines the process wrote to stderr
+	Stderr string
+
+	// Err is the read or write error, if any
+	Err error
+}
+
+// Error implements error
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("process exited with code %d", e.Code)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	if e.Stderr != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Stderr)
+	}
+	return msg
+}
+
+// Unwrap returns the read or write error, if any
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // Run kicks off an interactive command.
 // New messages from stdout / stderr are returned as they are read from the process.
+// Input sent on stdin is written to the process, closing stdin closes the
+// process' stdin. Sending on (or closing) ctrl kills the process.
+// When the process has exited the stdout & stderr chans are closed, after
+// which the error chan is given an error (if the process failed to start,
+// or exited with an error; see ExitError) & closed.
 func Run(cmd string, args []string, stdin <-chan string, ctrl chan os.Signal, opts ...Option) (<-chan string, <-chan string, <-chan error) {
-	cfg := &config{Sep: msgEnds}
+	cfg := &config{Sep: msgEnds, Buffer: 64, MaxMessage: 1 << 20}
 	for _, o := range opts {
 		o(cfg)
 	}
 
-	// handler for the active command we'll be launching
-	active := exec.Command(cmd, args...)
-
 	// channels we use to give stdout, stderr and go errors to the user
-	retStdout := make(chan string)
-	retStderr := make(chan string)
-	retErr := make(chan error)
+	retStdout := make(chan string, cfg.Buffer)
+	retStderr := make(chan string, cfg.Buffer)
+	retErr := make(chan error, 1)
 
-	// stdout & stderr pipes coming from the active process
-	cmdStdout, _ := active.StdoutPipe()
-	cmdStderr, _ := active.StderrPipe()
-	// stdin pipe to the active process
-	cmdStdIn, _ := active.StdinPipe()
+	p := &process{
+		cmd:    exec.Command(cmd, args...),
+		cfg:    cfg,
+		quit:   make(chan struct{}),
+		exited: make(chan struct{}),
+		stderr: []string{},
+	}
 
-	// an error chan & helper func to kick off read pumps for stderr & stdout
-	pumpErrs := make(chan error)
-	pump(cmdStdout, retStdout, pumpErrs, cfg.Sep) // messages divided by \n\n
-	pump(cmdStderr, retStderr, pumpErrs, "\n")    // return any error lines
+	err := p.start()
+	if err != nil {
+		go func() {
+			// drop input so that writers aren't blocked
+			for range stdin {
+			}
+		}()
+		retErr <- err
+		close(retStdout)
+		close(retStderr)
+		close(retErr)
+		return retStdout, retStderr, retErr
+	}
 
+	pumps := &sync.WaitGroup{}
+	pumps.Add(2)
 	go func() {
-		/* Commented out to prevent send on closed during utils Simulate
-		defer close(retStdout)
-		defer close(retStderr)
-		defer close(retErr)
-		defer close(pumpErrs)
-		*/
-		defer cmdStdout.Close()
-		defer cmdStderr.Close()
-		defer cmdStdIn.Close()
-
-		pumpsFinished := 0
-		for {
-			// the read pumps launched above handle reading from the active commands
-			// stdout/err and returning messages to the caller, we're going to:
-			// - check our read pumps haven't exited & exit if they both finish
-			// - write to the process stdin if the caller sends input
-			// - signal the process to exit if the caller sends any signal
-			// If we encounter any errors they'll be shipped over our errs return chan
-			select {
-			case input := <-stdin:
-				if input == "" {
-					continue
-				}
-
-				_, err := cmdStdIn.Write([]byte(input))
-				if err != nil {
-					retErr <- err
-				}
-			case <-ctrl:
-				retErr <- active.Process.Kill()
-				return
-			case err := <-pumpErrs:
-				pumpsFinished += 1
-				if err != nil {
-					retErr <- err
-				}
-				if pumpsFinished >= 2 {
-					ctrl <- syscall.SIGINT
-				}
-			}
-		}
+		defer pumps.Done()
+		p.pump(p.stdoutPipe, retStdout, cfg.Sep, nil)
 	}()
+	go func() {
+		defer pumps.Done()
+		p.pump(p.stderrPipe, retStderr, "\n", p.keepStderr)
+	}()
+
+	go p.write(stdin)
+	go p.control(ctrl)
+	go p.wait(pumps, retErr)
+
+	return retStdout, retStderr, retErr
+}
 
-	err := active.Start()
+// process is a running command & the state shared by the goroutines
+// reading from & writing to it
+type process struct {
+	cmd *exec.Cmd
+	cfg *config
+
+	stdinPipe  io.WriteCloser
+	stdoutPipe io.ReadCloser
+	stderrPipe io.ReadCloser
+
+	// quit is closed when the process is killed
+	quit chan struct{}
+
+	// exited is closed when the process has exited
+	exited chan struct{}
+
+	lock   sync.Mutex
+	killed bool
+	done   bool
+	err    error
+
+	// stderr is only written to by the stderr pump
+	stderr []string
+}
+
+// start opens pipes to the process & starts it
+func (p *process) start() error {
+	var err error
+	p.stdoutPipe, err = p.cmd.StdoutPipe()
 	if err != nil {
-		retErr <- err
-		ctrl <- syscall.SIGINT
+		return err
+	}
+	p.stderrPipe, err = p.cmd.StderrPipe()
+	if err != nil {
+		return err
 	}
+	p.stdinPipe, err = p.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	return p.cmd.Start()
+}
 
-	return retStdout, retStderr, retErr
+// pump reads messages split by `sep` from src & passes them to drain
+// until src ends or the process is killed. Messages aren't read faster
+// than drain is read from & are at most cfg.MaxMessage bytes, so we never
+// hold more than we've been told to.
+func (p *process) pump(src io.Reader, drain chan<- string, sep string, each func(string)) {
+	defer close(drain)
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(nil, p.cfg.MaxMessage)
+	scanner.Split(splitOn(sep))
+
+	for scanner.Scan() {
+		msg := scanner.Text()
+		if each != nil {
+			each(msg)
+		}
+		select {
+		case drain <- msg:
+		case <-p.quit:
+			return
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		p.fail(fmt.Errorf("reading output: %w", err))
+	}
+}
+
+// keepStderr keeps the last few lines from stderr
+func (p *process) keepStderr(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	p.stderr = append(p.stderr, line)
+	if len(p.stderr) > stderrLines {
+		p.stderr = p.stderr[1:]
+	}
+}
+
+// write passes input to the process until stdin is closed.
+// Once the process has exited (or we fail to write) input is read & dropped
+// so that writers are never left blocked.
+func (p *process) write(stdin <-chan string) {
+	defer p.stdinPipe.Close()
+
+	for input := range stdin {
+		if input == "" || p.failed() {
+			continue
+		}
+
+		_, err := io.WriteString(p.stdinPipe, input)
+		if err != nil {
+			p.fail(fmt.Errorf("writing input: %w", err))
+		}
+	}
+}
+
+// control kills the process if the caller sends any signal (or closes ctrl)
+func (p *process) control(ctrl <-chan os.Signal) {
+	select {
+	case <-ctrl:
+		p.kill()
+	case <-p.exited:
+	}
 }
 
-// determineMsgs breaks a long string (potentially many messages) into message
-// chunks. Any remaining chunks are returned as is incase they're partial
-// messages.
-func determineMsgs(in, sep string) ([]string, string) {
-	bits := strings.Split(in, sep)
+// wait waits for the process to exit & gives the caller an error if it
+// didn't exit cleanly
+func (p *process) wait(pumps *sync.WaitGroup, errs chan<- error) {
+	defer close(errs)
+
+	// nb. all reads must finish before Wait closes the pipes
+	pumps.Wait()
+	p.cmd.Wait()
 
-	if len(bits) == 1 {
-		return []string{}, in
+	p.lock.Lock()
+	p.done = true
+	close(p.exited)
+	err, killed := p.err, p.killed
+	p.lock.Unlock()
+
+	code := -1
+	if p.cmd.ProcessState != nil {
+		code = p.cmd.ProcessState.ExitCode()
+	}
+	if err == nil && (killed || code == 0) {
+		// exited by itself or was asked to
+		return
 	}
 
-	return bits[0 : len(bits)-1], bits[len(bits)-1]
+	errs <- &ExitError{Code: code, Stderr: strings.Join(p.stderr, "\n"), Err: err}
 }
 
-// pump continuously reads from the given read and writes messages into the given
-// drain channel. Messages are split by `sep`
-func pump(src io.Reader, drain chan<- string, errs chan<- error, sep string) {
-	go func() {
-		soFar := ""
-		for {
-			buf := make([]byte, 2048)
-			_, err := src.Read(buf)
-
-			if err != nil {
-				if err == io.EOF {
-					errs <- nil
-				} else {
-					errs <- err
-				}
-			}
+// fail records why the process is being killed & kills it
+func (p *process) fail(err error) {
+	p.lock.Lock()
+	if p.err == nil && !p.killed && !p.done {
+		p.err = err
+	}
+	p.lock.Unlock()
+	p.kill()
+}
 
-			soFar += strings.Trim(string(buf), "\x00")
+// failed returns if the process has been killed or has exited
+func (p *process) failed() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.killed || p.done
+}
 
-			msgs, remaining := determineMsgs(soFar, sep)
-			for _, msg := range msgs {
-				drain <- msg
-				soFar = remaining
-			}
+// kill the process, if it hasn't been already
+func (p *process) kill() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.killed || p.done {
+		return
+	}
+	p.killed = true
+	close(p.quit)
+	p.cmd.Process.Kill()
+
+	// nb. children of the process may still hold the other ends open
+	p.stdoutPipe.Close()
+	p.stderrPipe.Close()
+}
+
+// splitOn returns a split function for a bufio.Scanner that splits
+// messages by `sep`. Anything left when the reader ends is given as a
+// final message.
+func splitOn(sep string) bufio.SplitFunc {
+	s := []byte(sep)
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		i := bytes.Index(data, s)
+		if i >= 0 {
+			return i + len(s), data[:i], nil
 		}
-	}()
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		// ask for more data
+		return 0, nil, nil
+	}
 }
@@ -1,29 +1,171 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
-func TestDetermineMsgs(t *testing.T) {
+// collect reads everything from Run's chans until they're closed
+func collect(t *testing.T, stdout, stderr <-chan string, errs <-chan error) ([]string, []string, []error) {
+	outs, serrs, es := []string{}, []string{}, []error{}
+	timeout := time.After(5 * time.Second)
+	for stdout != nil || stderr != nil || errs != nil {
+		select {
+		case msg, ok := <-stdout:
+			if !ok {
+				stdout = nil
+				continue
+			}
+			outs = append(outs, msg)
+		case msg, ok := <-stderr:
+			if !ok {
+				stderr = nil
+				continue
+			}
+			serrs = append(serrs, msg)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			es = append(es, err)
+		case <-timeout:
+			t.Fatal("timed out waiting for process")
+			return outs, serrs, es
+		}
+	}
+	return outs, serrs, es
+}
+
+func TestSplitOn(t *testing.T) {
 	cases := []struct {
-		Given        string
-		Sep          string
-		ExpectParsed []string
-		ExpectRemain string
+		Given  string
+		Sep    string
+		Expect []string
 	}{
-		{"a\n\nb\n\ncd\n", "\n\n", []string{"a", "b"}, "cd\n"},
-		{"a\nb\n\nc", "\n", []string{"a", "b", ""}, "c"},
+		{"a\n\nb\n\ncd\n", "\n\n", []string{"a", "b", "cd\n"}},
+		{"a\nb\n\nc", "\n", []string{"a", "b", "", "c"}},
+		{"|request|{}\n\n", "\n\n", []string{"|request|{}"}},
+		{"", "\n\n", []string{}},
 	}
 
 	for i, tt := range cases {
-		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			messages, remaining := determineMsgs(tt.Given, tt.Sep)
+		for name, wrap := range map[string]func(string) *bufio.Scanner{
+			"whole": func(s string) *bufio.Scanner {
+				return bufio.NewScanner(strings.NewReader(s))
+			},
+			"bytes": func(s string) *bufio.Scanner {
+				return bufio.NewScanner(iotest.OneByteReader(strings.NewReader(s)))
+			},
+		} {
+			t.Run(fmt.Sprintf("%d-%s", i, name), func(t *testing.T) {
+				scanner := wrap(tt.Given)
+				scanner.Split(splitOn(tt.Sep))
+
+				result := []string{}
+				for scanner.Scan() {
+					result = append(result, scanner.Text())
+				}
 
-			assert.Equal(t, tt.ExpectParsed, messages)
-			assert.Equal(t, tt.ExpectRemain, remaining)
-		})
+				assert.Nil(t, scanner.Err())
+				assert.Equal(t, tt.Expect, result)
+			})
+		}
 	}
+}
+
+func TestRun(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	stdout, stderr, errs := Run("cat", nil, stdin, ctrl)
+	stdin <- "a\n\nb"
+	stdin <- "c\n\n"
+	stdin <- "d"
+	close(stdin)
+
+	outs, serrs, es := collect(t, stdout, stderr, errs)
+
+	assert.Equal(t, []string{"a", "bc", "d"}, outs)
+	assert.Equal(t, []string{}, serrs)
+	assert.Equal(t, []error{}, es)
+}
+
+func TestRunExitError(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	stdout, stderr, errs := Run("sh", []string{"-c", "echo out; echo oops >&2; exit 3"}, stdin, ctrl, Seperator("\n"))
+
+	outs, serrs, es := collect(t, stdout, stderr, errs)
+
+	assert.Equal(t, []string{"out"}, outs)
+	assert.Equal(t, []string{"oops"}, serrs)
+	assert.Equal(t, []error{&ExitError{Code: 3, Stderr: "oops"}}, es)
+	assert.Equal(t, "process exited with code 3: oops", es[0].Error())
+}
+
+func TestRunMaxMessage(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	stdout, stderr, errs := Run("sh", []string{"-c", "printf 'ab\n\nabcdefgh'; sleep 10"}, stdin, ctrl, MaxMessage(4))
+
+	outs, _, es := collect(t, stdout, stderr, errs)
+
+	assert.Equal(t, []string{"ab"}, outs)
+	assert.Equal(t, 1, len(es))
+	assert.True(t, errors.Is(es[0], bufio.ErrTooLong))
+	assert.Equal(t, -1, es[0].(*ExitError).Code)
+}
+
+func TestRunKill(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	stdout, stderr, errs := Run("cat", nil, stdin, ctrl)
+	close(ctrl)
+
+	outs, _, es := collect(t, stdout, stderr, errs)
+
+	assert.Equal(t, []string{}, outs)
+	assert.Equal(t, []error{}, es)
+
+	// input after the process has gone is dropped
+	stdin <- "a\n\n"
+	close(stdin)
+}
+
+func TestRunKillUnread(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	// nothing reads stdout, so the process is held up writing
+	stdout, stderr, errs := Run("yes", nil, stdin, ctrl, Seperator("\n"), Buffer(1))
+	time.Sleep(50 * time.Millisecond)
+	close(ctrl)
+
+	_, _, es := collect(t, stdout, stderr, errs)
+	assert.Equal(t, []error{}, es)
+}
+
+func TestRunMissingBinary(t *testing.T) {
+	stdin := make(chan string)
+	ctrl := make(chan os.Signal)
+
+	stdout, stderr, errs := Run("not-a-real-binary", nil, stdin, ctrl)
+	stdin <- "a\n\n"
+
+	outs, _, es := collect(t, stdout, stderr, errs)
 
+	assert.Equal(t, []string{}, outs)
+	assert.Equal(t, 1, len(es))
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"syscall"
 )
 
 // activeCmd is a holder for a commands stdout, err, in etc
@@ -19,16 +18,15 @@ type activeCmd struct {
 
 // stop kills the cmd
 func (a *activeCmd) stop() {
-	defer close(a.stdin)
-	defer close(a.ctrl)
-	a.ctrl <- syscall.SIGINT
+	close(a.stdin)
+	close(a.ctrl)
 }
 
-func (a *activeCmd) start(seed int, format string, teamsizes []int, teams []string) error {
+func (a *activeCmd) start(seed [4]uint16, format string, teamsizes []int, teams []string) error {
 	// pushes in initial stdin to kick off battle
 	// #1 push in the battle format
 	data, err := json.Marshal(map[string]interface{}{
-		"seed":     []int{seed, seed, seed, seed},
+		"seed":     seed,
 		"formatid": format,
 	})
 	if err != nil {
@@ -0,0 +1,30 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartSendsSeed(t *testing.T) {
+	cmd := &activeCmd{stdin: make(chan string, 10)}
+
+	err := cmd.start([4]uint16{1, 2, 3, 4}, "gen8ou", []int{1}, []string{""})
+
+	assert.Nil(t, err)
+	assert.Equal(t, ">start {\"formatid\":\"gen8ou\",\"seed\":[1,2,3,4]}\n", <-cmd.stdin)
+}
+
+func TestConfigDefaultSeed(t *testing.T) {
+	a := &Config{}
+	a.defaults()
+	b := &Config{}
+	b.defaults()
+
+	c := &Config{Seed: [4]uint16{1, 2, 3, 4}}
+	c.defaults()
+
+	assert.NotEqual(t, [4]uint16{}, a.Seed)
+	assert.NotEqual(t, a.Seed, b.Seed)
+	assert.Equal(t, [4]uint16{1, 2, 3, 4}, c.Seed)
+}
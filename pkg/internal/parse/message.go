@@ -15,6 +15,10 @@ type Message struct {
 	Event  *event.Event
 	Update *structs.Update
 	Error  error
+
+	// Player is who the Error is for, if the simulator said (ie. choice
+	// errors, which are given in side updates)
+	Player string
 }
 
 // message makes a new message for the given item
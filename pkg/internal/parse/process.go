@@ -1,12 +1,13 @@
 package parse
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/voidshard/poke-showdown-go/pkg/event"
 	"github.com/voidshard/poke-showdown-go/pkg/internal/cmd"
@@ -14,9 +15,6 @@ import (
 )
 
 var (
-	// random number generator
-	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	// indirection allows for easier testing
 	runCommand = cmd.Run
 
@@ -27,8 +25,41 @@ var (
 	// ErrUnavailableChoice indicates that the given (usually valid) choice
 	// cannot be done for some reason (ie. move is disabled)
 	ErrUnavailableChoice = fmt.Errorf("unavailable choice https://github.com/smogon/pokemon-showdown/blob/master/sim/SIM-PROTOCOL.md")
+
+	// ErrExited indicates that the simulator exited with an error, or
+	// couldn't be started
+	ErrExited = fmt.Errorf("simulator exited")
+
+	// ErrMalformed indicates that the simulator wrote something we
+	// couldn't read
+	ErrMalformed = fmt.Errorf("malformed simulator output")
+
+	// ErrStopped is returned when writing to a stopped simulator
+	ErrStopped = fmt.Errorf("simulator has been stopped")
 )
 
+// wrapError is an error of some kind (ie. ErrExited) with a cause (ie. a
+// *cmd.ExitError). Both can be found with errors.Is / errors.As.
+type wrapError struct {
+	kind  error
+	cause error
+}
+
+// Error implements error
+func (e *wrapError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind, e.cause)
+}
+
+// Is returns if the target is our kind of error
+func (e *wrapError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap returns the cause
+func (e *wrapError) Unwrap() error {
+	return e.cause
+}
+
 // Process wraps an active pokemon showdown process
 type Process struct {
 	// underlying process (mostly a holder for it's stdout, strerr, stdin)
@@ -36,12 +67,18 @@ type Process struct {
 
 	// output messages from simulator
 	messages chan *Message
+
+	// lock is held (read) while writing to stdin so that it isn't closed
+	// mid write, done is closed when we're stopped
+	lock sync.RWMutex
+	done chan struct{}
+	stop sync.Once
 }
 
 // Config holds settings relevant to kicking off a showdown battle
 type Config struct {
 	Binary    string
-	Seed      int
+	Seed      [4]uint16
 	Format    string
 	Teams     []string
 	TeamSizes []int
@@ -49,8 +86,8 @@ type Config struct {
 
 // defaults sets unset fields
 func (c *Config) defaults() {
-	if c.Seed == 0 {
-		c.Seed = rng.Int()
+	if c.Seed == [4]uint16{} {
+		c.Seed = randomSeed()
 	}
 	if c.Binary == "" {
 		c.Binary = "pokemon-showdown"
@@ -60,6 +97,17 @@ func (c *Config) defaults() {
 	}
 }
 
+// randomSeed returns a seed from a cryptographically secure source
+func randomSeed() [4]uint16 {
+	seed := [4]uint16{}
+	err := binary.Read(rand.Reader, binary.BigEndian, &seed)
+	if err != nil || seed == [4]uint16{} {
+		// this really shouldn't happen
+		seed[3] = 1
+	}
+	return seed
+}
+
 // NewProcess starts a new battle simulation
 func NewProcess(cfg *Config) (*Process, error) {
 	cfg.defaults()
@@ -84,23 +132,32 @@ func NewProcess(cfg *Config) (*Process, error) {
 	proc := &Process{
 		raw:      raw,
 		messages: make(chan *Message),
+		done:     make(chan struct{}),
 	}
 
 	go func() {
-		// push errors from the raw handler into our error chan
-		for err := range errs {
-			log.Printf("err: %v\n", err)
-			proc.messages <- message(err)
+		// the simulator only writes to stderr when something has gone
+		// wrong, the last lines are given in the error when it exits
+		for range stderr {
 		}
 	}()
 
 	go func() {
+		defer close(proc.messages)
+
 		// read messages from raw stdout and parse into showdown structs
 		// results are pushed into process chans
 		for msg := range proc.raw.stdout {
 			log.Printf("msg: %s\n", msg)
 			proc.parseStdout(msg)
 		}
+
+		// stdout is closed when the process has exited, errors (if any)
+		// say why
+		for err := range proc.raw.errors {
+			log.Printf("err: %v\n", err)
+			proc.messages <- message(&wrapError{kind: ErrExited, cause: err})
+		}
 	}()
 
 	err := raw.start(cfg.Seed, cfg.Format, cfg.TeamSizes, cfg.Teams)
@@ -111,14 +168,23 @@ func NewProcess(cfg *Config) (*Process, error) {
 // errors or side updates (as applicable). Not all lines that are printed are parsed;
 // some are unimportant, diagnostic info (stuff we already know) or simply not useful
 // as events.
-// Eg. team preview messages, duplicate health/damage updates (with percentages that
-// we can calculate anyways if needed), server time, info about the format, players
-// or format rules.
+// Eg. team preview messages, server time, info about the format, players or format
+// rules. Secret & public pairs of lines (after |split|) are given as one event.
 func (s *Process) parseStdout(raw string) {
 	// requires showdown version 0.11.4+ to fix a bug where messages are not returned
 	lines := strings.Split(strings.Trim(strings.TrimSpace(raw), "\x00"), "\n")
+
+	// player a side update is for
+	player := ""
+
 	for i := 0; i < len(lines); i++ {
-		if strings.HasPrefix(lines[i], "|request|") {
+		if lines[i] == "sideupdate" {
+			// the next line is the player
+			player = line(lines, i+1)
+			i++
+		} else if lines[i] == "update" {
+			player = ""
+		} else if strings.HasPrefix(lines[i], "|request|") {
 			bits := strings.Split(lines[i], "|")
 
 			encoded := bits[len(bits)-1]
@@ -130,33 +196,10 @@ func (s *Process) parseStdout(raw string) {
 			// the simulator is asking a player to make a choice
 			update, err := structs.DecodeUpdate([]byte(encoded))
 			if err != nil {
-				s.messages <- message(err)
+				s.messages <- message(&wrapError{kind: ErrMalformed, cause: err})
 				continue
 			}
 			s.messages <- message(update)
-		} else if strings.HasPrefix(lines[i], "|switch|") || strings.HasPrefix(lines[i], "|-damage|") || strings.HasPrefix(lines[i], "|-heal|") {
-			// Nb. the simulator returns duplicates of some messages
-			// - in particular switch, damage and heal messages
-			// can be replayed with both actual health & percentage
-			// (/100) values. This is super annoying and it's easy
-			// to figure out a percentage given the health values.
-			// So here we strip out the duplicates ..
-			bitsthis := strings.Split(lines[i], "|")
-
-			// if it's not the same event type as previous
-			if !strings.HasPrefix(lines[i-1], "|"+bitsthis[1]) {
-				evt := event.Parse(lines[i])
-				if evt != nil {
-					s.messages <- message(evt)
-				}
-				continue
-			}
-
-			bitsprev := strings.Split(lines[i-1], "|")
-			// if it is the same type as previous & about the same pokemon
-			if bitsthis[2] == bitsprev[2] {
-				continue
-			}
 		} else if strings.HasPrefix(lines[i], "|error|") {
 			if strings.Contains(lines[i], "Can't choose for Team Preview") {
 				// we totally ignore team preview messages, so we also
@@ -168,20 +211,17 @@ func (s *Process) parseStdout(raw string) {
 				continue
 			}
 
-			err := fmt.Errorf(strings.Replace(lines[i], "|error|", "", 1))
-
-			// there are two specific sub errors we're really interested in
-			// since they both indicate that a user explicitly did something
-			// wrong.
-			if strings.Contains(lines[i], "[Invalid choice]") {
-				err = fmt.Errorf("%w %v", ErrInvalidChoice, err)
-			} else if strings.Contains(lines[i], "[Unavailable choice]") {
-				err = fmt.Errorf("%w %v", ErrUnavailableChoice, err)
-			}
-
-			s.messages <- message(err)
+			m := message(LineError(lines[i]))
+			m.Player = player
+			s.messages <- m
 		} else if strings.HasPrefix(lines[i], "|split|") {
-			continue
+			// the next line is secret (exact HP, for the player) & the
+			// one after is public (HP as a percentage, for everyone else)
+			evt := event.ParseSplit(line(lines, i+1), line(lines, i+2))
+			i += 2
+			if evt != nil {
+				s.messages <- message(evt)
+			}
 		} else if strings.HasPrefix(lines[i], "|start") {
 			continue
 		} else if strings.HasPrefix(lines[i], "|poke|") {
@@ -200,32 +240,77 @@ func (s *Process) parseStdout(raw string) {
 			continue
 		} else if strings.HasPrefix(lines[i], "|rule|") {
 			continue
+		} else if strings.HasPrefix(lines[i], "|clearpoke") || strings.HasPrefix(lines[i], "|teampreview") || strings.HasPrefix(lines[i], "|updatepoke|") {
+			continue
 		} else if strings.HasPrefix(lines[i], "|") {
-			if strings.Count(lines[i], "|") > 1 {
-				// if we got this far, then it's probably a noteworthy event
-				evt := event.Parse(lines[i])
-				if evt != nil {
-					s.messages <- message(evt)
-				}
+			// if we got this far, then it's probably a noteworthy event
+			// (if the event parser recognises it)
+			evt := event.Parse(lines[i])
+			if evt != nil {
+				s.messages <- message(evt)
 			}
 		}
 	}
 }
 
-// Messages are parsed messages (in order) from the showdown simulator
+// line returns the i'th line, or "" if there isn't one
+func line(lines []string, i int) string {
+	if i >= len(lines) {
+		return ""
+	}
+	return lines[i]
+}
+
+// LineError returns the error given by an "|error|" line
+func LineError(line string) error {
+	err := fmt.Errorf(strings.Replace(line, "|error|", "", 1))
+
+	// there are two specific sub errors we're really interested in
+	// since they both indicate that a user explicitly did something
+	// wrong.
+	if strings.Contains(line, "[Invalid choice]") {
+		err = fmt.Errorf("%w %v", ErrInvalidChoice, err)
+	} else if strings.Contains(line, "[Unavailable choice]") {
+		err = fmt.Errorf("%w %v", ErrUnavailableChoice, err)
+	}
+	return err
+}
+
+// Messages are parsed messages (in order) from the showdown simulator.
+// The chan is closed when the simulator exits, if it didn't exit cleanly
+// the last message is an error saying why.
 func (s *Process) Messages() <-chan *Message {
 	return s.messages
 }
 
 // Write writes one player decision(s) for their pokemon (1 or more) to
-// the simulator.
+// the simulator. ErrStopped is returned once the process is stopped.
 func (s *Process) Write(in string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	select {
+	case <-s.done:
+		return ErrStopped
+	default:
+	}
+
 	log.Printf(in)
-	s.raw.stdin <- in
-	return nil
+	select {
+	case s.raw.stdin <- in:
+		return nil
+	case <-s.done:
+		return ErrStopped
+	}
 }
 
 // Stop simulation & kill subprocess(es)
 func (s *Process) Stop() {
-	s.raw.stop()
+	s.stop.Do(func() {
+		// nb. writes waiting on stdin give up when done is closed
+		close(s.done)
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.raw.stop()
+	})
 }
@@ -1,10 +1,15 @@
 package parse
 
 import (
+	"errors"
+	"os"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/cmd"
 )
 
 var dataTestParseStdout = `p1
@@ -82,3 +87,170 @@ func TestParseStdout(t *testing.T) {
 	assert.Equal(t, "-fieldstart", msgs[4].Event.Type)
 	assert.Equal(t, "turn", msgs[5].Event.Type)
 }
+
+func TestParseStdoutProgress(t *testing.T) {
+	proc := &Process{messages: make(chan *Message)}
+	msgs := []*Message{}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for m := range proc.messages {
+			msgs = append(msgs, m)
+		}
+	}()
+
+	proc.parseStdout("update\n|\n|-weather|RainDance|[upkeep]\n|upkeep\n|raw|<b>hi</b>\n|tie")
+
+	close(proc.messages)
+	wg.Wait()
+
+	assert.Equal(t, 4, len(msgs))
+	assert.Equal(t, "-weather", msgs[0].Event.Type)
+	assert.Equal(t, "upkeep", msgs[1].Event.Type)
+	assert.Equal(t, "raw", msgs[2].Event.Type)
+	assert.Equal(t, "tie", msgs[3].Event.Type)
+}
+
+var dataTestLineError = []struct {
+	In          string
+	Invalid     bool
+	Unavailable bool
+}{
+	{"|error|[Invalid choice] Can't switch: The active Pokémon is trapped", true, false},
+	{"|error|[Unavailable choice] Can't move: Tackle is disabled", false, true},
+	{"|error|something else", false, false},
+}
+
+func TestLineError(t *testing.T) {
+	for _, tt := range dataTestLineError {
+		t.Run(tt.In, func(t *testing.T) {
+			result := LineError(tt.In)
+
+			assert.Equal(t, tt.Invalid, errors.Is(result, ErrInvalidChoice))
+			assert.Equal(t, tt.Unavailable, errors.Is(result, ErrUnavailableChoice))
+			assert.Contains(t, result.Error(), strings.TrimPrefix(tt.In, "|error|"))
+		})
+	}
+}
+
+func TestParseStdoutErrorPlayer(t *testing.T) {
+	proc := &Process{messages: make(chan *Message)}
+	msgs := []*Message{}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for m := range proc.messages {
+			msgs = append(msgs, m)
+		}
+	}()
+
+	proc.parseStdout("sideupdate\np2\n|error|[Invalid choice] Can't move: Invalid target")
+	proc.parseStdout("update\n|error|something else")
+
+	close(proc.messages)
+	wg.Wait()
+
+	assert.Equal(t, 2, len(msgs))
+	assert.True(t, errors.Is(msgs[0].Error, ErrInvalidChoice))
+	assert.Equal(t, "p2", msgs[0].Player)
+	assert.Equal(t, "", msgs[1].Player)
+}
+
+func TestParseStdoutSplit(t *testing.T) {
+	proc := &Process{messages: make(chan *Message)}
+	msgs := []*Message{}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for m := range proc.messages {
+			msgs = append(msgs, m)
+		}
+	}()
+
+	// a multi-hit move, then recoil; the same pokemon takes damage twice in a row
+	proc.parseStdout(strings.Join([]string{
+		"update",
+		"|move|p1a: Cloyster|Icicle Spear|p2a: Liepard",
+		"|split|p2",
+		"|-damage|p2a: Liepard|200/256",
+		"|-damage|p2a: Liepard|79/100",
+		"|split|p2",
+		"|-damage|p2a: Liepard|130/256",
+		"|-damage|p2a: Liepard|51/100",
+		"|-damage|p1a: Cloyster|50/100|[from] Recoil",
+		"|-damage|p1a: Cloyster|40/100|[from] Recoil",
+		"|split|p1",
+	}, "\n"))
+
+	close(proc.messages)
+	wg.Wait()
+
+	assert.Equal(t, 5, len(msgs))
+	assert.Equal(t, "move", msgs[0].Event.Type)
+
+	expect := [][3]int{{200, 256, 79}, {130, 256, 51}, {50, 100, 50}, {40, 100, 40}}
+	for i, want := range expect {
+		dmg, ok := msgs[i+1].Event.Payload.(*event.DamageEvent)
+		assert.True(t, ok)
+		assert.Equal(t, want, [3]int{dmg.HP, dmg.MaxHP, dmg.Percent})
+	}
+}
+
+func TestNewProcessExitError(t *testing.T) {
+	exitErr := &cmd.ExitError{Code: 1, Stderr: "TypeError"}
+	defer func() { runCommand = cmd.Run }()
+	runCommand = func(_ string, _ []string, stdin <-chan string, _ chan os.Signal, _ ...cmd.Option) (<-chan string, <-chan string, <-chan error) {
+		go func() {
+			for range stdin {
+			}
+		}()
+		stdout := make(chan string, 1)
+		stderr := make(chan string, 1)
+		errs := make(chan error, 1)
+		stdout <- "update\n|turn|1"
+		stderr <- "TypeError"
+		errs <- exitErr
+		close(stdout)
+		close(stderr)
+		close(errs)
+		return stdout, stderr, errs
+	}
+
+	proc, err := NewProcess(&Config{Teams: []string{"", ""}, TeamSizes: []int{0, 0}})
+	assert.Nil(t, err)
+
+	msgs := []*Message{}
+	for m := range proc.Messages() {
+		msgs = append(msgs, m)
+	}
+
+	assert.Equal(t, 2, len(msgs))
+	assert.Equal(t, event.Turn, msgs[0].Event.Type)
+	assert.True(t, errors.Is(msgs[1].Error, ErrExited))
+	assert.Equal(t, "simulator exited: process exited with code 1: TypeError", msgs[1].Error.Error())
+
+	var result *cmd.ExitError
+	assert.True(t, errors.As(msgs[1].Error, &result))
+	assert.Equal(t, exitErr, result)
+}
+
+func TestProcessWriteAfterStop(t *testing.T) {
+	proc := &Process{
+		raw:  &activeCmd{stdin: make(chan string), ctrl: make(chan os.Signal)},
+		done: make(chan struct{}),
+	}
+
+	proc.Stop()
+	proc.Stop()
+
+	assert.Equal(t, ErrStopped, proc.Write(">p1 move 1\n"))
+}
@@ -0,0 +1,139 @@
+// Package simtest has fake simulator streams & battles for tests of
+// packages that play battles.
+package simtest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Stream plays out scripted rounds, each round is sent once both players
+// have written their choice
+type Stream struct {
+	lock    sync.Mutex
+	updates chan *sim.Update
+	rounds  [][]*sim.Update
+	writes  int
+	reject  map[string]int
+	chosen  map[string]int
+}
+
+// NewStream returns a stream that sends the first round straight away
+func NewStream(rounds ...[]*sim.Update) *Stream {
+	s := &Stream{updates: make(chan *sim.Update, 100), rounds: rounds[1:], reject: map[string]int{}, chosen: map[string]int{}}
+	s.send(rounds[0])
+	return s
+}
+
+func (s *Stream) send(round []*sim.Update) {
+	for _, u := range round {
+		s.updates <- u
+	}
+}
+
+// Reject sets how many of the player's choices are rejected as invalid
+func (s *Stream) Reject(player string, n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.reject[player] = n
+}
+
+// Write records a choice, sending the next round once both players
+// have chosen
+func (s *Stream) Write(a *sim.Action) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.chosen[a.Player]++
+	if s.reject[a.Player] > 0 {
+		s.reject[a.Player]--
+		s.updates <- &sim.Update{Error: fmt.Errorf("%w [Invalid choice]", parse.ErrInvalidChoice), Player: a.Player}
+		return nil
+	}
+
+	s.writes++
+	if s.writes%2 == 0 && len(s.rounds) > 0 {
+		s.send(s.rounds[0])
+		s.rounds = s.rounds[1:]
+	}
+	return nil
+}
+
+// Chosen returns how many choices the player has written, including
+// those that were rejected
+func (s *Stream) Chosen(player string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.chosen[player]
+}
+
+// Updates returns the scripted updates
+func (s *Stream) Updates() <-chan *sim.Update {
+	return s.updates
+}
+
+// Stop does nothing, the battle is left as it is
+func (s *Stream) Stop() {}
+
+// Close ends the updates, as if the simulator exited
+func (s *Stream) Close() {
+	close(s.updates)
+}
+
+// Request returns a side request for the player, with a single pokemon
+// at the given HP (out of 100) that knows Tackle. At 0 HP the pokemon has
+// fainted & the player has nothing to do.
+func Request(player string, hp int) *sim.Update {
+	return &sim.Update{Side: &sim.Side{
+		Player: player,
+		Wait:   hp == 0,
+		Field: []*sim.Slot{&sim.Slot{
+			ID:      player + "a",
+			Options: &sim.Options{Moves: []*sim.Move{&sim.Move{ID: "tackle"}}},
+		}},
+		Pokemon: []*sim.Pokemon{&sim.Pokemon{
+			Active: true,
+			Status: &structs.Status{HPNow: hp, HPMax: 100, IsFainted: hp == 0},
+		}},
+	}}
+}
+
+// Battle is a battle (with seed 1, 2, 3, 4) where p1 knocks out p2 over
+// two turns
+func Battle() *Stream {
+	return NewStream(
+		[]*sim.Update{
+			&sim.Update{Seed: sim.Seed{1, 2, 3, 4}},
+			Request("p1", 100),
+			Request("p2", 100),
+			&sim.Update{Event: event.Parse("|turn|1")},
+		},
+		[]*sim.Update{
+			Request("p1", 100),
+			Request("p2", 50),
+			&sim.Update{Event: event.Parse("|move|p1a: Lugia|Tackle|p2a: Umbreon")},
+			&sim.Update{Event: event.Parse("|-damage|p2a: Umbreon|50/100")},
+			&sim.Update{Event: event.Parse("|turn|2")},
+		},
+		[]*sim.Update{
+			Request("p2", 0),
+			&sim.Update{Event: event.Parse("|move|p1a: Lugia|Tackle|p2a: Umbreon")},
+			&sim.Update{Event: event.Parse("|-damage|p2a: Umbreon|0 fnt")},
+			&sim.Update{Event: event.Parse("|faint|p2a: Umbreon")},
+			&sim.Update{Event: event.Parse("|win|p1")},
+		},
+	)
+}
+
+// Spec returns a battle spec with a single (blank) pokemon per player
+func Spec() *sim.BattleSpec {
+	return &sim.BattleSpec{
+		Format:  sim.FormatGen8,
+		Players: [][]*sim.PokemonSpec{{&sim.PokemonSpec{}}, {&sim.PokemonSpec{}}},
+	}
+}
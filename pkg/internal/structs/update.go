@@ -27,7 +27,7 @@ func DecodeUpdate(data []byte) (*Update, error) {
 			HPMax:       maxHP,
 			IsAsleep:    pkm.isAsleep(),
 			IsBurned:    pkm.isBurned(),
-			IsPoisoned:  pkm.isBurned(),
+			IsPoisoned:  pkm.isPoisoned(),
 			IsToxiced:   pkm.isToxiced(),
 			IsFrozen:    pkm.isFrozen(),
 			IsParalyzed: pkm.isParalyzed(),
@@ -84,5 +84,6 @@ type ActiveData struct {
 // Nb. order here is important.
 type Team struct {
 	Player  string     `json:"name"`
+	ID      string     `json:"id"`
 	Pokemon []*Pokemon `json:"pokemon"`
 }
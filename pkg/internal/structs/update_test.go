@@ -116,6 +116,9 @@ func TestDecodeUpdateSwitch(t *testing.T) {
 	assert.NotNil(t, result)
 
 	assert.Equal(t, 0, len(result.Active))
+	assert.True(t, result.Team.Pokemon[0].Status.IsPoisoned)
+	assert.False(t, result.Team.Pokemon[0].Status.IsBurned)
+	assert.False(t, result.Team.Pokemon[1].Status.IsPoisoned)
 }
 
 func TestDecodeUpdate(t *testing.T) {
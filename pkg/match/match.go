@@ -0,0 +1,257 @@
+/*
+Package match plays battles between players from start to finish.
+*/
+package match
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// indirection allows for easier testing
+	newStream = sim.NewSimulatorStream
+
+	// ErrTimeout is returned if a battle doesn't finish in time
+	ErrTimeout = errors.New("battle timed out")
+
+	// ErrTooManyRetries is returned if players keep making invalid choices
+	ErrTooManyRetries = errors.New("too many invalid choices")
+
+	// ErrIncomplete is returned if the simulator stops before the battle
+	// has a result
+	ErrIncomplete = errors.New("battle ended without a result")
+)
+
+// Result is the outcome of a single battle
+type Result struct {
+	// Index of the battle (see Run)
+	Index int `json:"index"`
+
+	// Seed the battle was played with
+	Seed sim.Seed `json:"seed"`
+
+	// Winner is the winning player (p1, p2) if any
+	Winner string `json:"winner"`
+	Tie    bool   `json:"tie"`
+	Turns  int    `json:"turns"`
+
+	// Remaining is the number of pokemon each player has left
+	Remaining map[string]int `json:"remaining"`
+
+	Duration time.Duration `json:"duration"`
+
+	// Error is set if the battle could not be played out
+	Error string `json:"error,omitempty"`
+}
+
+// Option is some option for Play or Run
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Timeout time.Duration
+	Retries int
+}
+
+func buildOpts(in []Option) *opts {
+	cfg := &opts{Timeout: 5 * time.Minute, Retries: 10}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Timeout sets how long a battle may take before we give up on it.
+// Defaults to 5 minutes, zero means no timeout.
+func Timeout(d time.Duration) Option {
+	return func(o *opts) {
+		o.Timeout = d
+	}
+}
+
+// Retries sets how many invalid choices we'll retry (with a random legal
+// choice) in a single battle before giving up on it. Defaults to 10.
+func Retries(n int) Option {
+	return func(o *opts) {
+		o.Retries = n
+	}
+}
+
+// Play runs the given battle to the end, where players[0] is p1,
+// players[1] is p2 and so on.
+func Play(spec *sim.BattleSpec, players []player.Player, in ...Option) (*Result, error) {
+	cfg := buildOpts(in)
+	start := time.Now()
+
+	stream, err := newStream(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Stop()
+
+	res := &Result{Seed: spec.Seed, Remaining: map[string]int{}}
+	err = play(stream, players, cfg, res)
+	res.Duration = time.Since(start)
+	return res, err
+}
+
+// play reads updates & answers requests until the battle has a result
+func play(stream sim.SimulatorStream, players []player.Player, cfg *opts, res *Result) error {
+	var timeout <-chan time.Time
+	if cfg.Timeout > 0 {
+		timeout = time.After(cfg.Timeout)
+	}
+
+	// requests that have been answered but not yet superseded, by player
+	pending := map[string]*sim.Side{}
+	fallback := player.NewRandom(int64(res.Seed.Uint64()))
+	retries := 0
+
+	for {
+		var u *sim.Update
+		var ok bool
+		select {
+		case u, ok = <-stream.Updates():
+			if !ok {
+				return ErrIncomplete
+			}
+		case <-timeout:
+			return fmt.Errorf("%w after %v", ErrTimeout, cfg.Timeout)
+		}
+
+		if !u.Seed.IsZero() {
+			res.Seed = u.Seed
+		}
+
+		if u.Error != nil {
+			if sim.IsUnavailableChoice(u.Error) {
+				// the simulator sends a new request with what it revealed
+				continue
+			}
+			if !sim.IsInvalidChoice(u.Error) {
+				return u.Error
+			}
+			retries++
+			if retries > cfg.Retries {
+				return fmt.Errorf("%w %v", ErrTooManyRetries, u.Error)
+			}
+			side, ok := pending[u.Player]
+			if !ok {
+				return u.Error
+			}
+			act, err := fallback.Choose(side)
+			if err != nil {
+				return err
+			}
+			err = stream.Write(act)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if u.Side != nil {
+			delete(pending, u.Side.Player)
+			if _, ok := res.Remaining[u.Side.Player]; !ok {
+				res.Remaining[u.Side.Player] = len(u.Side.Pokemon)
+			}
+			if u.Side.Wait {
+				continue
+			}
+
+			idx := playerIndex(u.Side.Player)
+			if idx < 0 || idx >= len(players) {
+				return fmt.Errorf("no player given for %s", u.Side.Player)
+			}
+			act, err := players[idx].Choose(u.Side)
+			if err != nil {
+				return fmt.Errorf("%w %s failed to choose", err, u.Side.Player)
+			}
+			pending[u.Side.Player] = u.Side
+			err = stream.Write(act)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if u.Event == nil {
+			continue
+		}
+		switch u.Event.Type {
+		case event.Turn:
+			res.Turns = u.Event.Magnitude
+		case event.Faint:
+			if u.Event.Subject != nil {
+				res.Remaining[u.Event.Subject.Player]--
+			}
+		case event.Win:
+			res.Winner = u.Event.Name
+			return nil
+		case event.Tie:
+			res.Tie = true
+			return nil
+		}
+	}
+}
+
+// playerIndex returns the index of a player from their ID (p1 -> 0)
+func playerIndex(name string) int {
+	var i int
+	_, err := fmt.Sscanf(name, "p%d", &i)
+	if err != nil {
+		return -1
+	}
+	return i - 1
+}
+
+// Job is a single battle to be played by Run
+type Job struct {
+	Spec    *sim.BattleSpec
+	Players []player.Player
+}
+
+// Run plays the given battles, up to the given number at once. Results are
+// in the same order as the jobs, failed battles have their Error set.
+func Run(jobs []*Job, concurrency int, in ...Option) []*Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*Result, len(jobs))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				res, err := Play(jobs[i].Spec, jobs[i].Players, in...)
+				if res == nil {
+					res = &Result{Seed: jobs[i].Spec.Seed, Remaining: map[string]int{}}
+				}
+				if err != nil {
+					res.Error = err.Error()
+				}
+				res.Index = i
+				results[i] = res
+			}
+		}()
+	}
+
+	for i := range jobs {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	return results
+}
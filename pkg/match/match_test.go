@@ -0,0 +1,158 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/simtest"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+func testPlayers() []player.Player {
+	return []player.Player{player.NewRandom(0), player.NewMaxPower(0)}
+}
+
+func withStream(t *testing.T, fn func(spec *sim.BattleSpec) (sim.SimulatorStream, error)) {
+	orig := newStream
+	newStream = fn
+	t.Cleanup(func() { newStream = orig })
+}
+
+func TestPlay(t *testing.T) {
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return simtest.Battle(), nil
+	})
+
+	res, err := Play(simtest.Spec(), testPlayers())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "p1", res.Winner)
+	assert.False(t, res.Tie)
+	assert.Equal(t, 2, res.Turns)
+	assert.Equal(t, sim.Seed{1, 2, 3, 4}, res.Seed)
+	assert.Equal(t, map[string]int{"p1": 1, "p2": 0}, res.Remaining)
+}
+
+func TestPlayRetriesInvalidChoices(t *testing.T) {
+	f := simtest.Battle()
+	f.Reject("p1", 2)
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		return f, nil
+	})
+
+	res, err := Play(simtest.Spec(), testPlayers())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "p1", res.Winner)
+	assert.Equal(t, 4, f.Chosen("p1"))
+	assert.Equal(t, 2, f.Chosen("p2")) // p2's choices aren't replaced
+
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		f := simtest.Battle()
+		f.Reject("p1", 100)
+		return f, nil
+	})
+
+	_, err = Play(simtest.Spec(), testPlayers(), Retries(3))
+
+	assert.ErrorIs(t, err, ErrTooManyRetries)
+}
+
+func TestPlayTimeout(t *testing.T) {
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		// only one round; the battle never ends
+		return simtest.NewStream([]*sim.Update{simtest.Request("p1", 100)}), nil
+	})
+
+	_, err := Play(simtest.Spec(), testPlayers(), Timeout(10*time.Millisecond))
+
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestPlayIncomplete(t *testing.T) {
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		f := simtest.NewStream([]*sim.Update{simtest.Request("p1", 100)})
+		f.Close()
+		return f, nil
+	})
+
+	_, err := Play(simtest.Spec(), testPlayers())
+
+	assert.ErrorIs(t, err, ErrIncomplete)
+}
+
+func TestRun(t *testing.T) {
+	lock := sync.Mutex{}
+	count := 0
+	withStream(t, func(spec *sim.BattleSpec) (sim.SimulatorStream, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		count++
+		if count == 3 {
+			return nil, fmt.Errorf("simulator not found")
+		}
+		return simtest.Battle(), nil
+	})
+
+	jobs := []*Job{}
+	for i := 0; i < 5; i++ {
+		spec := simtest.Spec()
+		spec.Seed = sim.Seed{0, 0, 0, uint16(i + 1)}
+		jobs = append(jobs, &Job{Spec: spec, Players: testPlayers()})
+	}
+
+	results := Run(jobs, 2)
+
+	assert.Equal(t, 5, len(results))
+	failed := 0
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+		if r.Error != "" {
+			failed++
+			assert.Equal(t, jobs[i].Spec.Seed, r.Seed)
+		}
+	}
+	assert.Equal(t, 1, failed)
+
+	summary := Summarise(results)
+	assert.Equal(t, 5, summary.Battles)
+	assert.Equal(t, 1, summary.Errors)
+	assert.Equal(t, map[string]int{"p1": 4, "p2": 0}, summary.Wins)
+	assert.Equal(t, 1.0, summary.WinRate["p1"])
+	assert.Equal(t, 0.0, summary.WinRate["p2"])
+	assert.Equal(t, 2.0, summary.AverageTurns)
+}
+
+func TestWriteCSV(t *testing.T) {
+	results := []*Result{
+		&Result{Index: 0, Seed: sim.Seed{1, 2, 3, 4}, Winner: "p1", Turns: 10, Remaining: map[string]int{"p1": 2, "p2": 0}, Duration: 1500 * time.Millisecond},
+		&Result{Index: 1, Seed: sim.Seed{5, 6, 7, 8}, Tie: true, Turns: 20, Remaining: map[string]int{"p1": 0, "p2": 0}},
+		&Result{Index: 2, Error: "oh no", Remaining: map[string]int{}},
+	}
+
+	buf := &strings.Builder{}
+	err := WriteCSV(buf, results)
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"index,seed,winner,tie,turns,p1_remaining,p2_remaining,duration_ms,error",
+		"0,\"1,2,3,4\",p1,false,10,2,0,1500,",
+		"1,\"5,6,7,8\",,true,20,0,0,0,",
+		"2,\"0,0,0,0\",,false,0,0,0,0,oh no",
+		"",
+	}, "\n"), buf.String())
+
+	buf = &strings.Builder{}
+	err = Summarise(results).WriteCSV(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"player,battles,wins,ties,errors,win_rate,average_turns",
+		"p1,3,1,1,1,0.5000,15.00",
+		"p2,3,0,1,1,0.0000,15.00",
+		"",
+	}, "\n"), buf.String())
+}
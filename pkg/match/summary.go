@@ -0,0 +1,140 @@
+package match
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Summary is aggregate statistics over a number of battles
+type Summary struct {
+	Battles int `json:"battles"`
+	Ties    int `json:"ties"`
+	Errors  int `json:"errors"`
+
+	// Wins by player (p1, p2)
+	Wins map[string]int `json:"wins"`
+
+	// WinRate by player, over battles that finished
+	WinRate map[string]float64 `json:"win_rate"`
+
+	// AverageTurns over battles that finished
+	AverageTurns float64 `json:"average_turns"`
+}
+
+// Summarise returns aggregate statistics for the given results
+func Summarise(results []*Result) *Summary {
+	s := &Summary{Wins: map[string]int{}, WinRate: map[string]float64{}}
+
+	turns := 0
+	for _, r := range results {
+		s.Battles++
+		if r.Error != "" {
+			s.Errors++
+			continue
+		}
+		for name := range r.Remaining {
+			if _, ok := s.Wins[name]; !ok {
+				s.Wins[name] = 0
+			}
+		}
+		if r.Tie {
+			s.Ties++
+		} else if r.Winner != "" {
+			s.Wins[r.Winner]++
+		}
+		turns += r.Turns
+	}
+
+	finished := s.Battles - s.Errors
+	if finished == 0 {
+		return s
+	}
+	for name, wins := range s.Wins {
+		s.WinRate[name] = float64(wins) / float64(finished)
+	}
+	s.AverageTurns = float64(turns) / float64(finished)
+
+	return s
+}
+
+// WriteCSV writes results as CSV, one battle per row
+func WriteCSV(w io.Writer, results []*Result) error {
+	players := []string{}
+	seen := map[string]bool{}
+	for _, r := range results {
+		for name := range r.Remaining {
+			if !seen[name] {
+				seen[name] = true
+				players = append(players, name)
+			}
+		}
+	}
+	sort.Strings(players)
+
+	out := csv.NewWriter(w)
+	header := []string{"index", "seed", "winner", "tie", "turns"}
+	for _, name := range players {
+		header = append(header, fmt.Sprintf("%s_remaining", name))
+	}
+	header = append(header, "duration_ms", "error")
+
+	err := out.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := []string{
+			fmt.Sprintf("%d", r.Index),
+			r.Seed.String(),
+			r.Winner,
+			fmt.Sprintf("%t", r.Tie),
+			fmt.Sprintf("%d", r.Turns),
+		}
+		for _, name := range players {
+			row = append(row, fmt.Sprintf("%d", r.Remaining[name]))
+		}
+		row = append(row, fmt.Sprintf("%d", r.Duration.Milliseconds()), r.Error)
+
+		err = out.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// WriteCSV writes the summary as CSV, one player per row
+func (s *Summary) WriteCSV(w io.Writer) error {
+	players := []string{}
+	for name := range s.Wins {
+		players = append(players, name)
+	}
+	sort.Strings(players)
+
+	out := csv.NewWriter(w)
+	err := out.Write([]string{"player", "battles", "wins", "ties", "errors", "win_rate", "average_turns"})
+	if err != nil {
+		return err
+	}
+	for _, name := range players {
+		err = out.Write([]string{
+			name,
+			fmt.Sprintf("%d", s.Battles),
+			fmt.Sprintf("%d", s.Wins[name]),
+			fmt.Sprintf("%d", s.Ties),
+			fmt.Sprintf("%d", s.Errors),
+			fmt.Sprintf("%.4f", s.WinRate[name]),
+			fmt.Sprintf("%.2f", s.AverageTurns),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
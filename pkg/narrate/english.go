@@ -0,0 +1,231 @@
+package narrate
+
+// English are the default templates, following the wording of the
+// Showdown client.
+//
+// Keys are the event type (without a leading '-') optionally followed by
+// more specific information. We use the most specific template available
+// ie. for "|-damage|p2a: Umbreon|327/348 brn|[from] brn" we try
+// "damage.status.brn", then "damage.status" then "damage".
+//
+// Templates that are empty (or missing) mean the event isn't narrated.
+var English = map[string]string{
+	"pokemon":         "{{.Nickname}}",
+	"pokemon.mid":     "{{.Nickname}}",
+	"pokemon.foe":     "The opposing {{.Nickname}}",
+	"pokemon.foe.mid": "the opposing {{.Nickname}}",
+	"team":            "Your team",
+	"team.mid":        "your team",
+	"team.foe":        "The opposing team",
+	"team.foe.mid":    "the opposing team",
+
+	"battlestart": "Battle started between {{.Player}} and {{.Opponent}}!",
+	"turn":        "Turn {{.Amount}}",
+	"win":         "{{.Player}} won the battle!",
+	"tie":         "The battle ended in a tie!",
+	"player":      "",
+
+	"switch":            "{{.Player}} sent out {{.Nickname}}!",
+	"switch.own":        "Go! {{.Nickname}}!",
+	"drag":              "{{.Pokemon}} was dragged out!",
+	"faint":             "{{.Pokemon}} fainted!",
+	"move":              "{{.Pokemon}} used {{.Move}}!",
+	"cant":              "{{.Pokemon}} can't move!",
+	"cant.par":          "{{.Pokemon}} is paralyzed! It can't move!",
+	"cant.slp":          "{{.Pokemon}} is fast asleep.",
+	"cant.frz":          "{{.Pokemon}} is frozen solid!",
+	"cant.flinch":       "{{.Pokemon}} flinched and couldn't move!",
+	"cant.recharge":     "{{.Pokemon}} must recharge!",
+	"cant.disable":      "{{.Pokemon}}'s {{.Move}} is disabled!",
+	"cant.taunt":        "{{.Pokemon}} can't use {{.Move}} after the taunt!",
+	"cant.nopp":         "{{.Pokemon}} used {{.Move}}!\nBut there was no PP left for the move!",
+	"mustrecharge":      "{{.Pokemon}} must recharge!",
+	"prepare":           "{{.Pokemon}} is preparing {{.Move}}!",
+	"prepare.solarbeam": "{{.Pokemon}} absorbed light!",
+
+	"fail":           "But it failed!",
+	"notarget":       "But there was no target...",
+	"miss":           "{{.Target}} avoided the attack!",
+	"crit":           "A critical hit!",
+	"supereffective": "It's super effective!",
+	"resisted":       "It's not very effective...",
+	"immune":         "It doesn't affect {{.Pokemon.Mid}}...",
+	"ohko":           "It's a one-hit KO!",
+	"nothing":        "But nothing happened!",
+	"hitcount":       "The Pokémon was hit {{.Amount}} times!",
+	"hitcount.1":     "The Pokémon was hit 1 time!",
+	"block":          "{{.Pokemon}} protected itself!",
+
+	"damage":                   "({{.Pokemon}} lost {{.Amount}}% of its health!)",
+	"damage.status.brn":        "{{.Pokemon}} was hurt by its burn!",
+	"damage.status.psn":        "{{.Pokemon}} was hurt by poison!",
+	"damage.status.tox":        "{{.Pokemon}} was hurt by poison!",
+	"damage.weather.sandstorm": "{{.Pokemon}} is buffeted by the sandstorm!",
+	"damage.weather.hail":      "{{.Pokemon}} is buffeted by the hail!",
+	"damage.other.recoil":      "{{.Pokemon}} was damaged by the recoil!",
+	"damage.other.confusion":   "{{.Pokemon}} hurt itself in its confusion!",
+	"damage.other.stealthrock": "Pointed stones dug into {{.Pokemon.Mid}}!",
+	"damage.other.spikes":      "{{.Pokemon}} was hurt by the spikes!",
+	"damage.item.lifeorb":      "{{.Pokemon}} lost some of its HP!",
+	"damage.item":              "{{.Pokemon}} was hurt by {{if .Source.Start}}{{.Source.Mid}}'s {{end}}{{.Effect}}!",
+	"damage.ability":           "{{.Pokemon}} was hurt by {{if .Source.Start}}{{.Source.Mid}}'s {{end}}{{.Effect}}!",
+	"damage.move.leechseed":    "{{.Pokemon}}'s health is sapped by Leech Seed!",
+	"heal":                     "{{.Pokemon}} had its HP restored.",
+	"heal.item":                "{{.Pokemon}} restored a little HP using its {{.Effect}}!",
+	"heal.other.drain":         "{{.Source}} had its energy drained!",
+	"sethp":                    "",
+	"sethp.move.painsplit":     "The battlers shared their pain!",
+
+	"status.brn":     "{{.Pokemon}} was burned!",
+	"status.par":     "{{.Pokemon}} is paralyzed! It may be unable to move!",
+	"status.slp":     "{{.Pokemon}} fell asleep!",
+	"status.frz":     "{{.Pokemon}} was frozen solid!",
+	"status.psn":     "{{.Pokemon}} was poisoned!",
+	"status.tox":     "{{.Pokemon}} was badly poisoned!",
+	"curestatus.brn": "{{.Pokemon}}'s burn was healed.",
+	"curestatus.par": "{{.Pokemon}} was cured of paralysis.",
+	"curestatus.slp": "{{.Pokemon}} woke up!",
+	"curestatus.frz": "{{.Pokemon}} thawed out!",
+	"curestatus.psn": "{{.Pokemon}} was cured of its poisoning.",
+	"curestatus.tox": "{{.Pokemon}} was cured of its poisoning.",
+	"cureteam":       "{{.Team}}'s status conditions were cured!",
+
+	"stat.atk":      "Attack",
+	"stat.def":      "Defense",
+	"stat.spa":      "Sp. Atk",
+	"stat.spd":      "Sp. Def",
+	"stat.spe":      "Speed",
+	"stat.accuracy": "accuracy",
+	"stat.evasion":  "evasiveness",
+
+	"boost":                   "{{.Pokemon}}'s {{.Stat}} rose drastically!",
+	"boost.1":                 "{{.Pokemon}}'s {{.Stat}} rose!",
+	"boost.2":                 "{{.Pokemon}}'s {{.Stat}} rose sharply!",
+	"boost.0":                 "{{.Pokemon}}'s {{.Stat}} won't go any higher!",
+	"unboost":                 "{{.Pokemon}}'s {{.Stat}} severely fell!",
+	"unboost.1":               "{{.Pokemon}}'s {{.Stat}} fell!",
+	"unboost.2":               "{{.Pokemon}}'s {{.Stat}} harshly fell!",
+	"unboost.0":               "{{.Pokemon}}'s {{.Stat}} won't go any lower!",
+	"setboost":                "{{.Pokemon}}'s {{.Stat}} was set to {{.Amount}}!",
+	"setboost.move.bellydrum": "{{.Pokemon}} cut its own HP and maximized its Attack!",
+	"swapboost":               "{{.Pokemon}} switched stat changes with its target!",
+	"copyboost":               "{{.Pokemon}} copied {{.Target.Mid}}'s stat changes!",
+	"invertboost":             "{{.Pokemon}}'s stat changes were inverted!",
+	"clearboost":              "{{.Pokemon}}'s stat changes were removed!",
+	"clearallboost":           "All stat changes were eliminated!",
+	"clearpositiveboost":      "{{.Pokemon}}'s stat changes were removed!",
+	"clearnegativeboost":      "{{.Pokemon}} returned its decreased stats to normal using its Z-Power!",
+
+	"weather":                   "The weather became {{.Effect}}.",
+	"weather.raindance":         "It started to rain!",
+	"weather.sunnyday":          "The sunlight turned harsh!",
+	"weather.sandstorm":         "A sandstorm kicked up!",
+	"weather.hail":              "It started to hail!",
+	"weather.snow":              "It started to snow!",
+	"weather.primordialsea":     "A heavy rain began to fall!",
+	"weather.desolateland":      "The sunlight turned extremely harsh!",
+	"weather.deltastream":       "Mysterious strong winds are protecting Flying-type Pokémon!",
+	"weather.upkeep.raindance":  "Rain continues to fall.",
+	"weather.upkeep.sunnyday":   "The sunlight is strong.",
+	"weather.upkeep.sandstorm":  "The sandstorm is raging.",
+	"weather.upkeep.hail":       "The hail is crashing down.",
+	"weather.upkeep.snow":       "The snow is falling.",
+	"weather.upkeep":            "",
+	"weather.end":               "The weather cleared up.",
+	"weather.end.raindance":     "The rain stopped.",
+	"weather.end.sunnyday":      "The harsh sunlight faded.",
+	"weather.end.sandstorm":     "The sandstorm subsided.",
+	"weather.end.hail":          "The hail stopped.",
+	"weather.end.snow":          "The snow stopped.",
+	"weather.end.primordialsea": "The heavy rain has lifted!",
+	"weather.end.desolateland":  "The extremely harsh sunlight faded!",
+	"weather.end.deltastream":   "The mysterious strong winds have dissipated!",
+
+	"fieldstart":                 "{{.Effect}} started!",
+	"fieldstart.electricterrain": "An electric current ran across the battlefield!",
+	"fieldstart.grassyterrain":   "Grass grew to cover the battlefield!",
+	"fieldstart.mistyterrain":    "Mist swirled around the battlefield!",
+	"fieldstart.psychicterrain":  "The battlefield got weird!",
+	"fieldstart.trickroom":       "{{.Source}} twisted the dimensions!",
+	"fieldend":                   "{{.Effect}} ended!",
+	"fieldend.electricterrain":   "The electricity disappeared from the battlefield.",
+	"fieldend.grassyterrain":     "The grass disappeared from the battlefield.",
+	"fieldend.mistyterrain":      "The mist disappeared from the battlefield.",
+	"fieldend.psychicterrain":    "The weirdness disappeared from the battlefield!",
+	"fieldend.trickroom":         "The twisted dimensions returned to normal!",
+
+	"sidestart":             "{{.Effect}} started on {{.Team.Mid}}!",
+	"sidestart.stealthrock": "Pointed stones float in the air around {{.Team.Mid}}!",
+	"sidestart.spikes":      "Spikes were scattered on the ground all around {{.Team.Mid}}!",
+	"sidestart.toxicspikes": "Poison spikes were scattered on the ground all around {{.Team.Mid}}!",
+	"sidestart.stickyweb":   "A sticky web has been laid out on the ground around {{.Team.Mid}}!",
+	"sidestart.reflect":     "Reflect made {{.Team.Mid}} stronger against physical moves!",
+	"sidestart.lightscreen": "Light Screen made {{.Team.Mid}} stronger against special moves!",
+	"sidestart.auroraveil":  "Aurora Veil made {{.Team.Mid}} stronger against physical and special moves!",
+	"sidestart.tailwind":    "The Tailwind blew from behind {{.Team.Mid}}!",
+	"sideend":               "{{.Team}}'s {{.Effect}} wore off!",
+	"sideend.stealthrock":   "The pointed stones disappeared from around {{.Team.Mid}}!",
+	"sideend.spikes":        "The spikes disappeared from the ground around {{.Team.Mid}}!",
+	"sideend.toxicspikes":   "The poison spikes disappeared from the ground around {{.Team.Mid}}!",
+	"sideend.stickyweb":     "The sticky web has disappeared from the ground around {{.Team.Mid}}!",
+	"sideend.tailwind":      "{{.Team}}'s Tailwind petered out!",
+
+	"start":                  "",
+	"start.confusion":        "{{.Pokemon}} became confused!",
+	"start.substitute":       "{{.Pokemon}} put in a substitute!",
+	"start.taunt":            "{{.Pokemon}} fell for the taunt!",
+	"start.leechseed":        "{{.Pokemon}} was seeded!",
+	"start.encore":           "{{.Pokemon}} must do an encore!",
+	"start.disable":          "{{.Pokemon}}'s {{.Move}} was disabled!",
+	"start.yawn":             "{{.Pokemon}} grew drowsy!",
+	"start.perish3":          "{{.Pokemon}}'s perish count fell to 3.",
+	"start.perish2":          "{{.Pokemon}}'s perish count fell to 2.",
+	"start.perish1":          "{{.Pokemon}}'s perish count fell to 1.",
+	"start.perish0":          "{{.Pokemon}}'s perish count fell to 0.",
+	"start.dynamax":          "",
+	"end":                    "",
+	"end.confusion":          "{{.Pokemon}} snapped out of its confusion!",
+	"end.substitute":         "{{.Pokemon}}'s substitute faded!",
+	"end.taunt":              "{{.Pokemon}} shook off the taunt!",
+	"end.encore":             "{{.Pokemon}}'s encore ended!",
+	"end.disable":            "{{.Pokemon}}'s move is no longer disabled!",
+	"end.illusion":           "{{.Pokemon}}'s illusion wore off!",
+	"singleturn":             "",
+	"singleturn.protect":     "{{.Pokemon}} protected itself!",
+	"singleturn.endure":      "{{.Pokemon}} braced itself!",
+	"singlemove":             "",
+	"singlemove.destinybond": "{{.Pokemon}} is hoping to take its attacker down with it!",
+	"activate":               "",
+	"activate.protect":       "{{.Pokemon}} protected itself!",
+	"activate.substitute":    "The substitute took damage for {{.Pokemon.Mid}}!",
+
+	"item":                  "{{.Pokemon}} obtained one {{.Item}}.",
+	"item.ability.frisk":    "{{.Source}} frisked {{.Pokemon.Mid}} and found its {{.Item}}!",
+	"item.airballoon":       "{{.Pokemon}} floats in the air with its Air Balloon!",
+	"enditem":               "{{.Pokemon}} lost its {{.Item}}!",
+	"enditem.eat":           "{{.Pokemon}} ate its {{.Item}}!",
+	"enditem.move.knockoff": "{{.Source}} knocked off {{.Pokemon.Mid}}'s {{.Item}}!",
+	"enditem.airballoon":    "{{.Pokemon}}'s Air Balloon popped!",
+	"ability":               "[{{.Pokemon}}'s {{.Ability}}]",
+	"endability":            "({{.Pokemon}}'s Ability was suppressed!)",
+	"transform":             "{{.Pokemon}} transformed into {{.Species}}!",
+	"formechange":           "{{.Pokemon}} transformed!",
+	"detailschange":         "",
+	"mega":                  "{{.Pokemon}} has Mega Evolved into Mega {{.Species}}!",
+	"primal":                "{{.Pokemon}}'s Primal Reversion! It reverted to its primal form!",
+	"burst":                 "Bright light is about to burst out of {{.Pokemon.Mid}}!",
+	"zpower":                "{{.Pokemon}} surrounds itself with its Z-Power!",
+	"zbroken":               "{{.Pokemon}} couldn't fully protect itself and got hurt!",
+	"terastallize":          "{{.Pokemon}} has Terastallized into the {{.Effect}}-type!",
+
+	"message":     "{{.Text}}",
+	"hint":        "({{.Text}})",
+	"inactive":    "{{.Text}}",
+	"inactiveoff": "{{.Text}}",
+	"bigerror":    "{{.Text}}",
+	"chat":        "{{.Player}}: {{.Text}}",
+	"c":           "{{.Player}}: {{.Text}}",
+	"c:":          "{{.Player}}: {{.Text}}",
+	"j":           "{{.Player}} joined.",
+	"l":           "{{.Player}} left.",
+}
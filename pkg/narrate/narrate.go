@@ -0,0 +1,400 @@
+package narrate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+// Name is how a pokemon (or team) is referred to, which depends on who is
+// reading. Start is used at the beginning of a sentence, Mid elsewhere.
+// Ie. "The opposing Umbreon" vs "the opposing Umbreon"
+type Name struct {
+	Start string
+	Mid   string
+}
+
+// String returns the name as used at the start of a sentence
+func (n Name) String() string {
+	return n.Start
+}
+
+// Data is what we hand to templates. Not all fields are set for all events.
+type Data struct {
+	Pokemon Name
+	Target  Name
+	Team    Name
+
+	// Source is the pokemon responsible for the event (ie. given by [of])
+	Source Name
+
+	// Nickname of the pokemon (without "The opposing")
+	Nickname string
+
+	// Player & Opponent are usernames (where known)
+	Player   string
+	Opponent string
+
+	Move    string
+	Item    string
+	Ability string
+	Stat    string
+	Species string
+
+	// Effect is the name of whatever caused or is the subject of the event
+	// ie. a weather, side condition, volatile status or [from] effect
+	Effect string
+
+	Text   string
+	Amount int
+
+	Event *event.Event
+}
+
+// Option is some option for New
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Perspective string
+	Templates   map[string]string
+}
+
+func buildOpts(in []Option) *opts {
+	cfg := &opts{Perspective: "p1", Templates: map[string]string{}}
+	for k, v := range English {
+		cfg.Templates[k] = v
+	}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Perspective sets which player we're narrating for. Their pokemon are
+// referred to by name, everyone else's are "the opposing" pokemon.
+// Defaults to p1 (as the Showdown client does for spectators).
+func Perspective(player string) Option {
+	return func(o *opts) {
+		o.Perspective = player
+	}
+}
+
+// Templates sets templates (see English) to use over the defaults.
+func Templates(in map[string]string) Option {
+	return func(o *opts) {
+		for k, v := range in {
+			o.Templates[k] = v
+		}
+	}
+}
+
+// Narrator turns events into battle text
+type Narrator struct {
+	perspective string
+	tmpl        *template.Template
+
+	// players maps player IDs (p1) to usernames
+	players map[string]string
+
+	// hp is the last known HP (percent) of pokemon by ident
+	hp map[string]int
+
+	// weather currently in effect (stripped)
+	weather string
+}
+
+// New returns a new Narrator
+func New(in ...Option) (*Narrator, error) {
+	cfg := buildOpts(in)
+
+	root := template.New("")
+	for k, v := range cfg.Templates {
+		_, err := root.New(k).Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w failed to parse template %s", err, k)
+		}
+	}
+
+	return &Narrator{
+		perspective: cfg.Perspective,
+		tmpl:        root,
+		players:     map[string]string{},
+		hp:          map[string]int{},
+	}, nil
+}
+
+// Narrate returns the battle text for the given event.
+// Some events are not narrated, in which case we return "".
+func (n *Narrator) Narrate(e *event.Event) string {
+	if e == nil || e.Flags.Silent {
+		return ""
+	}
+
+	keys, d := n.describe(e)
+	return n.render(keys, d)
+}
+
+// render executes the first of the given templates that exists
+func (n *Narrator) render(keys []string, d *Data) string {
+	for _, k := range keys {
+		t := n.tmpl.Lookup(k)
+		if t == nil {
+			continue
+		}
+
+		buf := &bytes.Buffer{}
+		err := t.Execute(buf, d)
+		if err != nil {
+			return ""
+		}
+		return buf.String()
+	}
+	return ""
+}
+
+// name returns how we should refer to the given pokemon
+func (n *Narrator) name(s *event.Subject) Name {
+	if s == nil {
+		return Name{}
+	}
+
+	key := "pokemon"
+	if s.Player != n.perspective {
+		key = "pokemon.foe"
+	}
+
+	d := &Data{Nickname: s.Nickname}
+	return Name{Start: n.render([]string{key}, d), Mid: n.render([]string{key + ".mid"}, d)}
+}
+
+// team returns how we should refer to the given player's team
+func (n *Narrator) team(player string) Name {
+	key := "team"
+	if player != n.perspective {
+		key = "team.foe"
+	}
+	return Name{Start: n.render([]string{key}, &Data{}), Mid: n.render([]string{key + ".mid"}, &Data{})}
+}
+
+// username returns the player's username, if we know it
+func (n *Narrator) username(player string) string {
+	name, ok := n.players[player]
+	if ok && name != "" {
+		return name
+	}
+	return player
+}
+
+// setHP records a pokemon's HP & returns the percentage lost
+func (n *Narrator) setHP(s *event.Subject, hp, max int) int {
+	now := 0
+	if max > 0 && hp > 0 {
+		now = int(float64(hp)/float64(max)*100 + 0.5)
+	}
+
+	prev, ok := n.hp[s.Ident()]
+	if !ok {
+		prev = 100
+	}
+	n.hp[s.Ident()] = now
+
+	return prev - now
+}
+
+// describe returns the template keys (most specific first) and data for
+// the given event.
+func (n *Narrator) describe(e *event.Event) ([]string, *Data) {
+	base := strings.TrimPrefix(e.Type, "-")
+
+	d := &Data{
+		Pokemon: n.name(e.Subject),
+		Event:   e,
+	}
+	if e.Subject != nil {
+		d.Nickname = e.Subject.Nickname
+		d.Player = n.username(e.Subject.Player)
+		d.Team = n.team(e.Subject.Player)
+	}
+	if len(e.Targets) > 0 {
+		d.Target = n.name(e.Targets[0])
+	}
+	if e.Cause != nil {
+		d.Effect = e.Cause.Name
+		d.Source = n.name(e.Cause.Source)
+	}
+
+	switch p := e.Payload.(type) {
+	case *event.PlayerEvent:
+		n.players[p.Player] = p.Username
+		return []string{base}, d
+	case *event.InfoEvent:
+		if e.Type == event.BattleStart {
+			d.Player = n.username("p1")
+			d.Opponent = n.username("p2")
+			return []string{"battlestart"}, d
+		}
+		return []string{base}, d
+	case *event.SwitchEvent:
+		n.setHP(p.Pokemon, p.HP, p.MaxHP)
+		if p.Forced {
+			return []string{base}, d
+		}
+		if p.Pokemon.Player == n.perspective {
+			return []string{"switch.own", "switch"}, d
+		}
+		return []string{"switch"}, d
+	case *event.MoveEvent:
+		d.Move = p.Move
+		return []string{base}, d
+	case *event.CantEvent:
+		d.Move = p.Move
+		return []string{base + "." + strip(p.Reason), base}, d
+	case *event.DamageEvent:
+		if p.Target == nil {
+			return nil, d
+		}
+		d.Amount = n.setHP(p.Target, p.HP, p.MaxHP)
+		return withCause(base, e.Cause), d
+	case *event.HealEvent:
+		if p.Target == nil {
+			return nil, d
+		}
+		d.Amount = -n.setHP(p.Target, p.HP, p.MaxHP)
+		return withCause(base, e.Cause), d
+	case *event.StatusEvent:
+		return []string{base + "." + strip(p.Status), base}, d
+	case *event.BoostEvent:
+		d.Stat = n.render([]string{"stat." + p.Stat}, d)
+		if d.Stat == "" {
+			d.Stat = p.Stat
+		}
+		if p.Set {
+			d.Amount = p.Stages
+			return withCause(base, e.Cause), d
+		}
+		d.Amount = p.Stages
+		if d.Amount < 0 {
+			d.Amount = -d.Amount
+		}
+		if d.Amount < 3 {
+			return []string{fmt.Sprintf("%s.%d", base, d.Amount), base}, d
+		}
+		return []string{base}, d
+	case *event.SwapBoostEvent:
+		d.Pokemon = n.name(p.Source)
+		d.Target = n.name(p.Target)
+		return []string{base}, d
+	case *event.CopyBoostEvent:
+		d.Pokemon = n.name(p.Source)
+		d.Target = n.name(p.Target)
+		return []string{base}, d
+	case *event.TurnEvent:
+		d.Amount = p.Number
+		return []string{base}, d
+	case *event.WinEvent:
+		d.Player = p.Player
+		return []string{base}, d
+	case *event.WeatherEvent:
+		d.Effect = p.Weather
+		w := strip(p.Weather)
+		prev := n.weather
+		switch {
+		case w == "none":
+			n.weather = ""
+			return []string{"weather.end." + prev, "weather.end"}, d
+		case p.Upkeep:
+			return []string{"weather.upkeep." + w, "weather.upkeep"}, d
+		}
+		n.weather = w
+		return []string{base + "." + w, base}, d
+	case *event.FieldEvent:
+		d.Effect = effectName(p.Condition)
+		return []string{base + "." + strip(d.Effect), base}, d
+	case *event.SideConditionEvent:
+		d.Effect = effectName(p.Condition)
+		d.Team = n.team(p.Side)
+		return []string{base + "." + strip(d.Effect), base}, d
+	case *event.VolatileEvent:
+		d.Effect = effectName(p.Effect)
+		if len(p.Detail) > 0 {
+			d.Move = p.Detail[0]
+		}
+		return []string{base + "." + strip(d.Effect), base}, d
+	case *event.SingleEvent:
+		d.Effect = effectName(p.Effect)
+		return []string{base + "." + strip(d.Effect), base}, d
+	case *event.ActivateEvent:
+		d.Effect = effectName(p.Effect)
+		return []string{base + "." + strip(d.Effect), base}, d
+	case *event.ItemEvent:
+		d.Item = p.Item
+		keys := []string{base + "." + strip(p.Item)}
+		if e.Flags.Eat {
+			keys = append(keys, base+".eat")
+		}
+		return append(keys, withCause(base, e.Cause)...), d
+	case *event.AbilityEvent:
+		d.Ability = p.Ability
+		return withCause(base, e.Cause), d
+	case *event.TransformEvent:
+		if p.Into != nil {
+			d.Species = p.Into.Nickname
+		}
+		return []string{base}, d
+	case *event.FormeEvent:
+		d.Species = p.Species
+		if d.Species == "" {
+			d.Species = d.Nickname
+		}
+		d.Item = p.Item
+		return []string{base}, d
+	case *event.PrepareEvent:
+		d.Move = p.Move
+		d.Target = n.name(p.Target)
+		return []string{base + "." + strip(p.Move), base}, d
+	case *event.HitCountEvent:
+		d.Amount = p.Hits
+		return []string{fmt.Sprintf("%s.%d", base, p.Hits), base}, d
+	case *event.TerastallizeEvent:
+		d.Effect = p.Type
+		return []string{base}, d
+	case *event.MessageEvent:
+		d.Text = p.Text
+		return []string{base}, d
+	case *event.ChatEvent:
+		d.Player = p.User
+		d.Text = p.Text
+		return []string{base}, d
+	case *event.MoveResultEvent, *event.ClearBoostEvent, *event.InvertBoostEvent, *event.FaintEvent, *event.CureTeamEvent:
+		return []string{base}, d
+	}
+
+	return nil, d
+}
+
+// withCause returns keys for an event with the given cause, most
+// specific first ie. damage.status.brn, damage.status, damage
+func withCause(base string, c *event.Cause) []string {
+	if c == nil {
+		return []string{base}
+	}
+	kind := base + "." + string(c.Kind)
+	return []string{kind + "." + strip(c.Name), kind, base}
+}
+
+// effectName returns the name of an effect without it's kind
+// ie. "move: Stealth Rock" -> "Stealth Rock"
+func effectName(in string) string {
+	bits := strings.SplitN(in, ":", 2)
+	return strings.TrimSpace(bits[len(bits)-1])
+}
+
+// strip returns a name as used in template keys
+func strip(in string) string {
+	return data.Strip(in)
+}
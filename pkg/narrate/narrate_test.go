@@ -0,0 +1,95 @@
+package narrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+var dataTestNarrate = []struct {
+	In     string
+	Expect string
+}{
+	{"|switch|p1a: Lugia|Lugia, L50|100/100", "Go! Lugia!"},
+	{"|switch|p2a: Umbreon|Umbreon, L50|100/100", "Red sent out Umbreon!"},
+	{"|drag|p2a: Umbreon|Umbreon, L50|100/100", "The opposing Umbreon was dragged out!"},
+	{"|move|p1a: Lugia|Aeroblast|p2a: Umbreon", "Lugia used Aeroblast!"},
+	{"|-damage|p2a: Umbreon|60/100", "(The opposing Umbreon lost 40% of its health!)"},
+	{"|-damage|p2a: Umbreon|54/100 brn|[from] brn", "The opposing Umbreon was hurt by its burn!"},
+	{"|-damage|p2a: Umbreon|48/100|[from] ability: Rough Skin|[of] p1a: Garchomp", "The opposing Umbreon was hurt by Garchomp's Rough Skin!"},
+	{"|-heal|p2a: Umbreon|54/100|[from] item: Leftovers", "The opposing Umbreon restored a little HP using its Leftovers!"},
+	{"|-sethp|p2a: Umbreon|54/100|[silent]", ""},
+	{"|-status|p2a: Umbreon|tox", "The opposing Umbreon was badly poisoned!"},
+	{"|-curestatus|p1a: Lugia|slp|[msg]", "Lugia woke up!"},
+	{"|-boost|p1a: Lugia|spa|2", "Lugia's Sp. Atk rose sharply!"},
+	{"|-unboost|p2a: Umbreon|atk|1", "The opposing Umbreon's Attack fell!"},
+	{"|-unboost|p2a: Umbreon|atk|3", "The opposing Umbreon's Attack severely fell!"},
+	{"|-setboost|p1a: Azumarill|atk|6|[from] move: Belly Drum", "Azumarill cut its own HP and maximized its Attack!"},
+	{"|-crit|p2a: Umbreon", "A critical hit!"},
+	{"|-supereffective|p2a: Umbreon", "It's super effective!"},
+	{"|-immune|p2a: Gengar", "It doesn't affect the opposing Gengar..."},
+	{"|-miss|p1a: Lugia|p2a: Umbreon", "The opposing Umbreon avoided the attack!"},
+	{"|cant|p1a: Lugia|par", "Lugia is paralyzed! It can't move!"},
+	{"|cant|p1a: Lugia|Disable|Aeroblast", "Lugia's Aeroblast is disabled!"},
+	{"|faint|p2a: Umbreon", "The opposing Umbreon fainted!"},
+	{"|-sidestart|p2: Red|move: Stealth Rock", "Pointed stones float in the air around the opposing team!"},
+	{"|-sideend|p1: Blue|Reflect", "Your team's Reflect wore off!"},
+	{"|-fieldstart|move: Electric Terrain", "An electric current ran across the battlefield!"},
+	{"|-start|p2a: Umbreon|confusion", "The opposing Umbreon became confused!"},
+	{"|-start|p2a: Cinccino|Disable|Rock Blast|[from] ability: Cursed Body|[of] p1b: Froslass", "The opposing Cinccino's Rock Blast was disabled!"},
+	{"|-enditem|p1a: Lugia|Sitrus Berry|[eat]", "Lugia ate its Sitrus Berry!"},
+	{"|-enditem|p2a: Umbreon|Leftovers|[from] move: Knock Off|[of] p1a: Lugia", "Lugia knocked off the opposing Umbreon's Leftovers!"},
+	{"|-ability|p2a: Gyarados|Intimidate|boost", "[The opposing Gyarados's Intimidate]"},
+	{"|-hitcount|p2a: Umbreon|1", "The Pokémon was hit 1 time!"},
+	{"|-hitcount|p2a: Umbreon|3", "The Pokémon was hit 3 times!"},
+	{"|turn|4", "Turn 4"},
+	{"|win|Red", "Red won the battle!"},
+	{"|upkeep", ""},
+	{"|-message|Red forfeited.", "Red forfeited."},
+}
+
+func TestNarrate(t *testing.T) {
+	n, err := New()
+	assert.Nil(t, err)
+
+	n.Narrate(event.Parse("|player|p2|Red|60|"))
+
+	for _, tt := range dataTestNarrate {
+		t.Run(tt.In, func(t *testing.T) {
+			result := n.Narrate(event.Parse(tt.In))
+
+			assert.Equal(t, tt.Expect, result)
+		})
+	}
+}
+
+func TestNarrateWeather(t *testing.T) {
+	n, _ := New()
+
+	assert.Equal(t, "It started to rain!", n.Narrate(event.Parse("|-weather|RainDance")))
+	assert.Equal(t, "Rain continues to fall.", n.Narrate(event.Parse("|-weather|RainDance|[upkeep]")))
+	assert.Equal(t, "The rain stopped.", n.Narrate(event.Parse("|-weather|none")))
+}
+
+func TestNarratePerspective(t *testing.T) {
+	n, _ := New(Perspective("p2"))
+
+	assert.Equal(t, "The opposing Lugia used Aeroblast!", n.Narrate(event.Parse("|move|p1a: Lugia|Aeroblast|p2a: Umbreon")))
+	assert.Equal(t, "Go! Umbreon!", n.Narrate(event.Parse("|switch|p2a: Umbreon|Umbreon, L50|100/100")))
+}
+
+func TestNarrateTemplates(t *testing.T) {
+	n, err := New(Templates(map[string]string{
+		"pokemon.foe": "{{.Nickname}} adverse",
+		"move":        "{{.Pokemon}} utilise {{.Move}} !",
+		"crit":        "",
+	}))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Lugia adverse utilise Aeroblast !", n.Narrate(event.Parse("|move|p2a: Lugia|Aeroblast|p1a: Umbreon")))
+	assert.Equal(t, "", n.Narrate(event.Parse("|-crit|p2a: Umbreon")))
+
+	_, err = New(Templates(map[string]string{"move": "{{.Pokemon"}))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,127 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// MaxPower is a player that uses whichever move hits hardest, judged only by
+// the move (power, STAB & accuracy) since requests don't tell us about the
+// foe. It switches only when it must, to it's healthiest pokemon.
+// Ties are broken at random.
+type MaxPower struct {
+	lock sync.Mutex
+	rng  *rand.Rand
+}
+
+// NewMaxPower returns a max power player using the given seed
+func NewMaxPower(seed int64) *MaxPower {
+	return &MaxPower{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Choose returns the legal action with the highest score for the given side
+func (m *MaxPower) Choose(side *sim.Side) (*sim.Action, error) {
+	doubles := len(side.Field) > 1
+	mask := encode.Mask(side, doubles)
+
+	best := []*sim.Action{}
+	bestScore := 0.0
+	for i, ok := range mask {
+		if ok <= 0 {
+			continue
+		}
+		act, err := encode.DecodeAction(side, i, doubles)
+		if err != nil {
+			return nil, err
+		}
+
+		score := 0.0
+		for slot, spec := range act.Specs {
+			score += scoreSpec(side, slot, spec)
+		}
+
+		if len(best) == 0 || score > bestScore {
+			best = []*sim.Action{act}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, act)
+		}
+	}
+	if len(best) == 0 {
+		return nil, fmt.Errorf("%w no legal actions for %s", encode.ErrInvalidAction, side.Player)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return best[m.rng.Intn(len(best))], nil
+}
+
+// scoreSpec returns how much we like the given action for a slot
+func scoreSpec(side *sim.Side, slot int, spec *sim.ActionSpec) float64 {
+	switch spec.Type {
+	case sim.ActionPass:
+		return 0
+	case sim.ActionSwitch:
+		if !side.Field[slot].Switch {
+			// we'd rather do anything else
+			return -1
+		}
+		var idx int
+		fmt.Sscanf(spec.ID, "%d", &idx)
+		if idx < 1 || idx > len(side.Pokemon) {
+			return -1
+		}
+		return health(side.Pokemon[idx-1])
+	}
+
+	opts := side.Field[slot].Options
+	if opts == nil {
+		return 0
+	}
+	var move *sim.Move
+	for _, mv := range opts.Moves {
+		if mv != nil && mv.ID == spec.ID {
+			move = mv
+		}
+	}
+	if move == nil || move.Power <= 0 {
+		// status moves are better than nothing
+		return 0.1
+	}
+
+	score := float64(move.Power)
+	if slot < len(side.Pokemon) && side.Pokemon[slot].Dex != nil {
+		for _, t := range side.Pokemon[slot].Dex.Types {
+			if t == move.Type {
+				score *= 1.5
+			}
+		}
+	}
+	if move.Accuracy > 0 && move.Accuracy <= 100 {
+		score *= float64(move.Accuracy) / 100
+	}
+	if spec.Target < 0 {
+		// negative targets are on our side of the field
+		score = -score
+	}
+	if spec.ZMove || spec.Max {
+		// these are one use; save them for when they're the only option
+		score *= 0.9
+	}
+	if spec.Mega {
+		score += 1
+	}
+	return score
+}
+
+// health returns the pokemon's HP as a fraction of it's max HP
+func health(p *sim.Pokemon) float64 {
+	if p.Status == nil || p.Status.HPMax <= 0 || p.Status.IsFainted {
+		return 0
+	}
+	return float64(p.Status.HPNow) / float64(p.Status.HPMax)
+}
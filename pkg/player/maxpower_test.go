@@ -0,0 +1,74 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// testMoves returns inflated moves by ID
+func testMoves(t *testing.T, ids ...string) []*sim.Move {
+	moves := []*sim.Move{}
+	for _, id := range ids {
+		m, err := sim.NewMove(id)
+		assert.Nil(t, err)
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+func TestMaxPowerPrefersStab(t *testing.T) {
+	dex, err := data.Species("Charizard")
+	assert.Nil(t, err)
+
+	side := &sim.Side{
+		Player: "p1",
+		Field: []*sim.Slot{&sim.Slot{
+			ID:      "p1a",
+			Options: &sim.Options{Moves: testMoves(t, "swordsdance", "bodyslam", "flamethrower", "focusblast")},
+		}},
+		Pokemon: []*sim.Pokemon{
+			&sim.Pokemon{Active: true, Dex: dex, Status: &structs.Status{HPNow: 1, HPMax: 1}},
+			&sim.Pokemon{Status: &structs.Status{HPNow: 1, HPMax: 1}},
+		},
+	}
+
+	// flamethrower 90 * 1.5 beats body slam 85 & focus blast 120 * 0.7
+	act, err := NewMaxPower(0).Choose(side)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "flamethrower", act.Specs[0].ID)
+}
+
+func TestMaxPowerSwitchesToHealthiest(t *testing.T) {
+	side := &sim.Side{
+		Player: "p1",
+		Field:  []*sim.Slot{&sim.Slot{ID: "p1a", Switch: true}},
+		Pokemon: []*sim.Pokemon{
+			&sim.Pokemon{Active: true, Status: &structs.Status{HPMax: 100, IsFainted: true}},
+			&sim.Pokemon{Status: &structs.Status{HPNow: 20, HPMax: 100}},
+			&sim.Pokemon{Status: &structs.Status{HPNow: 90, HPMax: 100}},
+			&sim.Pokemon{Status: &structs.Status{HPNow: 50, HPMax: 100}},
+		},
+	}
+
+	act, err := NewMaxPower(0).Choose(side)
+
+	assert.Nil(t, err)
+	assert.Equal(t, sim.ActionSwitch, act.Specs[0].Type)
+	assert.Equal(t, "3", act.Specs[0].ID)
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range Names() {
+		p, err := ByName(name, 0)
+		assert.Nil(t, err)
+		assert.NotNil(t, p)
+	}
+
+	_, err := ByName("deepblue", 0)
+	assert.ErrorIs(t, err, ErrUnknownPlayer)
+}
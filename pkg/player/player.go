@@ -0,0 +1,57 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Player decides what to do given a request from the simulator
+type Player interface {
+	// Choose returns an action for the given side
+	Choose(*sim.Side) (*sim.Action, error)
+}
+
+// Func is a decision function that can be used as a Player
+type Func func(*sim.Side) (*sim.Action, error)
+
+// Choose calls the function
+func (f Func) Choose(side *sim.Side) (*sim.Action, error) {
+	return f(side)
+}
+
+// Random is a player that picks uniformly from legal actions
+type Random struct {
+	lock sync.Mutex
+	rng  *rand.Rand
+}
+
+// NewRandom returns a random player using the given seed
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Choose returns a random legal action for the given side
+func (r *Random) Choose(side *sim.Side) (*sim.Action, error) {
+	doubles := len(side.Field) > 1
+	mask := encode.Mask(side, doubles)
+
+	legal := []int{}
+	for i, ok := range mask {
+		if ok > 0 {
+			legal = append(legal, i)
+		}
+	}
+	if len(legal) == 0 {
+		return nil, fmt.Errorf("%w no legal actions for %s", encode.ErrInvalidAction, side.Player)
+	}
+
+	r.lock.Lock()
+	idx := legal[r.rng.Intn(len(legal))]
+	r.lock.Unlock()
+
+	return encode.DecodeAction(side, idx, doubles)
+}
@@ -0,0 +1,49 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/encode"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+func TestRandomChoosesLegal(t *testing.T) {
+	side := &sim.Side{
+		Player: "p1",
+		Field: []*sim.Slot{&sim.Slot{
+			ID:      "p1a",
+			Trapped: true,
+			Options: &sim.Options{Moves: []*sim.Move{
+				&sim.Move{ID: "tackle", Disabled: true},
+				&sim.Move{ID: "growl"},
+			}},
+		}},
+		Pokemon: []*sim.Pokemon{
+			&sim.Pokemon{Active: true, Status: &structs.Status{HPNow: 1, HPMax: 1}},
+			&sim.Pokemon{Status: &structs.Status{HPNow: 1, HPMax: 1}},
+		},
+	}
+
+	p := NewRandom(0)
+	for i := 0; i < 20; i++ {
+		act, err := p.Choose(side)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "p1", act.Player)
+		assert.Equal(t, "growl", act.Specs[0].ID)
+	}
+}
+
+func TestRandomNoLegalActions(t *testing.T) {
+	side := &sim.Side{
+		Player:  "p1",
+		Field:   []*sim.Slot{&sim.Slot{ID: "p1a", Trapped: true, Options: &sim.Options{}}},
+		Pokemon: []*sim.Pokemon{&sim.Pokemon{Active: true}},
+	}
+
+	_, err := NewRandom(0).Choose(side)
+
+	assert.ErrorIs(t, err, encode.ErrInvalidAction)
+}
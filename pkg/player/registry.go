@@ -0,0 +1,37 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrUnknownPlayer is returned if asked for a player we don't have
+	ErrUnknownPlayer = errors.New("unknown player")
+
+	// builtin players by name
+	builtin = map[string]func(seed int64) Player{
+		"random":   func(seed int64) Player { return NewRandom(seed) },
+		"maxpower": func(seed int64) Player { return NewMaxPower(seed) },
+	}
+)
+
+// Names returns the names of the builtin players
+func Names() []string {
+	names := []string{}
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByName returns a new builtin player by name ie. "random", "maxpower"
+func ByName(name string, seed int64) (Player, error) {
+	fn, ok := builtin[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %s, expected one of %v", ErrUnknownPlayer, name, Names())
+	}
+	return fn(seed), nil
+}
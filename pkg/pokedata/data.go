@@ -3,6 +3,7 @@ package pokedata
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 )
@@ -12,9 +13,16 @@ var (
 	parsedPokedex map[string]*PokeDexItem
 	parsedMovedex map[string]*MoveDexItem
 
+	// nil if assets.go wasn't generated with learnsets.json
+	parsedLearnsets map[string]*LearnsetItem
+
 	// ErrNotFound implies we didn't find a match for some ID
 	ErrNotFound = fmt.Errorf("not found")
 
+	// ErrNoLearnsets is returned by Learnset if assets.go wasn't
+	// generated with learnsets.json (see regenerate-data.sh)
+	ErrNoLearnsets = fmt.Errorf("no learnsets embedded, run regenerate-data.sh")
+
 	// remove all non alpha numeric
 	invalid = regexp.MustCompile("[^a-zA-Z0-9]+")
 )
@@ -31,6 +39,7 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	aliases = buildAliases(parsedPokedex)
 
 	raw, err = Asset("moves.json")
 	if err != nil {
@@ -41,6 +50,17 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	raw, err = Asset("learnsets.json")
+	if err != nil {
+		log.Println(ErrNoLearnsets)
+		return
+	}
+
+	err = json.Unmarshal(raw, &parsedLearnsets)
+	if err != nil {
+		panic(err)
+	}
 }
 
 // Strip removes non alpha-num chars and switches to lowercase
@@ -0,0 +1,204 @@
+package pokedata
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// aliases maps alternate (stripped) names to pokedex IDs.
+	// Built from pokedex data in init()
+	aliases map[string]string
+
+	// ErrFormeRequirement implies a pokemon is a forme that requires some
+	// item, ability or move that it doesn't have
+	ErrFormeRequirement = fmt.Errorf("forme requirement not met")
+)
+
+// buildAliases works out alternate names for pokedex entries that
+// pokemon-showdown understands but that are not keys in the pokedex itself.
+// - cosmetic formes (Gastrodon-East -> Gastrodon)
+// - named base formes (Lycanroc-Midday -> Lycanroc)
+// - "Mega X" & "Primal X" style names (Mega Charizard Y -> Charizard-Mega-Y)
+func buildAliases(dex map[string]*PokeDexItem) map[string]string {
+	found := map[string]string{}
+	for id, p := range dex {
+		for _, cosmetic := range p.CosmeticFormes {
+			found[Strip(cosmetic)] = id
+		}
+
+		if p.BaseForme != "" {
+			found[Strip(p.Name+p.BaseForme)] = id
+		}
+
+		if p.BaseSpecies == "" {
+			continue
+		}
+
+		for _, prefix := range []string{"Mega", "Primal"} {
+			if !strings.HasPrefix(p.Forme, prefix) {
+				continue
+			}
+			// Charizard-Mega-Y -> megacharizardy
+			suffix := strings.TrimPrefix(p.Forme, prefix)
+			found[Strip(prefix+p.BaseSpecies+suffix)] = id
+		}
+	}
+
+	// an alias should never shadow a real entry
+	for id := range dex {
+		delete(found, id)
+	}
+
+	return found
+}
+
+// Species returns the canonical pokedex entry for a pokemon, resolving
+// aliases and cosmetic formes that the pokedex itself has no entry for.
+// Ie. 'Gastrodon-East' -> Gastrodon, 'Mega Charizard X' -> Charizard-Mega-X
+func Species(in string) (*PokeDexItem, error) {
+	result, err := PokeDex(in)
+	if err == nil {
+		return result, nil
+	}
+
+	id, ok := aliases[Strip(in)]
+	if !ok {
+		return nil, err
+	}
+
+	return PokeDex(id)
+}
+
+// SameSpecies returns if two names refer to formes of the same base species.
+// Ie. Charizard & Charizard-Mega-X, Gastrodon & Gastrodon-East
+func SameSpecies(a, b string) bool {
+	adex, err := Species(a)
+	if err != nil {
+		return false
+	}
+	bdex, err := Species(b)
+	if err != nil {
+		return false
+	}
+	return Strip(adex.Base()) == Strip(bdex.Base())
+}
+
+// Base returns the name of this pokemon's base species (which might be itself)
+func (p *PokeDexItem) Base() string {
+	if p.BaseSpecies == "" {
+		return p.Name
+	}
+	return p.BaseSpecies
+}
+
+// IsMega returns if this is a mega evolved forme
+func (p *PokeDexItem) IsMega() bool {
+	return p.BaseSpecies != "" && strings.HasPrefix(p.Forme, "Mega")
+}
+
+// IsPrimal returns if this is a primal reversion forme
+func (p *PokeDexItem) IsPrimal() bool {
+	return p.BaseSpecies != "" && p.Forme == "Primal"
+}
+
+// IsGigantamax returns if this is a gigantamax forme
+func (p *PokeDexItem) IsGigantamax() bool {
+	return p.BaseSpecies != "" && strings.HasSuffix(p.Forme, "Gmax")
+}
+
+// IsBattleOnly returns if this forme can only be reached during a battle
+// (ie. a pokemon cannot start a battle in this forme).
+func (p *PokeDexItem) IsBattleOnly() bool {
+	return len(p.BattleOnly()) > 0
+}
+
+// BattleOnly returns the forme(s) this pokemon must be in when entering
+// battle, if this is a battle only forme. Otherwise we return nil.
+// Nb. megas, primals & gigantamax formes are considered battle only even
+// if the data doesn't explicitly say so.
+func (p *PokeDexItem) BattleOnly() []string {
+	switch p.UnparsedBattleOnly.(type) {
+	case string:
+		return []string{p.UnparsedBattleOnly.(string)}
+	case []interface{}:
+		formes := []string{}
+		for _, f := range p.UnparsedBattleOnly.([]interface{}) {
+			name, ok := f.(string)
+			if ok {
+				formes = append(formes, name)
+			}
+		}
+		return formes
+	}
+
+	if p.IsMega() || p.IsPrimal() {
+		return []string{p.BaseSpecies}
+	}
+	if p.IsGigantamax() {
+		if p.ChangesFrom != "" {
+			return []string{p.ChangesFrom}
+		}
+		return []string{p.BaseSpecies}
+	}
+
+	return nil
+}
+
+// CheckRequirements returns an error if a pokemon in this forme holding the
+// given item with the given ability could not exist.
+// Ie. Charizard-Mega-Y must hold a Charizardite Y, Giratina-Origin must hold
+// a Griseous Orb and Aegislash-Blade must have Stance Change.
+func (p *PokeDexItem) CheckRequirements(item, ability string) error {
+	if p.RequiredItem != "" && Strip(item) != Strip(p.RequiredItem) {
+		return fmt.Errorf("%w %s requires item %s (has '%s')", ErrFormeRequirement, p.Name, p.RequiredItem, item)
+	}
+
+	if len(p.RequiredItems) > 0 {
+		found := false
+		for _, required := range p.RequiredItems {
+			if Strip(item) == Strip(required) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf(
+				"%w %s requires one of items %s (has '%s')",
+				ErrFormeRequirement, p.Name, strings.Join(p.RequiredItems, ", "), item,
+			)
+		}
+	}
+
+	if p.RequiredAbility != "" && Strip(ability) != Strip(p.RequiredAbility) {
+		return fmt.Errorf("%w %s requires ability %s (has '%s')", ErrFormeRequirement, p.Name, p.RequiredAbility, ability)
+	}
+
+	return nil
+}
+
+// MegaForme returns the forme the given species turns in to when mega
+// evolving or undergoing primal reversion holding the given item.
+// Nb. Rayquaza requires a move rather than an item, so we return it's
+// mega forme regardless of item.
+func MegaForme(species, item string) (*PokeDexItem, error) {
+	base, err := Species(species)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, forme := range base.OtherFormes {
+		dex, err := PokeDex(forme)
+		if err != nil {
+			continue
+		}
+		if !dex.IsMega() && !dex.IsPrimal() {
+			continue
+		}
+		if dex.RequiredMove != "" || Strip(dex.RequiredItem) == Strip(item) {
+			return dex, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w mega forme of '%s' with item '%s'", ErrNotFound, species, item)
+}
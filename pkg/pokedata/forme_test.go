@@ -0,0 +1,116 @@
+package pokedata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestSpecies = []struct {
+	In     string
+	Expect string
+}{
+	{"Umbreon", "Umbreon"},
+	{"Gastrodon-East", "Gastrodon"},
+	{"gastrodoneast", "Gastrodon"},
+	{"Lycanroc-Midday", "Lycanroc"},
+	{"Mega Charizard Y", "Charizard-Mega-Y"},
+	{"Primal Groudon", "Groudon-Primal"},
+	{"Charizard-Mega-X", "Charizard-Mega-X"},
+}
+
+func TestSpecies(t *testing.T) {
+	for _, tt := range dataTestSpecies {
+		t.Run(tt.In, func(t *testing.T) {
+			result, err := Species(tt.In)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expect, result.Name)
+		})
+	}
+}
+
+func TestSpeciesNotFound(t *testing.T) {
+	_, err := Species("Missingno-East")
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestSameSpecies(t *testing.T) {
+	assert.True(t, SameSpecies("Charizard", "Charizard-Mega-X"))
+	assert.True(t, SameSpecies("Gastrodon-East", "gastrodon"))
+	assert.False(t, SameSpecies("Charizard", "Umbreon"))
+}
+
+var dataTestBattleOnly = []struct {
+	In     string
+	Expect []string
+}{
+	{"Umbreon", nil},
+	{"Charizard-Mega-Y", []string{"Charizard"}},
+	{"Kyogre-Primal", []string{"Kyogre"}},
+	{"Venusaur-Gmax", []string{"Venusaur"}},
+	{"Aegislash-Blade", []string{"Aegislash"}},
+	{"Zygarde-Complete", []string{"Zygarde", "Zygarde-10%"}},
+	{"Giratina-Origin", nil},
+}
+
+func TestBattleOnly(t *testing.T) {
+	for _, tt := range dataTestBattleOnly {
+		t.Run(tt.In, func(t *testing.T) {
+			dex, err := PokeDex(tt.In)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expect, dex.BattleOnly())
+			assert.Equal(t, tt.Expect != nil, dex.IsBattleOnly())
+		})
+	}
+}
+
+var dataTestCheckRequirements = []struct {
+	Name    string
+	Species string
+	Item    string
+	Ability string
+	Err     bool
+}{
+	{"no-requirements", "Umbreon", "", "", false},
+	{"mega-stone", "Charizard-Mega-Y", "charizarditey", "drought", false},
+	{"wrong-mega-stone", "Charizard-Mega-Y", "charizarditex", "drought", true},
+	{"forme-item", "Giratina-Origin", "Griseous Orb", "levitate", false},
+	{"missing-forme-item", "Giratina-Origin", "leftovers", "levitate", true},
+	{"one-of-items", "Arceus-Fire", "firiumz", "multitype", false},
+	{"none-of-items", "Arceus-Fire", "splashplate", "multitype", true},
+	{"forme-ability", "Aegislash-Blade", "", "stancechange", false},
+	{"missing-forme-ability", "Aegislash-Blade", "", "noguard", true},
+}
+
+func TestCheckRequirements(t *testing.T) {
+	for _, tt := range dataTestCheckRequirements {
+		t.Run(tt.Name, func(t *testing.T) {
+			dex, err := PokeDex(tt.Species)
+			assert.Nil(t, err)
+
+			err = dex.CheckRequirements(tt.Item, tt.Ability)
+
+			assert.Equal(t, tt.Err, err != nil)
+			if tt.Err {
+				assert.True(t, errors.Is(err, ErrFormeRequirement))
+			}
+		})
+	}
+}
+
+func TestMegaForme(t *testing.T) {
+	x, err := MegaForme("Charizard", "charizarditex")
+	assert.Nil(t, err)
+	assert.Equal(t, "Charizard-Mega-X", x.Name)
+
+	primal, err := MegaForme("Groudon", "Red Orb")
+	assert.Nil(t, err)
+	assert.Equal(t, "Groudon-Primal", primal.Name)
+
+	_, err = MegaForme("Charizard", "leftovers")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,95 @@
+package pokedata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// learnsetGen is the generation of our data, moves must be learnable in
+// this generation to be legal
+const learnsetGen = "8"
+
+// LearnsetItem is data parsed from Showdown data files
+// https://play.pokemonshowdown.com/data/learnsets.json
+type LearnsetItem struct {
+	// Learnset maps move IDs to how they're learnt, ie. "8L1" is at level
+	// 1 in gen 8, "8M" is by TM/TR
+	Learnset map[string][]string `json:"learnset"`
+}
+
+// Learnset returns the IDs of moves a pokemon can currently learn (sorted),
+// including those it learns as a pre-evolution & those of it's base
+// species if it's a forme without a learnset of it's own (ie. megas).
+// ErrNoLearnsets is returned if we've no learnset data at all.
+func Learnset(in string) ([]string, error) {
+	if parsedLearnsets == nil {
+		return nil, ErrNoLearnsets
+	}
+
+	dex, err := Species(in)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := map[string]bool{}
+	found := false
+	seen := map[string]bool{}
+	for dex != nil && !seen[dex.Name] {
+		seen[dex.Name] = true
+
+		item, ok := parsedLearnsets[Strip(dex.Name)]
+		own := ok && item.Learnset != nil
+		if own {
+			found = true
+			for id, sources := range item.Learnset {
+				if currentGen(sources) {
+					moves[id] = true
+				}
+			}
+		}
+		dex = learnsetParent(dex, own)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w learnset for '%s'", ErrNotFound, Strip(in))
+	}
+
+	ids := []string{}
+	for id := range moves {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// learnsetParent returns the species whose moves are also learnable by
+// the given one; it's base species if it has no learnset of it's own,
+// otherwise it's pre-evolution (if any)
+func learnsetParent(dex *PokeDexItem, own bool) *PokeDexItem {
+	next := dex.PreEvolution
+	if !own {
+		next = dex.ChangesFrom
+		if next == "" {
+			next = dex.BaseSpecies
+		}
+	}
+	if next == "" {
+		return nil
+	}
+	parent, err := PokeDex(next)
+	if err != nil {
+		return nil
+	}
+	return parent
+}
+
+// currentGen returns if any of the ways a move is learnt are in the
+// generation of our data
+func currentGen(sources []string) bool {
+	for _, s := range sources {
+		if strings.HasPrefix(s, learnsetGen) {
+			return true
+		}
+	}
+	return false
+}
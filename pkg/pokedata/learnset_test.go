@@ -0,0 +1,85 @@
+package pokedata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testLearnsets is a cut down learnsets.json
+const testLearnsets = `{
+	"charmander": {"learnset": {"ember": ["8L1", "7L1"], "dragondance": ["8E"], "irontail": ["7T"]}},
+	"charmeleon": {"learnset": {"ember": ["8L1"], "slash": ["8L24"]}},
+	"charizard": {"learnset": {"ember": ["8L1"], "airslash": ["8L1"], "earthquake": ["8M"]}},
+	"charizardmegax": {}
+}`
+
+var dataTestLearnset = []struct {
+	In     string
+	Expect []string
+}{
+	{"Charmander", []string{"dragondance", "ember"}},
+	{"Charizard", []string{"airslash", "dragondance", "earthquake", "ember", "slash"}},
+	{"Mega Charizard X", []string{"airslash", "dragondance", "earthquake", "ember", "slash"}},
+	{"Charizard-Gmax", []string{"airslash", "dragondance", "earthquake", "ember", "slash"}},
+}
+
+func useLearnsets(t *testing.T, raw string) {
+	found := map[string]*LearnsetItem{}
+	err := json.Unmarshal([]byte(raw), &found)
+	assert.Nil(t, err)
+
+	was := parsedLearnsets
+	parsedLearnsets = found
+	t.Cleanup(func() { parsedLearnsets = was })
+}
+
+func TestLearnset(t *testing.T) {
+	useLearnsets(t, testLearnsets)
+
+	for _, tt := range dataTestLearnset {
+		t.Run(tt.In, func(t *testing.T) {
+			result, err := Learnset(tt.In)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expect, result)
+		})
+	}
+}
+
+func TestLearnsetNotFound(t *testing.T) {
+	useLearnsets(t, testLearnsets)
+
+	for _, in := range []string{"Umbreon", "notapokemon"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := Learnset(in)
+
+			assert.True(t, errors.Is(err, ErrNotFound))
+		})
+	}
+}
+
+func TestLearnsetEmbedded(t *testing.T) {
+	if _, err := Asset("learnsets.json"); err != nil {
+		t.Skip(ErrNoLearnsets)
+	}
+
+	result, err := Learnset("Charizard")
+
+	assert.Nil(t, err)
+	assert.Contains(t, result, "flamethrower")
+	assert.Contains(t, result, "earthquake")
+	assert.NotContains(t, result, "surf")
+}
+
+func TestLearnsetNotEmbedded(t *testing.T) {
+	was := parsedLearnsets
+	parsedLearnsets = nil
+	t.Cleanup(func() { parsedLearnsets = was })
+
+	_, err := Learnset("Charizard")
+
+	assert.True(t, errors.Is(err, ErrNoLearnsets))
+}
@@ -21,6 +21,19 @@ type MoveDexItem struct {
 	MaxPP            int                    `json:"pp"`
 	Name             string                 `json:"name"`
 	Target           string                 `json:"target"`
+	IsNonstandard    string                 `json:"isNonstandard"`
+
+	// IsZ is the Z crystal required for a Z move (if any)
+	IsZ string `json:"isZ"`
+
+	// UnparsedIsMax is either a bool or the name of a Gigantamax pokemon
+	// (see IsMax())
+	UnparsedIsMax interface{} `json:"isMax"`
+}
+
+// IsMax returns if this is a Max or G-Max move
+func (m *MoveDexItem) IsMax() bool {
+	return m.UnparsedIsMax != nil && m.UnparsedIsMax != false
 }
 
 // secondary move side effect data
@@ -32,6 +32,13 @@ type PokeDexItem struct {
 	BaseSpecies  string   `json:"baseSpecies"`
 
 	Forme         string   `json:"forme"`
+	BaseForme     string   `json:"baseForme"`
+	ChangesFrom   string   `json:"changesFrom"`
 	Gender        string   `json:"gender"`
 	RequiredItems []string `json:"requiredItems"`
+	RequiredMove  string   `json:"requiredMove"`
+
+	// UnparsedBattleOnly is either a single species name or a list of them
+	// (see BattleOnly())
+	UnparsedBattleOnly interface{} `json:"battleOnly"`
 }
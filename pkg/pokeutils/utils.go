@@ -35,6 +35,8 @@ func Binary(path string) Option {
 }
 
 // RandomTeam uses pokemon-showdown to generate a random team of pokemon.
+// See the teamgen package for a native (seedable, constrainable) generator;
+// this remains useful as the sets it returns are always legal.
 func RandomTeam(in ...Option) ([]*sim.PokemonSpec, error) {
 	cfg := buildOpts(in)
 	team, err := randomTeam(cfg.Binary)
@@ -54,6 +56,7 @@ func randomTeam(binary string) ([]*sim.PokemonSpec, error) {
 	sout, serr, errs := cmd.Run(
 		binary, []string{"unpack-team"}, stdin, cntrl, cmd.Seperator("\n"),
 	)
+	defer close(cntrl)
 	go func() {
 		stdin <- string(out) + "\n\n"
 	}()
@@ -72,6 +75,4 @@ func randomTeam(binary string) ([]*sim.PokemonSpec, error) {
 	case <-timer.C:
 		return nil, fmt.Errorf("time out unpacking team")
 	}
-
-	return nil, fmt.Errorf("failed to generate team")
 }
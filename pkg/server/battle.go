@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/subtle"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Info describes a battle hosted by the server
+type Info struct {
+	ID     string     `json:"id"`
+	Format sim.Format `json:"format"`
+	Done   bool       `json:"done"`
+}
+
+// Created is returned when a battle is made. Tokens (by player) are
+// needed to connect as a player, so each should be given only to the
+// player it's for.
+type Created struct {
+	Info
+	Tokens map[string]string `json:"tokens"`
+}
+
+// battle is a running simulator & the views players connect to
+type battle struct {
+	id     string
+	format sim.Format
+	tokens map[string]string
+	views  *sim.Views
+
+	// wlock serialises writes to the simulator
+	wlock sync.Mutex
+
+	lock sync.Mutex
+	done bool
+}
+
+func newBattle(id string, format sim.Format, stream sim.SimulatorStream, tokens map[string]string) *battle {
+	return &battle{
+		id:     id,
+		format: format,
+		tokens: tokens,
+		views:  sim.NewViews(stream),
+	}
+}
+
+// run waits for the simulator to finish
+func (b *battle) run() {
+	for range b.views.PlayerView("").Updates() {
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.done = true
+}
+
+// info returns a description of the battle
+func (b *battle) info() *Info {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return &Info{ID: b.id, Format: b.format, Done: b.done}
+}
+
+// allowed returns if the token is the given player's
+func (b *battle) allowed(player, token string) bool {
+	want, ok := b.tokens[player]
+	return ok && subtle.ConstantTimeCompare([]byte(want), []byte(token)) == 1
+}
+
+// view returns what the given player (or spectator, if player is "") can
+// see of the battle, from the start
+func (b *battle) view(player string) sim.SimulatorStream {
+	return b.views.PlayerView(player)
+}
+
+// write passes an action to the simulator through a player's view
+func (b *battle) write(view sim.SimulatorStream, act *sim.Action) error {
+	b.wlock.Lock()
+	defer b.wlock.Unlock()
+	return view.Write(act)
+}
+
+// stop ends the battle & the simulator
+func (b *battle) stop() {
+	b.views.Stop()
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// ErrRequest is returned if the server rejects a request
+	ErrRequest = errors.New("request failed")
+
+	// ErrDisconnected is given (as an Update error) if the connection to
+	// the server is lost before the battle ends
+	ErrDisconnected = errors.New("disconnected from server")
+)
+
+// Client is a connection to a battle on a Server.
+// It implements sim.SimulatorStream.
+type Client struct {
+	// Player we're connected as, "" for a spectator
+	Player string
+
+	conn  *websocket.Conn
+	wlock sync.Mutex
+	out   chan *sim.Update
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Create starts a battle on the server at addr (ie. http://localhost:8080)
+// returning the battle ID & the token for each player
+func Create(addr string, spec *sim.BattleSpec) (*Created, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post(strings.TrimRight(addr, "/")+"/battles", "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w %s: %s", ErrRequest, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	created := &Created{}
+	err = json.NewDecoder(resp.Body).Decode(created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Dial connects to a battle on the server at addr (ie. http://localhost:8080)
+// as the given player (ie. p1), using the player's token from Create
+func Dial(addr, id, player, token string) (*Client, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	return dial(fmt.Sprintf("%s/battles/%s/play/%s", wsAddr(addr), id, player), player, header)
+}
+
+// Watch connects to a battle on the server at addr (ie. http://localhost:8080)
+// as a spectator
+func Watch(addr, id string) (*Client, error) {
+	return dial(fmt.Sprintf("%s/battles/%s/watch", wsAddr(addr), id), "", nil)
+}
+
+func dial(url, player string, header http.Header) (*Client, error) {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrRequest, resp.Status, err)
+		}
+		return nil, err
+	}
+
+	c := &Client{
+		Player: player,
+		conn:   conn,
+		out:    make(chan *sim.Update),
+		done:   make(chan struct{}),
+	}
+	go c.read()
+	return c, nil
+}
+
+// Write an action to the server.
+// The server sets the player to the one we're connected as.
+func (c *Client) Write(act *sim.Action) error {
+	if c.Player == "" {
+		return ErrSpectator
+	}
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	return c.conn.WriteJSON(act)
+}
+
+// Updates returns updates from the server, the chan is closed when
+// the battle is over (or the client is stopped)
+func (c *Client) Updates() <-chan *sim.Update {
+	return c.out
+}
+
+// Stop closes our connection, the battle carries on without us
+func (c *Client) Stop() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// read passes messages from the server to our update chan
+func (c *Client) read() {
+	defer close(c.out)
+	for {
+		m := &Message{}
+		err := c.conn.ReadJSON(m)
+		if err != nil {
+			c.send(&sim.Update{Error: fmt.Errorf("%w: %v", ErrDisconnected, err)})
+			return
+		}
+		if m.Type == MessageEnd {
+			return
+		}
+		if !c.send(m.Update()) {
+			return
+		}
+	}
+}
+
+// send passes an update on unless we're stopped, returning if it was sent
+func (c *Client) send(u *sim.Update) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.out <- u:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// wsAddr returns the websocket address for a http(s) address
+func wsAddr(addr string) string {
+	addr = strings.TrimRight(addr, "/")
+	if strings.HasPrefix(addr, "http") {
+		return "ws" + strings.TrimPrefix(addr, "http")
+	}
+	return addr
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// MessageType is the kind of message sent by the server
+type MessageType string
+
+const (
+	// MessageUpdate holds an Update from the simulator
+	MessageUpdate MessageType = "update"
+
+	// MessageError is a reply to an Action the server couldn't accept
+	MessageError MessageType = "error"
+
+	// MessageEnd is sent when the battle is over, after which the server
+	// closes the connection
+	MessageEnd MessageType = "end"
+)
+
+const (
+	// kindInvalid marks an error as an invalid choice
+	kindInvalid = "invalid"
+
+	// kindUnavailable marks an error as an unavailable choice
+	kindUnavailable = "unavailable"
+
+	// kindCrashed marks an error as the simulator having crashed
+	kindCrashed = "crashed"
+)
+
+// Message is what the server sends to clients (as JSON)
+type Message struct {
+	Type MessageType `json:"type"`
+
+	// Number, Side, Event & Error are as given in the sim.Update
+	Number int          `json:"number,omitempty"`
+	Side   *sim.Side    `json:"side,omitempty"`
+	Event  *event.Event `json:"event,omitempty"`
+	Error  string       `json:"error,omitempty"`
+
+	// Kind is set if Error is a choice error ("invalid", "unavailable")
+	// or the simulator crashed ("crashed")
+	Kind string `json:"kind,omitempty"`
+}
+
+// toMessage returns the message for an update from a player's view
+func toMessage(u *sim.Update) *Message {
+	m := &Message{Type: MessageUpdate, Number: u.Number, Side: u.Side, Event: u.Event}
+	if u.Error != nil {
+		m.Error = u.Error.Error()
+		if sim.IsInvalidChoice(u.Error) {
+			m.Kind = kindInvalid
+		} else if sim.IsUnavailableChoice(u.Error) {
+			m.Kind = kindUnavailable
+		} else if sim.IsSimulatorCrashed(u.Error) {
+			m.Kind = kindCrashed
+		}
+	}
+	return m
+}
+
+// Update returns the message as a simulator update, where errors still
+// satisfy sim.IsInvalidChoice, sim.IsUnavailableChoice &
+// sim.IsSimulatorCrashed
+func (m *Message) Update() *sim.Update {
+	u := &sim.Update{Number: m.Number, Side: m.Side, Event: m.Event}
+	if m.Error != "" {
+		var kind error
+		switch m.Kind {
+		case kindInvalid:
+			kind = parse.ErrInvalidChoice
+		case kindUnavailable:
+			kind = parse.ErrUnavailableChoice
+		case kindCrashed:
+			kind = sim.ErrSimulatorCrashed
+		}
+		u.Error = &remoteError{msg: m.Error, kind: kind}
+	}
+	return u
+}
+
+// remoteError is an error given by the server
+type remoteError struct {
+	msg  string
+	kind error
+}
+
+// Error returns the error as the server gave it
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the kind of error (if known) so errors.Is works as
+// it would locally
+func (e *remoteError) Unwrap() error {
+	return e.kind
+}
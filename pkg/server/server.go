@@ -0,0 +1,331 @@
+/*
+Package server hosts battles over HTTP & websockets so players can run
+as separate services.
+
+	POST   /battles                      create a battle from a sim.BattleSpec
+	GET    /battles                      list battles
+	GET    /battles/{id}                 describe a battle
+	DELETE /battles/{id}                 stop a battle
+	GET    /battles/{id}/play/{player}   websocket for a player (ie. p1)
+	GET    /battles/{id}/watch           websocket for spectators
+
+Creating a battle returns a token for each player, which must be given
+to play as them (as "Authorization: Bearer {token}", or a "token" query
+parameter for browsers).
+
+Websockets are sent a JSON Message for each update of a sim.Views player
+view. Players are sent their own side requests, choice errors & the event
+stream, spectators are sent only the event stream. Other player's
+pokemon have HP given as a percentage, and the battle's seed is never
+sent. Players write JSON sim.Actions.
+Anyone connecting late is first sent everything they've missed.
+
+Client implements sim.SimulatorStream over a websocket, so players can
+battle remotely as they would locally.
+*/
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// indirection allows for easier testing
+	newStream = sim.NewSimulatorStream
+
+	// ErrNotFound is returned if a battle doesn't exist
+	ErrNotFound = errors.New("battle not found")
+
+	// ErrInvalidPlayer is returned when connecting as a player that
+	// isn't in the battle
+	ErrInvalidPlayer = errors.New("invalid player")
+
+	// ErrUnauthorized is returned when connecting as a player without
+	// their token
+	ErrUnauthorized = errors.New("invalid token")
+
+	// ErrSpectator is returned if a spectator tries to write an action
+	ErrSpectator = sim.ErrSpectator
+)
+
+// players are those we allow to connect
+var players = map[string]bool{"p1": true, "p2": true, "p3": true, "p4": true}
+
+// Server hosts battles. It implements http.Handler.
+type Server struct {
+	upgrader websocket.Upgrader
+
+	lock    sync.Mutex
+	battles map[string]*battle
+}
+
+// New returns a server without any battles
+func New() *Server {
+	return &Server{battles: map[string]*battle{}}
+}
+
+// Create starts a new battle, returning it's ID & a token for each player.
+// Finished battles are kept (so they can be watched) until deleted.
+func (s *Server) Create(spec *sim.BattleSpec) (*Created, error) {
+	tokens := map[string]string{}
+	for _, player := range seats(spec) {
+		token, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		tokens[player] = token
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := newStream(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBattle(id, spec.Format, stream, tokens)
+	s.lock.Lock()
+	s.battles[id] = b
+	s.lock.Unlock()
+
+	go b.run()
+	return &Created{Info: *b.info(), Tokens: tokens}, nil
+}
+
+// Battles returns all battles, ordered by ID
+func (s *Server) Battles() []*Info {
+	s.lock.Lock()
+	all := []*battle{}
+	for _, b := range s.battles {
+		all = append(all, b)
+	}
+	s.lock.Unlock()
+
+	out := []*Info{}
+	for _, b := range all {
+		out = append(out, b.info())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Delete stops & removes a battle
+func (s *Server) Delete(id string) error {
+	s.lock.Lock()
+	b, ok := s.battles[id]
+	delete(s.battles, id)
+	s.lock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w %s", ErrNotFound, id)
+	}
+	b.stop()
+	return nil
+}
+
+// Close stops & removes all battles
+func (s *Server) Close() {
+	for _, b := range s.Battles() {
+		s.Delete(b.ID)
+	}
+}
+
+// battle returns the battle with the given ID
+func (s *Server) battle(id string) (*battle, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	b, ok := s.battles[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNotFound, id)
+	}
+	return b, nil
+}
+
+// ServeHTTP routes requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bits := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if bits[0] != "battles" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(bits) == 1 && r.Method == http.MethodPost:
+		s.handleCreate(w, r)
+	case len(bits) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Battles())
+	case len(bits) == 2 && r.Method == http.MethodGet:
+		b, err := s.battle(bits[1])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, b.info())
+	case len(bits) == 2 && r.Method == http.MethodDelete:
+		err := s.Delete(bits[1])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(bits) == 4 && bits[2] == "play" && r.Method == http.MethodGet:
+		if !players[bits[3]] {
+			writeError(w, fmt.Errorf("%w %s", ErrInvalidPlayer, bits[3]))
+			return
+		}
+		s.handleConn(w, r, bits[1], bits[3])
+	case len(bits) == 3 && bits[2] == "watch" && r.Method == http.MethodGet:
+		s.handleConn(w, r, bits[1], "")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleCreate starts a battle from the BattleSpec in the request body
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	spec := &sim.BattleSpec{}
+	err := json.NewDecoder(r.Body).Decode(spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := s.Create(spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// handleConn connects a player (or spectator, if player is "") to a
+// battle over a websocket
+func (s *Server) handleConn(w http.ResponseWriter, r *http.Request, id, player string) {
+	b, err := s.battle(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if player != "" && !b.allowed(player, token(r)) {
+		writeError(w, fmt.Errorf("%w for %s", ErrUnauthorized, player))
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied
+		return
+	}
+	defer conn.Close()
+
+	view := b.view(player)
+	defer view.Stop()
+
+	// nb. a websocket allows only one writer at a time
+	var lock sync.Mutex
+	send := func(m *Message) error {
+		lock.Lock()
+		defer lock.Unlock()
+		return conn.WriteJSON(m)
+	}
+
+	go func() {
+		// nb. if the connection closes we stop the view, which ends
+		// the writer below
+		defer view.Stop()
+		for {
+			act := &sim.Action{}
+			err := conn.ReadJSON(act)
+			if err != nil {
+				return
+			}
+			err = b.write(view, act)
+			if err != nil {
+				send(&Message{Type: MessageError, Error: err.Error()})
+			}
+		}
+	}()
+
+	for u := range view.Updates() {
+		err = send(toMessage(u))
+		if err != nil {
+			return
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	conn.WriteJSON(&Message{Type: MessageEnd})
+	conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+	)
+}
+
+// seats returns the players in a battle
+func seats(spec *sim.BattleSpec) []string {
+	n := len(spec.Players)
+	if sim.IsRandom(spec.Format) {
+		n = 2
+	}
+	out := []string{}
+	for i := 1; i <= n && players[fmt.Sprintf("p%d", i)]; i++ {
+		out = append(out, fmt.Sprintf("p%d", i))
+	}
+	return out
+}
+
+// token returns the token given with a request
+func token(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// newID returns a random battle ID (or token)
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// writeJSON replies with the given value as JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError replies with a status suitable for the error
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidPlayer):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		status = http.StatusUnauthorized
+	}
+	http.Error(w, err.Error(), status)
+}
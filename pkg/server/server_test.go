@@ -0,0 +1,338 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// fakeStream sends whatever the test gives it & records writes
+type fakeStream struct {
+	lock    sync.Mutex
+	updates chan *sim.Update
+	writes  []*sim.Action
+	once    sync.Once
+}
+
+func (f *fakeStream) Write(a *sim.Action) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.writes = append(f.writes, a)
+	return nil
+}
+
+func (f *fakeStream) Updates() <-chan *sim.Update {
+	return f.updates
+}
+
+func (f *fakeStream) Stop() {
+	f.once.Do(func() { close(f.updates) })
+}
+
+func (f *fakeStream) written() []*sim.Action {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]*sim.Action{}, f.writes...)
+}
+
+// testServer starts a server where battles use the returned stream
+func testServer(t *testing.T) (*httptest.Server, *fakeStream) {
+	fake := &fakeStream{updates: make(chan *sim.Update, 100)}
+	newStream = func(*sim.BattleSpec) (sim.SimulatorStream, error) {
+		return fake, nil
+	}
+
+	srv := New()
+	hs := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		newStream = sim.NewSimulatorStream
+		srv.Close()
+		hs.Close()
+	})
+	return hs, fake
+}
+
+func testRequest(name string) *sim.Update {
+	return &sim.Update{Side: &sim.Side{
+		Player: name,
+		Field: []*sim.Slot{&sim.Slot{
+			ID:      name + "a",
+			Options: &sim.Options{Moves: []*sim.Move{&sim.Move{ID: "tackle"}}},
+		}},
+		Pokemon: []*sim.Pokemon{&sim.Pokemon{ID: name + "-lead", Active: true}},
+	}}
+}
+
+// create starts a battle, failing the test if it can't
+func create(t *testing.T, hs *httptest.Server) *Created {
+	b, err := Create(hs.URL, &sim.BattleSpec{Format: sim.FormatGen8Random})
+	assert.Nil(t, err)
+	return b
+}
+
+// next returns the next update, failing the test if there isn't one soon
+func next(t *testing.T, c *Client) *sim.Update {
+	select {
+	case u, ok := <-c.Updates():
+		if !ok {
+			t.Fatal("updates closed")
+		}
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+	return nil
+}
+
+// closed returns if the client's updates are closed soon
+func closed(c *Client) bool {
+	select {
+	case _, ok := <-c.Updates():
+		return !ok
+	case <-time.After(2 * time.Second):
+		return false
+	}
+}
+
+func TestPlayersSeeOwnSide(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	p1, err := Dial(hs.URL, b.ID, "p1", b.Tokens["p1"])
+	assert.Nil(t, err)
+	defer p1.Stop()
+	p2, err := Dial(hs.URL, b.ID, "p2", b.Tokens["p2"])
+	assert.Nil(t, err)
+	defer p2.Stop()
+	watcher, err := Watch(hs.URL, b.ID)
+	assert.Nil(t, err)
+	defer watcher.Stop()
+
+	first := testRequest("p1")
+	first.Seed = sim.Seed{1, 2, 3, 4}
+	fake.updates <- first
+	fake.updates <- testRequest("p2")
+	fake.updates <- &sim.Update{Event: event.Parse("|-damage|p2a: Umbreon|150/200")}
+	fake.updates <- &sim.Update{Event: event.Parse("|win|p1")}
+	fake.Stop()
+
+	for _, c := range []*Client{p1, p2} {
+		u := next(t, c)
+		assert.True(t, u.Seed.IsZero())
+		assert.Equal(t, c.Player, u.Side.Player)
+		assert.Equal(t, c.Player+"-lead", u.Side.Pokemon[0].ID)
+
+		u = next(t, c)
+		assert.Equal(t, event.Damage, u.Event.Type)
+
+		u = next(t, c)
+		assert.Equal(t, &event.WinEvent{Player: "p1"}, u.Event.Payload)
+
+		assert.True(t, closed(c))
+	}
+
+	for _, kind := range []string{event.Damage, event.Win} {
+		u := next(t, watcher)
+		assert.Nil(t, u.Side)
+		assert.True(t, u.Seed.IsZero())
+		assert.Equal(t, kind, u.Event.Type)
+	}
+	assert.True(t, closed(watcher))
+}
+
+func TestFoeHPIsPercentage(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	p1, err := Dial(hs.URL, b.ID, "p1", b.Tokens["p1"])
+	assert.Nil(t, err)
+	defer p1.Stop()
+	p2, err := Dial(hs.URL, b.ID, "p2", b.Tokens["p2"])
+	assert.Nil(t, err)
+	defer p2.Stop()
+
+	fake.updates <- &sim.Update{Event: event.ParseSplit("|-damage|p2a: Umbreon|131/200", "|-damage|p2a: Umbreon|66/100")}
+
+	u := next(t, p1)
+	assert.Equal(t, "66/100", u.Event.Name)
+
+	u = next(t, p2)
+	assert.Equal(t, "131/200", u.Event.Name)
+}
+
+func TestPlayersWriteActions(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	p2, err := Dial(hs.URL, b.ID, "p2", b.Tokens["p2"])
+	assert.Nil(t, err)
+	defer p2.Stop()
+	watcher, err := Watch(hs.URL, b.ID)
+	assert.Nil(t, err)
+	defer watcher.Stop()
+
+	// a player may not act for someone else
+	act := &sim.Action{Player: "p1", Specs: []*sim.ActionSpec{&sim.ActionSpec{Type: sim.ActionMove, ID: "tackle"}}}
+	assert.Nil(t, p2.Write(act))
+	assert.True(t, errors.Is(watcher.Write(act), ErrSpectator))
+
+	assert.Eventually(t, func() bool { return len(fake.written()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	result := fake.written()[0]
+	assert.Equal(t, "p2", result.Player)
+	assert.Equal(t, act.Specs, result.Specs)
+}
+
+func TestChoiceErrors(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	p1, err := Dial(hs.URL, b.ID, "p1", b.Tokens["p1"])
+	assert.Nil(t, err)
+	defer p1.Stop()
+	watcher, err := Watch(hs.URL, b.ID)
+	assert.Nil(t, err)
+	defer watcher.Stop()
+
+	fake.updates <- &sim.Update{Error: fmt.Errorf("%w [Invalid choice] nope", parse.ErrInvalidChoice)}
+	fake.updates <- &sim.Update{Event: event.Parse("|turn|1")}
+
+	u := next(t, p1)
+	assert.True(t, sim.IsInvalidChoice(u.Error))
+	assert.False(t, sim.IsUnavailableChoice(u.Error))
+
+	// spectators don't get choice errors
+	u = next(t, watcher)
+	assert.Equal(t, event.Turn, u.Event.Type)
+}
+
+func TestSimulatorCrash(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	p1, err := Dial(hs.URL, b.ID, "p1", b.Tokens["p1"])
+	assert.Nil(t, err)
+	defer p1.Stop()
+
+	fake.updates <- &sim.Update{Error: fmt.Errorf("%w: boom", sim.ErrSimulatorCrashed)}
+
+	u := next(t, p1)
+	assert.True(t, sim.IsSimulatorCrashed(u.Error))
+	assert.False(t, sim.IsInvalidChoice(u.Error))
+}
+
+func TestLateJoinersCatchUp(t *testing.T) {
+	hs, fake := testServer(t)
+	b := create(t, hs)
+
+	fake.updates <- testRequest("p1")
+	fake.updates <- testRequest("p2")
+	fake.updates <- &sim.Update{Event: event.Parse("|turn|1")}
+	fake.Stop()
+
+	// wait for the battle to end
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(hs.URL + "/battles/" + b.ID)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		info := &Info{}
+		json.NewDecoder(resp.Body).Decode(info)
+		return info.Done
+	}, 2*time.Second, 10*time.Millisecond)
+
+	p2, err := Dial(hs.URL, b.ID, "p2", b.Tokens["p2"])
+	assert.Nil(t, err)
+	defer p2.Stop()
+
+	u := next(t, p2)
+	assert.Equal(t, "p2", u.Side.Player)
+
+	u = next(t, p2)
+	assert.Equal(t, event.Turn, u.Event.Type)
+
+	assert.True(t, closed(p2))
+}
+
+func TestBattleNotFound(t *testing.T) {
+	hs, _ := testServer(t)
+
+	_, err := Dial(hs.URL, "nope", "p1", "")
+	assert.True(t, errors.Is(err, ErrRequest))
+
+	_, err = Watch(hs.URL, "nope")
+	assert.True(t, errors.Is(err, ErrRequest))
+}
+
+func TestInvalidPlayer(t *testing.T) {
+	hs, _ := testServer(t)
+	b := create(t, hs)
+
+	_, err := Dial(hs.URL, b.ID, "p9", b.Tokens["p1"])
+
+	assert.True(t, errors.Is(err, ErrRequest))
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestPlayersNeedTokens(t *testing.T) {
+	hs, _ := testServer(t)
+	b := create(t, hs)
+	assert.Equal(t, 2, len(b.Tokens))
+	assert.NotEqual(t, b.Tokens["p1"], b.Tokens["p2"])
+
+	for name, token := range map[string]string{"none": "", "other player's": b.Tokens["p1"], "wrong": "nope"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Dial(hs.URL, b.ID, "p2", token)
+			assert.True(t, errors.Is(err, ErrRequest))
+			assert.Contains(t, err.Error(), "401")
+		})
+	}
+
+	// players not in the battle have no token
+	_, err := Dial(hs.URL, b.ID, "p3", "")
+	assert.Contains(t, err.Error(), "401")
+
+	// browsers can't set headers, so may give the token as a parameter
+	c, err := dial(fmt.Sprintf("%s/battles/%s/play/p2?token=%s", wsAddr(hs.URL), b.ID, b.Tokens["p2"]), "p2", nil)
+	assert.Nil(t, err)
+	c.Stop()
+}
+
+func TestCreateError(t *testing.T) {
+	hs, _ := testServer(t)
+	newStream = func(*sim.BattleSpec) (sim.SimulatorStream, error) {
+		return nil, errors.New("bad spec")
+	}
+
+	_, err := Create(hs.URL, &sim.BattleSpec{})
+
+	assert.True(t, errors.Is(err, ErrRequest))
+	assert.Contains(t, err.Error(), "bad spec")
+}
+
+func TestDelete(t *testing.T) {
+	srv := New()
+	fake := &fakeStream{updates: make(chan *sim.Update, 100)}
+	newStream = func(*sim.BattleSpec) (sim.SimulatorStream, error) {
+		return fake, nil
+	}
+	defer func() { newStream = sim.NewSimulatorStream }()
+
+	b, err := srv.Create(&sim.BattleSpec{Format: sim.FormatGen8Random})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Info{&Info{ID: b.ID, Format: sim.FormatGen8Random}}, srv.Battles())
+
+	assert.Nil(t, srv.Delete(b.ID))
+	assert.True(t, errors.Is(srv.Delete(b.ID), ErrNotFound))
+	assert.Equal(t, []*Info{}, srv.Battles())
+}
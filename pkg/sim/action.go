@@ -61,6 +61,12 @@ type ActionSpec struct {
 
 // Pack represents this action as a showdown simulator compliant string
 func (a *Action) Pack() string {
+	return fmt.Sprintf(">%s %s\n", a.Player, a.Choice())
+}
+
+// Choice returns the action's choices without the player,
+// as used by a showdown server's /choose command
+func (a *Action) Choice() string {
 	lines := []string{}
 	for _, spec := range a.Specs {
 		switch spec.Type {
@@ -72,7 +78,7 @@ func (a *Action) Pack() string {
 			lines = append(lines, fmt.Sprintf("switch %s", spec.ID))
 		}
 	}
-	return fmt.Sprintf(">%s %s\n", a.Player, strings.Join(lines, ","))
+	return strings.Join(lines, ",")
 }
 
 // packMove packs a piece of an action into a showdown style movespec
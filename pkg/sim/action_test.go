@@ -89,3 +89,15 @@ func TestPackMove(t *testing.T) {
 		})
 	}
 }
+
+func TestChoice(t *testing.T) {
+	act := &Action{
+		Player: "p1",
+		Specs: []*ActionSpec{
+			&ActionSpec{Type: ActionMove, ID: "thunderbolt", Target: 1},
+			&ActionSpec{Type: ActionPass},
+		},
+	}
+
+	assert.Equal(t, "move thunderbolt 1,pass", act.Choice())
+}
@@ -13,12 +13,38 @@ const (
 
 	// FormatGen8Doubles a SS doubles battle with given teams
 	FormatGen8Doubles Format = "[Gen 8] Doubles Ubers"
+
+	// FormatGen8Random a SS singles battle with teams generated by the simulator
+	FormatGen8Random Format = "[Gen 8] Random Battle"
+
+	// FormatGen8RandomDoubles a SS doubles battle with teams generated by the simulator
+	FormatGen8RandomDoubles Format = "[Gen 8] Random Doubles Battle"
+
+	// FormatGen7Random a SM singles battle with teams generated by the simulator
+	FormatGen7Random Format = "[Gen 7] Random Battle"
+
+	// FormatGen7RandomDoubles a SM doubles battle with teams generated by the simulator
+	FormatGen7RandomDoubles Format = "[Gen 7] Random Doubles Battle"
+
+	// FormatGen6Random a XY singles battle with teams generated by the simulator
+	FormatGen6Random Format = "[Gen 6] Random Battle"
 )
 
 // isDoubles holds if a format is a double battle.
 // At present it's assumed not-doubles is singles.
 var isDoubles = map[Format]bool{
-	FormatGen8Doubles: true,
+	FormatGen8Doubles:       true,
+	FormatGen8RandomDoubles: true,
+	FormatGen7RandomDoubles: true,
+}
+
+// isRandom holds if a format has teams generated by the simulator
+var isRandom = map[Format]bool{
+	FormatGen8Random:        true,
+	FormatGen8RandomDoubles: true,
+	FormatGen7Random:        true,
+	FormatGen7RandomDoubles: true,
+	FormatGen6Random:        true,
 }
 
 // IsDoubles returns if the given format is a `doubles` battle
@@ -27,22 +53,41 @@ func IsDoubles(f Format) bool {
 	return ok
 }
 
+// IsRandom returns if the given format is a random battle, where teams are
+// generated by the simulator
+func IsRandom(f Format) bool {
+	ok := isRandom[f]
+	return ok
+}
+
 // BattleSpec is all the required data to start a new battle
 type BattleSpec struct {
 	// Format is the battle style (singles/doubles)
 	Format Format
 
-	// Players indicates player team(s). Random battles can use nil
-	// values to represent how many players there are.
+	// Players indicates player team(s). Random battles can leave this
+	// unset (or use nil teams), the generated teams are given on each
+	// player's first side update (see Side.Team).
 	// The simulator refers to the players in order as p1 p2 p3 etc...
 	Players [][]*PokemonSpec
 
-	// Seed for internal RNG
-	Seed int
+	// Seed for internal RNG, if unset one is chosen at random & returned
+	// in the first update
+	Seed Seed
+
+	// Restarts is how many times the simulator is restarted if it
+	// crashes. The battle is replayed (from the seed & choices so far)
+	// so the stream carries on from where it was. If unset a crash ends
+	// the stream with ErrSimulatorCrashed.
+	Restarts int
 }
 
 // validate does some simple checks
 func (b *BattleSpec) validate() error {
+	if IsRandom(b.Format) {
+		return b.validateRandom()
+	}
+
 	if b.Players == nil {
 		return fmt.Errorf("no player team data")
 	}
@@ -64,3 +109,24 @@ func (b *BattleSpec) validate() error {
 
 	return nil
 }
+
+// validateRandom checks a random battle spec
+func (b *BattleSpec) validateRandom() error {
+	if len(b.Players) != 0 && len(b.Players) != 2 {
+		return fmt.Errorf("two players are required")
+	}
+	for _, p := range b.Players {
+		if len(p) > 0 {
+			return fmt.Errorf("teams are generated in random battles and cannot be given")
+		}
+	}
+	return nil
+}
+
+// teams returns the number of teams in the battle
+func (b *BattleSpec) teams() int {
+	if IsRandom(b.Format) {
+		return 2
+	}
+	return len(b.Players)
+}
@@ -7,9 +7,16 @@ import (
 
 func TestIsDoubles(t *testing.T) {
 	assert.True(t, IsDoubles(FormatGen8Doubles))
+	assert.True(t, IsDoubles(FormatGen8RandomDoubles))
 	assert.True(t, !IsDoubles(FormatGen8))
 }
 
+func TestIsRandom(t *testing.T) {
+	assert.True(t, IsRandom(FormatGen8Random))
+	assert.True(t, IsRandom(FormatGen7RandomDoubles))
+	assert.True(t, !IsRandom(FormatGen8))
+}
+
 var dataTestValidate = []struct {
 	Name string
 	In   *BattleSpec
@@ -112,6 +119,31 @@ var dataTestValidate = []struct {
 			}},
 		true,
 	},
+	{
+		"random-no-teams",
+		&BattleSpec{Format: FormatGen8Random},
+		false,
+	},
+	{
+		"random-nil-teams",
+		&BattleSpec{Format: FormatGen8RandomDoubles, Players: [][]*PokemonSpec{nil, nil}},
+		false,
+	},
+	{
+		"random-too-many-players",
+		&BattleSpec{Format: FormatGen8Random, Players: [][]*PokemonSpec{nil, nil, nil}},
+		true,
+	},
+	{
+		"random-given-team",
+		&BattleSpec{
+			Format: FormatGen8Random,
+			Players: [][]*PokemonSpec{
+				[]*PokemonSpec{&PokemonSpec{}},
+				nil,
+			}},
+		true,
+	},
 }
 
 func TestValidate(t *testing.T) {
@@ -0,0 +1,198 @@
+package sim
+
+import (
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+const (
+	// MaxStage is the highest (or, negated, lowest) a stat stage can go
+	MaxStage = 6
+)
+
+// BoostStats are the stats that have stages, as named by the simulator
+var BoostStats = []string{"atk", "def", "spa", "spd", "spe", "accuracy", "evasion"}
+
+// batonPassed are volatiles that Baton Pass hands on to the incoming pokemon
+var batonPassed = map[string]bool{
+	"aquaring":    true,
+	"confusion":   true,
+	"curse":       true,
+	"embargo":     true,
+	"focusenergy": true,
+	"healblock":   true,
+	"ingrain":     true,
+	"laserfocus":  true,
+	"leechseed":   true,
+	"magnetrise":  true,
+	"perish1":     true,
+	"perish2":     true,
+	"perish3":     true,
+	"powertrick":  true,
+	"substitute":  true,
+	"telekinesis": true,
+}
+
+// Boosts are a pokemon's stat stages, each from -6 to +6
+type Boosts struct {
+	Atk      int
+	Def      int
+	SpA      int
+	SpD      int
+	Spe      int
+	Accuracy int
+	Evasion  int
+}
+
+// stat returns a pointer to the named stat stage (or nil)
+func (b *Boosts) stat(name string) *int {
+	switch name {
+	case "atk":
+		return &b.Atk
+	case "def":
+		return &b.Def
+	case "spa":
+		return &b.SpA
+	case "spd":
+		return &b.SpD
+	case "spe":
+		return &b.Spe
+	case "accuracy":
+		return &b.Accuracy
+	case "evasion":
+		return &b.Evasion
+	}
+	return nil
+}
+
+// Get returns the stage of the named stat ie. "spa"
+func (b *Boosts) Get(stat string) int {
+	v := b.stat(stat)
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// Set sets the stage of the named stat, within -6 to +6
+func (b *Boosts) Set(stat string, stage int) {
+	v := b.stat(stat)
+	if v == nil {
+		return
+	}
+	if stage > MaxStage {
+		stage = MaxStage
+	} else if stage < -MaxStage {
+		stage = -MaxStage
+	}
+	*v = stage
+}
+
+// Add changes the stage of the named stat by the given number of stages
+func (b *Boosts) Add(stat string, stages int) {
+	b.Set(stat, b.Get(stat)+stages)
+}
+
+// Invert flips the sign of all stages (Topsy-Turvy)
+func (b *Boosts) Invert() {
+	for _, stat := range BoostStats {
+		b.Set(stat, -b.Get(stat))
+	}
+}
+
+// Clear resets stages. If positive or negative is given only stages above
+// or below zero respectively are reset.
+func (b *Boosts) Clear(positive, negative bool) {
+	for _, stat := range BoostStats {
+		v := b.Get(stat)
+		if (positive && v > 0) || (negative && v < 0) || (!positive && !negative) {
+			b.Set(stat, 0)
+		}
+	}
+}
+
+// IsZero returns if no stat has been boosted or lowered
+func (b *Boosts) IsZero() bool {
+	return *b == Boosts{}
+}
+
+// statsOrAll returns the given stats, or all stats with stages if none
+func statsOrAll(stats []string) []string {
+	if len(stats) == 0 {
+		return BoostStats
+	}
+	return stats
+}
+
+// boostEvent updates the stat stages of pokemon on the field from an event.
+// Nb. Psych Up, Heart Swap etc can involve pokemon from either player.
+func (s *stream) boostEvent(e *event.Event) {
+	switch p := e.Payload.(type) {
+	case *event.BoostEvent:
+		m := s.member(p.Pokemon)
+		if m == nil {
+			return
+		}
+		if p.Set {
+			m.status.boosts.Set(p.Stat, p.Stages)
+		} else {
+			m.status.boosts.Add(p.Stat, p.Stages)
+		}
+		m.sync()
+	case *event.InvertBoostEvent:
+		m := s.member(p.Pokemon)
+		if m == nil {
+			return
+		}
+		m.status.boosts.Invert()
+		m.sync()
+	case *event.ClearBoostEvent:
+		members := []*member{}
+		if p.Pokemon == nil {
+			for _, r := range s.rosters {
+				for _, m := range r.field {
+					members = append(members, m)
+				}
+			}
+		} else if m := s.member(p.Pokemon); m != nil {
+			members = append(members, m)
+		}
+		for _, m := range members {
+			m.status.boosts.Clear(p.Positive, p.Negative)
+			m.sync()
+		}
+	case *event.SwapBoostEvent:
+		a, b := s.member(p.Source), s.member(p.Target)
+		if a == nil || b == nil {
+			return
+		}
+		for _, stat := range statsOrAll(p.Stats) {
+			x, y := a.status.boosts.Get(stat), b.status.boosts.Get(stat)
+			a.status.boosts.Set(stat, y)
+			b.status.boosts.Set(stat, x)
+		}
+		a.sync()
+		b.sync()
+	case *event.CopyBoostEvent:
+		// |-copyboost|SOURCE|TARGET the source copies the target's stages
+		a, b := s.member(p.Source), s.member(p.Target)
+		if a == nil || b == nil {
+			return
+		}
+		for _, stat := range statsOrAll(p.Stats) {
+			a.status.boosts.Set(stat, b.status.boosts.Get(stat))
+		}
+		a.sync()
+	}
+}
+
+// member returns the team member in the given slot (if known)
+func (s *stream) member(sub *event.Subject) *member {
+	if sub == nil {
+		return nil
+	}
+	r, ok := s.rosters[sub.Player]
+	if !ok {
+		return nil
+	}
+	return r.at(sub.String())
+}
@@ -0,0 +1,174 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestBoostsAdd = []struct {
+	Start  int
+	Add    int
+	Expect int
+}{
+	{0, 2, 2},
+	{5, 2, 6},
+	{-5, -3, -6},
+	{6, -12, -6},
+	{-1, 1, 0},
+}
+
+func TestBoostsAdd(t *testing.T) {
+	for i, tt := range dataTestBoostsAdd {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			b := &Boosts{}
+			b.Set("spe", tt.Start)
+
+			b.Add("spe", tt.Add)
+
+			assert.Equal(t, tt.Expect, b.Spe)
+			assert.Equal(t, tt.Expect, b.Get("spe"))
+		})
+	}
+}
+
+func TestBoostsClear(t *testing.T) {
+	b := Boosts{Atk: 2, Def: -1, Evasion: 1}
+	b.Clear(true, false)
+	assert.Equal(t, Boosts{Def: -1}, b)
+
+	b = Boosts{Atk: 2, Def: -1, Evasion: 1}
+	b.Clear(false, true)
+	assert.Equal(t, Boosts{Atk: 2, Evasion: 1}, b)
+
+	b.Invert()
+	assert.Equal(t, Boosts{Atk: -2, Evasion: -1}, b)
+
+	b.Clear(false, false)
+	assert.True(t, b.IsZero())
+}
+
+// battleStream returns a stream that has seen both sides & leads switch in
+func battleStream() (*stream, *Side, *Side) {
+	s := testStream("a", "b")
+
+	p1 := testSide(
+		"p1",
+		testPokemon("p1: Vaporeon", "Vaporeon", "waterabsorb", true, "batonpass"),
+		testPokemon("p1: Lugia", "Lugia", "pressure", false, "aeroblast"),
+	)
+	p2 := testSide(
+		"p2",
+		testPokemon("p2: Gyarados", "Gyarados", "intimidate", true, "dragondance"),
+		testPokemon("p2: Espeon", "Espeon", "magicbounce", false, "psychup"),
+	)
+	s.fillIDs(&Update{Side: p1})
+	s.fillIDs(&Update{Side: p2})
+	feed(s,
+		"|switch|p1a: Vaporeon|Vaporeon, L50|100/100",
+		"|switch|p2a: Gyarados|Gyarados, L50|100/100",
+	)
+	return s, p1, p2
+}
+
+func TestBoostsFromEvents(t *testing.T) {
+	s, p1, p2 := battleStream()
+	vaporeon, gyarados := p1.Pokemon[0], p2.Pokemon[0]
+
+	feed(s,
+		"|-unboost|p1a: Vaporeon|atk|1|[from] ability: Intimidate|[of] p2a: Gyarados",
+		"|-boost|p2a: Gyarados|atk|1",
+		"|-boost|p2a: Gyarados|spe|1",
+		"|-boost|p2a: Gyarados|atk|1",
+		"|-setboost|p1a: Vaporeon|def|6|[from] move: Belly Drum",
+	)
+	assert.Equal(t, Boosts{Atk: -1, Def: 6}, vaporeon.Boosts)
+	assert.Equal(t, Boosts{Atk: 2, Spe: 1}, gyarados.Boosts)
+
+	// Psych Up copies the target's stages
+	feed(s, "|-copyboost|p1a: Vaporeon|p2a: Gyarados|[from] move: Psych Up")
+	assert.Equal(t, Boosts{Atk: 2, Spe: 1}, vaporeon.Boosts)
+
+	feed(s, "|-invertboost|p2a: Gyarados|[from] move: Topsy-Turvy")
+	assert.Equal(t, Boosts{Atk: -2, Spe: -1}, gyarados.Boosts)
+
+	feed(s, "|-swapboost|p1a: Vaporeon|p2a: Gyarados|spe|[from] move: Speed Swap")
+	assert.Equal(t, Boosts{Atk: 2, Spe: -1}, vaporeon.Boosts)
+	assert.Equal(t, Boosts{Atk: -2, Spe: 1}, gyarados.Boosts)
+
+	feed(s, "|-clearallboost")
+	assert.True(t, vaporeon.Boosts.IsZero())
+	assert.True(t, gyarados.Boosts.IsZero())
+
+	// switching out resets stages
+	feed(s,
+		"|-boost|p2a: Gyarados|atk|1",
+		"|switch|p2a: Espeon|Espeon, L50|100/100",
+	)
+	assert.True(t, gyarados.Boosts.IsZero())
+}
+
+func TestBoostsBatonPass(t *testing.T) {
+	s, _, _ := battleStream()
+
+	feed(s,
+		"|-boost|p1a: Vaporeon|def|2",
+		"|-start|p1a: Vaporeon|Substitute",
+		"|-start|p1a: Vaporeon|move: Taunt",
+		"|move|p1a: Vaporeon|Baton Pass|p1a: Vaporeon",
+	)
+
+	// the next request is sent before the switch event
+	next := testSide(
+		"p1",
+		testPokemon("p1: Lugia", "Lugia", "pressure", true, "aeroblast"),
+		testPokemon("p1: Vaporeon", "Vaporeon", "waterabsorb", false, "batonpass"),
+	)
+	s.fillIDs(&Update{Side: next})
+	feed(s, "|switch|p1a: Lugia|Lugia, L50|100/100")
+
+	lugia, vaporeon := next.Pokemon[0], next.Pokemon[1]
+	assert.Equal(t, Boosts{Def: 2}, lugia.Boosts)
+	assert.Equal(t, []string{"substitute"}, lugia.Volatiles.List())
+	assert.True(t, vaporeon.Boosts.IsZero())
+	assert.Equal(t, 0, len(vaporeon.Volatiles))
+}
+
+func TestBoostsBatonPassFailed(t *testing.T) {
+	s, p1, _ := battleStream()
+
+	feed(s,
+		"|-boost|p1a: Vaporeon|def|2",
+		"|move|p1a: Vaporeon|Baton Pass|p1a: Vaporeon",
+		"|-fail|p1a: Vaporeon",
+		"|upkeep",
+		"|switch|p1a: Lugia|Lugia, L50|100/100",
+	)
+
+	assert.True(t, p1.Pokemon[1].Boosts.IsZero())
+}
+
+func TestBoostsFollowSwap(t *testing.T) {
+	s := testStream("a", "b")
+
+	side := &Side{
+		Player: "p1",
+		Field:  []*Slot{&Slot{ID: "p1a"}, &Slot{ID: "p1b"}},
+		Pokemon: []*Pokemon{
+			testPokemon("p1: Vaporeon", "Vaporeon", "waterabsorb", true, "batonpass"),
+			testPokemon("p1: Lugia", "Lugia", "pressure", true, "aeroblast"),
+		},
+	}
+	s.fillIDs(&Update{Side: side})
+	feed(s,
+		"|switch|p1a: Vaporeon|Vaporeon, L50|100/100",
+		"|switch|p1b: Lugia|Lugia, L50|100/100",
+		"|-boost|p1a: Vaporeon|spa|1",
+		"|swap|p1a: Vaporeon|1|[from] move: Ally Switch",
+		"|-boost|p1a: Lugia|spe|1",
+	)
+
+	assert.Equal(t, Boosts{SpA: 1}, side.Pokemon[0].Boosts)
+	assert.Equal(t, Boosts{Spe: 1}, side.Pokemon[1].Boosts)
+}
@@ -2,10 +2,42 @@ package sim
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/voidshard/poke-showdown-go/pkg/internal/cmd"
 	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
 )
 
+// ErrSimulatorCrashed is given as the last update's error if the simulator
+// exits mid battle, can't be written to or writes something we can't read
+// (& can't be restarted, see BattleSpec.Restarts)
+var ErrSimulatorCrashed = errors.New("simulator crashed")
+
+// ExitError holds the simulator's exit code & the last lines of it's
+// stderr. If it exited it can be found in ErrSimulatorCrashed errors with
+// errors.As.
+type ExitError = cmd.ExitError
+
+// crashError is ErrSimulatorCrashed with the reason the simulator crashed
+type crashError struct {
+	reason error
+}
+
+// Error implements error
+func (e *crashError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrSimulatorCrashed, e.reason)
+}
+
+// Is returns if the target is ErrSimulatorCrashed
+func (e *crashError) Is(target error) bool {
+	return target == ErrSimulatorCrashed
+}
+
+// Unwrap returns the reason the simulator crashed
+func (e *crashError) Unwrap() error {
+	return e.reason
+}
+
 // IsInvalidChoice returns if the given error is a ErrInvalidChoice
 func IsInvalidChoice(err error) bool {
 	return errors.Is(err, parse.ErrInvalidChoice)
@@ -15,3 +47,8 @@ func IsInvalidChoice(err error) bool {
 func IsUnavailableChoice(err error) bool {
 	return errors.Is(err, parse.ErrUnavailableChoice)
 }
+
+// IsSimulatorCrashed returns if the given error is ErrSimulatorCrashed
+func IsSimulatorCrashed(err error) bool {
+	return errors.Is(err, ErrSimulatorCrashed)
+}
@@ -0,0 +1,358 @@
+package sim
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+// member is a single pokemon on a player's team
+type member struct {
+	// ID given to us in the PokemonSpec (if any)
+	ID string
+
+	// Index in the team as originally given to the simulator
+	Index int
+
+	// last known simulator details
+	ident   string
+	species string
+	moves   string
+	fainted bool
+
+	// illusion indicates the pokemon can disguise itself (Zoroark)
+	illusion bool
+
+	// status conditions & the pokemon from the latest side update
+	// that we keep them up to date on
+	status  *condition
+	pokemon *Pokemon
+}
+
+// setPokemon updates the member from a side update.
+// Nb. we don't clear volatiles here if the pokemon isn't active, the
+// request may be sent before the switch that took it off the field
+// (which might pass on boosts with Baton Pass).
+func (m *member) setPokemon(p *Pokemon) {
+	m.status.setMajor(ParseStatus(p.Condition))
+	m.status.apply(p)
+	m.pokemon = p
+}
+
+// sync sets the member's conditions on the pokemon from the latest
+// side update
+func (m *member) sync() {
+	if m.pokemon != nil {
+		m.status.apply(m.pokemon)
+	}
+}
+
+// statusEvent updates the member's status from an event
+func (m *member) statusEvent(e *event.Event) {
+	m.status.fromEvent(e)
+	m.sync()
+}
+
+// matches returns if the given pokemon (from a side update) could be this member
+func (m *member) matches(p *Pokemon) bool {
+	return m.ident == p.Ident && data.SameSpecies(m.species, p.BaseSpecies) && m.moves == moveSignature(p)
+}
+
+// roster resolves which pokemon is which on a given player's team.
+//
+// The simulator reports the team in it's *current* order; that is whenever
+// a pokemon switches in it swaps team positions with the pokemon that
+// switched out. Pokemon idents ('p1: Nickname') aren't necessarily unique
+// (duplicate nicknames) and events can refer to pokemon by a disguise
+// (Zoroark's Illusion), so we track team positions & slot occupancy
+// rather than looking things up by name.
+type roster struct {
+	player string
+
+	// members in the order they were given to the simulator
+	members []*member
+
+	// order[i] is the member at the simulators team position i
+	order []*member
+
+	// slots maps slot IDs (p1a) to the member currently in them
+	slots map[string]*member
+
+	// field maps slot IDs to the member in them according to events
+	// alone. Unlike slots this isn't updated by side updates, which can
+	// arrive before the switch events that lead to them.
+	field map[string]*member
+
+	// passing maps slot IDs to a move that passes conditions to whatever
+	// switches in next (ie. batonpass)
+	passing map[string]string
+}
+
+// newRoster builds a roster from the first side update we see for a player.
+// At this point the simulator reports pokemon in the order they were given.
+func newRoster(side *Side, specs []*PokemonSpec) *roster {
+	r := &roster{
+		player:  side.Player,
+		members: []*member{},
+		order:   []*member{},
+		slots:   map[string]*member{},
+		field:   map[string]*member{},
+		passing: map[string]string{},
+	}
+
+	for i := range side.Pokemon {
+		m := &member{Index: i, status: newCondition()}
+		if i < len(specs) && specs[i] != nil {
+			m.ID = specs[i].ID
+		}
+		r.members = append(r.members, m)
+		r.order = append(r.order, m)
+	}
+
+	r.fromSide(side)
+	return r
+}
+
+// fromSide updates our team order from a side update & sets IDs on the
+// side's pokemon accordingly.
+func (r *roster) fromSide(side *Side) {
+	order := make([]*member, len(side.Pokemon))
+	assigned := map[*member]bool{}
+
+	// #1 pokemon that haven't moved (the vast majority)
+	for i, p := range side.Pokemon {
+		if i >= len(r.order) {
+			continue
+		}
+		m := r.order[i]
+		if m.species == "" || m.matches(p) {
+			order[i] = m
+			assigned[m] = true
+		}
+	}
+
+	// #2 pokemon that have moved; we prefer whichever matching member was
+	// nearest the front of the team (ie. the one that was likely active)
+	for i, p := range side.Pokemon {
+		if order[i] != nil {
+			continue
+		}
+		for _, m := range r.order {
+			if !assigned[m] && m.matches(p) {
+				order[i] = m
+				assigned[m] = true
+				break
+			}
+		}
+	}
+
+	// #3 anything left over; this shouldn't happen but if it does we'd rather
+	// hand out an ID than nothing at all.
+	for i, p := range side.Pokemon {
+		if order[i] != nil {
+			continue
+		}
+		for _, m := range r.members {
+			if !assigned[m] {
+				log.Printf("[identity] unable to match %s, assuming team member %d\n", p.Ident, m.Index)
+				order[i] = m
+				assigned[m] = true
+				break
+			}
+		}
+	}
+
+	r.order = order
+	r.slots = map[string]*member{}
+	for i, p := range side.Pokemon {
+		m := order[i]
+		if m == nil {
+			continue
+		}
+
+		m.ident = p.Ident
+		m.species = p.BaseSpecies
+		m.moves = moveSignature(p)
+		m.fainted = p.Status != nil && p.Status.IsFainted
+		m.illusion = data.Strip(p.Ability) == "illusion"
+		m.setPokemon(p)
+		p.ID = m.ID
+
+		// active pokemon are always reported at the front of the team,
+		// in slot order
+		if p.Active && i < len(side.Field) {
+			r.slots[slotID(i, r.player)] = m
+		}
+	}
+}
+
+// fromEvent updates slot occupancy from an event
+func (r *roster) fromEvent(e *event.Event) {
+	if e.Type == event.Upkeep || e.Type == event.Turn {
+		// a pass that didn't result in a switch (ie. no one to switch to)
+		r.passing = map[string]string{}
+	}
+	if e.Subject == nil || e.Subject.Player != r.player {
+		return
+	}
+	slot := e.Subject.String()
+
+	switch e.Type {
+	case event.Switch, event.Drag, event.Replace:
+		m := r.find(slot, e.Subject.Ident(), e.Name, e.Type == event.Replace)
+		if m == nil {
+			return
+		}
+		r.enter(slot, m, e.Type)
+		for s, other := range r.slots {
+			if other == m {
+				delete(r.slots, s)
+			}
+		}
+		r.slots[slot] = m
+	case event.Swap:
+		// |swap|POKEMON|POSITION (position is 0 indexed)
+		other := slotID(e.Magnitude, r.player)
+		r.slots[slot], r.slots[other] = r.slots[other], r.slots[slot]
+		r.field[slot], r.field[other] = r.field[other], r.field[slot]
+		for _, s := range []string{slot, other} {
+			if r.field[s] == nil {
+				delete(r.field, s)
+			}
+		}
+	case event.Move:
+		p, ok := e.Payload.(*event.MoveEvent)
+		if !ok {
+			return
+		}
+		switch move := data.Strip(p.Move); move {
+		case "batonpass", "shedtail":
+			r.passing[slot] = move
+		}
+	case event.Faint:
+		m := r.at(slot)
+		if m != nil {
+			m.fainted = true
+			m.statusEvent(e)
+		}
+	case event.CureTeam:
+		for _, m := range r.members {
+			m.statusEvent(e)
+		}
+	case event.Status, event.CureStatus, event.Start, event.End, event.Activate, event.Cant, event.Damage:
+		m := r.at(slot)
+		if m != nil {
+			m.statusEvent(e)
+		}
+	}
+}
+
+// enter records that the given member has entered the slot, clearing
+// (or passing on) the conditions of whatever was there before
+func (r *roster) enter(slot string, m *member, kind string) {
+	prev := r.field[slot]
+	r.field[slot] = m
+	if prev == nil || prev == m {
+		return
+	}
+
+	if kind == event.Replace {
+		// the pokemon was disguised (Illusion), so it's really been the one
+		// under the conditions all along
+		m.status.volatiles = prev.status.volatiles
+		m.status.confusion = prev.status.confusion
+		m.status.boosts = prev.status.boosts
+		prev.status.switchOut()
+	} else {
+		if move, ok := r.passing[slot]; ok && kind == event.Switch {
+			prev.status.pass(m.status, move)
+		}
+		prev.status.switchOut()
+	}
+	delete(r.passing, slot)
+
+	prev.sync()
+	m.sync()
+}
+
+// find returns the member that has entered the given slot with the given
+// ident & details (species, level, gender). If strict we require that the
+// species is exact (ie. Zoroark's illusion has ended).
+func (r *roster) find(slot, ident, details string, strict bool) *member {
+	species := strings.Split(details, ", ")[0]
+
+	// #1 if the simulator has already told us who is in the slot
+	// (side updates are sent before the events of a turn) then we're done.
+	current := r.slots[slot]
+	if current != nil && data.SameSpecies(current.species, species) {
+		return current
+	}
+	if !strict && current != nil && current.illusion {
+		// the simulator says Zoroark is here, but we've been told it's
+		// something else .. which is exactly what Illusion does.
+		return current
+	}
+
+	// #2 who could it be? Any healthy pokemon of the right species that
+	// isn't already on the field, preferring one with the same ident
+	onField := map[*member]bool{}
+	for s, m := range r.slots {
+		if s != slot {
+			onField[m] = true
+		}
+	}
+	var found *member
+	for _, m := range r.order {
+		if m.fainted || onField[m] || !data.SameSpecies(m.species, species) {
+			continue
+		}
+		if m.ident == ident {
+			return m
+		}
+		if found == nil {
+			found = m
+		}
+	}
+	if found != nil {
+		return found
+	}
+
+	// #3 no one matches, so we trust the last side update
+	if !strict && current != nil {
+		return current
+	}
+
+	return nil
+}
+
+// at returns the member in the given slot as far as events are concerned,
+// falling back to the last side update if we've seen no switch events
+func (r *roster) at(slot string) *member {
+	if m, ok := r.field[slot]; ok {
+		return m
+	}
+	return r.slots[slot]
+}
+
+// resolve returns the member in the given slot (if known)
+func (r *roster) resolve(s *event.Subject) *member {
+	if s == nil || s.Player != r.player {
+		return nil
+	}
+	return r.slots[s.String()]
+}
+
+// moveSignature returns the pokemon's moves as a single string.
+// Movesets rarely change mid battle, so this helps tell apart pokemon
+// of the same species with the same nickname.
+func moveSignature(p *Pokemon) string {
+	ids := []string{}
+	for _, m := range p.Moves {
+		ids = append(ids, m.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
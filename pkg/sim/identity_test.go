@@ -0,0 +1,206 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+// testPokemon builds a minimal pokemon as we'd get from a side update
+func testPokemon(ident, species, ability string, active bool, moves ...string) *Pokemon {
+	mvs := []*Move{}
+	for _, m := range moves {
+		mvs = append(mvs, &Move{ID: m})
+	}
+	return &Pokemon{
+		Ident:       ident,
+		Active:      active,
+		Species:     species,
+		BaseSpecies: species,
+		Ability:     ability,
+		Moves:       mvs,
+	}
+}
+
+// testSide builds a side update for a singles battle
+func testSide(player string, pkm ...*Pokemon) *Side {
+	return &Side{
+		Player:  player,
+		Field:   []*Slot{&Slot{ID: slotID(0, player)}},
+		Pokemon: pkm,
+	}
+}
+
+func testStream(ids ...string) *stream {
+	team := []*PokemonSpec{}
+	for _, id := range ids {
+		team = append(team, &PokemonSpec{ID: id})
+	}
+	return &stream{
+		rosters: map[string]*roster{},
+		spec:    &BattleSpec{Players: [][]*PokemonSpec{team, team}},
+	}
+}
+
+func TestFillIDsSwitch(t *testing.T) {
+	s := testStream("a", "b", "c")
+
+	first := &Update{Side: testSide(
+		"p1",
+		testPokemon("p1: Umbreon", "Umbreon", "synchronize", true, "wish"),
+		testPokemon("p1: Lugia", "Lugia", "pressure", false, "aeroblast"),
+		testPokemon("p1: Ninetales", "Ninetales", "drought", false, "fireblast"),
+	)}
+	s.fillIDs(first)
+
+	assert.Equal(t, "a", first.Side.Pokemon[0].ID)
+	assert.Equal(t, "b", first.Side.Pokemon[1].ID)
+	assert.Equal(t, "c", first.Side.Pokemon[2].ID)
+
+	// ninetales switches in, swapping places with umbreon
+	second := &Update{Side: testSide(
+		"p1",
+		testPokemon("p1: Ninetales", "Ninetales", "drought", true, "fireblast"),
+		testPokemon("p1: Lugia", "Lugia", "pressure", false, "aeroblast"),
+		testPokemon("p1: Umbreon", "Umbreon", "synchronize", false, "wish"),
+	)}
+	s.fillIDs(second)
+
+	assert.Equal(t, "c", second.Side.Pokemon[0].ID)
+	assert.Equal(t, "b", second.Side.Pokemon[1].ID)
+	assert.Equal(t, "a", second.Side.Pokemon[2].ID)
+
+	evt := event.Parse("|switch|p1a: Ninetales|Ninetales, L50, M|100/100")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "c", evt.Subject.ID)
+
+	evt = event.Parse("|-damage|p2a: Lugia|50/100|[from] ability: Rough Skin|[of] p1a: Ninetales")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "c", evt.Targets[0].ID)
+}
+
+func TestFillIDsDuplicateNicknames(t *testing.T) {
+	s := testStream("a", "b")
+
+	first := &Update{Side: testSide(
+		"p1",
+		testPokemon("p1: Bob", "Umbreon", "synchronize", true, "wish"),
+		testPokemon("p1: Bob", "Lugia", "pressure", false, "aeroblast"),
+	)}
+	s.fillIDs(first)
+
+	second := &Update{Side: testSide(
+		"p1",
+		testPokemon("p1: Bob", "Lugia", "pressure", true, "aeroblast"),
+		testPokemon("p1: Bob", "Umbreon", "synchronize", false, "wish"),
+	)}
+	s.fillIDs(second)
+
+	assert.Equal(t, "b", second.Side.Pokemon[0].ID)
+	assert.Equal(t, "a", second.Side.Pokemon[1].ID)
+
+	evt := event.Parse("|move|p1a: Bob|Aeroblast|p2a: Foo")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "b", evt.Subject.ID)
+}
+
+func TestFillIDsIllusion(t *testing.T) {
+	s := testStream("zoroark", "umbreon")
+
+	first := &Update{Side: testSide(
+		"p1",
+		testPokemon("p1: Zoroark", "Zoroark", "illusion", false, "nightdaze"),
+		testPokemon("p1: Umbreon", "Umbreon", "synchronize", false, "wish"),
+	)}
+	first.Side.Pokemon[0].Active = true
+	s.fillIDs(first)
+
+	// zoroark enters disguised as umbreon
+	evt := event.Parse("|switch|p1a: Umbreon|Umbreon, L50, M|100/100")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "zoroark", evt.Subject.ID)
+
+	evt = event.Parse("|-damage|p1a: Umbreon|50/100")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "zoroark", evt.Subject.ID)
+
+	evt = event.Parse("|replace|p1a: Zoroark|Zoroark, L50, M")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "zoroark", evt.Subject.ID)
+}
+
+func TestFillIDsOpponentMidTurn(t *testing.T) {
+	s := testStream("a", "b")
+
+	s.fillIDs(&Update{Side: testSide(
+		"p2",
+		testPokemon("p2: Umbreon", "Umbreon", "synchronize", true, "wish"),
+		testPokemon("p2: Lugia", "Lugia", "pressure", false, "aeroblast"),
+	)})
+
+	// lugia is dragged in by roar before we see another side update
+	evt := event.Parse("|drag|p2a: Lugia|Lugia, L50|100/100")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "b", evt.Subject.ID)
+
+	evt = event.Parse("|faint|p2a: Lugia")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "b", evt.Subject.ID)
+}
+
+func TestFillIDsByIdent(t *testing.T) {
+	s := testStream("a", "b", "c")
+
+	s.fillIDs(&Update{Side: testSide(
+		"p2",
+		testPokemon("p2: Lugia", "Lugia", "pressure", true, "aeroblast"),
+		testPokemon("p2: Ghost", "Gengar", "cursedbody", false, "shadowball"),
+		testPokemon("p2: Spooky", "Gengar", "cursedbody", false, "hex"),
+	)})
+
+	// both gengar could have been dragged in, but only one is called Spooky
+	evt := event.Parse("|drag|p2a: Spooky|Gengar, L50|100/100")
+	s.fillIDs(&Update{Event: evt})
+	assert.Equal(t, "c", evt.Subject.ID)
+	assert.Equal(t, 3, evt.Subject.Index)
+	assert.Equal(t, "Spooky", evt.Subject.Nickname)
+}
+
+func TestFillIDsRandomTeam(t *testing.T) {
+	s := &stream{rosters: map[string]*roster{}, spec: &BattleSpec{Format: FormatGen8Random}}
+
+	lugia := testPokemon("p1: Lugia", "Lugia", "pressure", true, "aeroblast", "roost")
+	lugia.Level = 73
+	first := &Update{Side: testSide(
+		"p1",
+		lugia,
+		testPokemon("p1: Foxy", "Ninetales", "drought", false, "fireblast"),
+	)}
+	s.fillIDs(first)
+
+	assert.Equal(t, []*PokemonSpec{
+		&PokemonSpec{
+			ID:      "p1-1",
+			Name:    "Lugia",
+			Species: "Lugia",
+			Ability: "pressure",
+			Moves:   []string{"aeroblast", "roost"},
+			Level:   73,
+		},
+		&PokemonSpec{
+			ID:      "p1-2",
+			Name:    "Foxy",
+			Species: "Ninetales",
+			Ability: "drought",
+			Moves:   []string{"fireblast"},
+		},
+	}, first.Side.Team)
+	assert.Equal(t, "p1-2", first.Side.Pokemon[1].ID)
+
+	second := &Update{Side: testSide("p1", first.Side.Pokemon...)}
+	s.fillIDs(second)
+
+	assert.Nil(t, second.Side.Team)
+	assert.Equal(t, "p1-1", second.Side.Pokemon[0].ID)
+}
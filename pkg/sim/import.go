@@ -0,0 +1,314 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+var (
+	// ErrInvalidTeam is returned if a team can't be read
+	ErrInvalidTeam = errors.New("invalid team")
+)
+
+const (
+	// defaults for sets that don't say otherwise, as Showdown has them
+	defaultLevel     = 100
+	defaultHappiness = 255
+)
+
+// ReadTeam reads a team in any format we understand; a JSON list of
+// PokemonSpec, Showdown's packed format or Showdown's export (paste) format.
+func ReadTeam(in string) ([]*PokemonSpec, error) {
+	in = strings.TrimSpace(in)
+	switch {
+	case strings.HasPrefix(in, "["):
+		team := []*PokemonSpec{}
+		err := json.Unmarshal([]byte(in), &team)
+		if err != nil {
+			return nil, fmt.Errorf("%w %v", ErrInvalidTeam, err)
+		}
+		return team, nil
+	case !strings.Contains(in, "\n") && strings.Contains(in, "|"):
+		return UnpackTeam(in)
+	}
+	return ParseTeam(in)
+}
+
+// UnpackTeam reads a team in Showdown's packed format, as written by PackTeam.
+// ie. NICKNAME|SPECIES|ITEM|ABILITY|MOVES|NATURE|EVS|GENDER|IVS|SHINY|LEVEL|HAPPINESS,HPTYPE,POKEBALL,GIGANTAMAX
+func UnpackTeam(in string) ([]*PokemonSpec, error) {
+	team := []*PokemonSpec{}
+	for i, packed := range strings.Split(strings.TrimSpace(in), "]") {
+		spec, err := unpack(packed)
+		if err != nil {
+			return nil, fmt.Errorf("%w pokemon %d: %v", ErrInvalidTeam, i+1, err)
+		}
+		team = append(team, spec)
+	}
+	return team, nil
+}
+
+// unpack reads a single packed pokemon
+func unpack(in string) (*PokemonSpec, error) {
+	bits := strings.Split(in, "|")
+	if len(bits) < 11 {
+		return nil, fmt.Errorf("expected at least 11 fields, got %d", len(bits))
+	}
+
+	spec := &PokemonSpec{
+		Name:      bits[0],
+		Species:   bits[1],
+		Item:      bits[2],
+		Ability:   bits[3],
+		Nature:    bits[5],
+		Gender:    bits[7],
+		Level:     defaultLevel,
+		Happiness: defaultHappiness,
+	}
+	if spec.Species == "" {
+		spec.Species = spec.Name
+	}
+	if bits[4] != "" {
+		spec.Moves = strings.Split(bits[4], ",")
+	}
+
+	ability, err := unpackAbility(spec.Species, spec.Ability)
+	if err != nil {
+		return nil, err
+	}
+	spec.Ability = ability
+
+	// nb. blank EVs are 0 & blank IVs are 31
+	if bits[6] != "" {
+		spec.EffortValues, err = unpackStats(bits[6], 0)
+		if err != nil {
+			return nil, fmt.Errorf("evs: %v", err)
+		}
+	} else {
+		spec.EffortValues = &Stats{}
+	}
+	if bits[8] != "" {
+		spec.IndividualValues, err = unpackStats(bits[8], 31)
+		if err != nil {
+			return nil, fmt.Errorf("ivs: %v", err)
+		}
+	}
+
+	if bits[10] != "" {
+		spec.Level, err = strconv.Atoi(bits[10])
+		if err != nil {
+			return nil, fmt.Errorf("level: %v", err)
+		}
+	}
+
+	if len(bits) > 11 {
+		extra := strings.Split(bits[11], ",")
+		if extra[0] != "" {
+			spec.Happiness, err = strconv.Atoi(extra[0])
+			if err != nil {
+				return nil, fmt.Errorf("happiness: %v", err)
+			}
+		}
+		if len(extra) > 1 {
+			spec.HPType = extra[1]
+		}
+		if len(extra) > 2 {
+			spec.PokeballType = extra[2]
+		}
+		if len(extra) > 3 {
+			spec.GigantaMax = extra[3] == "G"
+		}
+	}
+
+	return spec, nil
+}
+
+// unpackAbility resolves packed ability slots ("0", "1", "H") to names
+func unpackAbility(species, ability string) (string, error) {
+	switch ability {
+	case "0", "1", "H", "S":
+		dex, err := data.Species(species)
+		if err != nil {
+			return "", err
+		}
+		return dex.Abilities[ability], nil
+	}
+	return ability, nil
+}
+
+// unpackStats reads six comma separated values, blanks are given the default
+func unpackStats(in string, def int) (*Stats, error) {
+	bits := strings.Split(in, ",")
+	if len(bits) != len(StatNames) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(StatNames), len(bits))
+	}
+
+	st := &Stats{}
+	for i, b := range bits {
+		v := def
+		if b != "" {
+			var err error
+			v, err = strconv.Atoi(b)
+			if err != nil {
+				return nil, err
+			}
+		}
+		st.Set(StatNames[i], v)
+	}
+	return st, nil
+}
+
+// ParseTeam reads a team in Showdown's export format, as used by the
+// teambuilder's import / export & most team sharing sites. ie.
+//
+//	Nickname (Lucario) (M) @ Life Orb
+//	Ability: Inner Focus
+//	Level: 50
+//	EVs: 252 Atk / 4 SpD / 252 Spe
+//	Jolly Nature
+//	IVs: 0 SpA
+//	- Swords Dance
+//	- Close Combat
+//
+// Pokemon are separated by blank lines.
+func ParseTeam(in string) ([]*PokemonSpec, error) {
+	team := []*PokemonSpec{}
+
+	var spec *PokemonSpec
+	for n, line := range strings.Split(in, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "===") {
+			// blank lines separate pokemon, '=== [gen8] Team ===' separate teams
+			spec = nil
+			continue
+		}
+
+		var err error
+		if spec == nil {
+			spec, err = parseHeader(line)
+			if err == nil {
+				team = append(team, spec)
+			}
+		} else {
+			err = parseLine(spec, line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w line %d: %v", ErrInvalidTeam, n+1, err)
+		}
+	}
+
+	if len(team) == 0 {
+		return nil, fmt.Errorf("%w no pokemon found", ErrInvalidTeam)
+	}
+	return team, nil
+}
+
+// parseHeader reads the first line of an exported pokemon ie.
+// "Nickname (Species) (M) @ Item"
+func parseHeader(line string) (*PokemonSpec, error) {
+	spec := &PokemonSpec{
+		Level:            defaultLevel,
+		Happiness:        defaultHappiness,
+		EffortValues:     &Stats{},
+		IndividualValues: &Stats{31, 31, 31, 31, 31, 31},
+		Moves:            []string{},
+	}
+
+	if i := strings.LastIndex(line, " @ "); i >= 0 {
+		spec.Item = strings.TrimSpace(line[i+3:])
+		line = strings.TrimSpace(line[:i])
+	}
+
+	for _, g := range []string{"M", "F"} {
+		if strings.HasSuffix(line, fmt.Sprintf("(%s)", g)) {
+			spec.Gender = g
+			line = strings.TrimSpace(strings.TrimSuffix(line, fmt.Sprintf("(%s)", g)))
+		}
+	}
+
+	spec.Name = line
+	spec.Species = line
+	if strings.HasSuffix(line, ")") {
+		i := strings.LastIndex(line, "(")
+		if i > 0 {
+			spec.Name = strings.TrimSpace(line[:i])
+			spec.Species = strings.TrimSpace(line[i+1 : len(line)-1])
+		}
+	}
+
+	if spec.Species == "" {
+		return nil, fmt.Errorf("no species given")
+	}
+	return spec, nil
+}
+
+// parseLine reads a line after the header of an exported pokemon
+func parseLine(spec *PokemonSpec, line string) error {
+	if strings.HasPrefix(line, "-") {
+		// moves, nb. "Hidden Power [Fire]" is the move "hiddenpowerfire"
+		move := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+		spec.Moves = append(spec.Moves, data.Strip(move))
+		return nil
+	}
+	if strings.HasSuffix(line, " Nature") {
+		spec.Nature = strings.TrimSuffix(line, " Nature")
+		return nil
+	}
+
+	bits := strings.SplitN(line, ":", 2)
+	if len(bits) != 2 {
+		return fmt.Errorf("unable to parse '%s'", line)
+	}
+	key, value := strings.TrimSpace(bits[0]), strings.TrimSpace(bits[1])
+
+	var err error
+	switch key {
+	case "Ability":
+		spec.Ability = value
+	case "Level":
+		spec.Level, err = strconv.Atoi(value)
+	case "Happiness":
+		spec.Happiness, err = strconv.Atoi(value)
+	case "EVs":
+		err = parseSpread(spec.EffortValues, value)
+	case "IVs":
+		err = parseSpread(spec.IndividualValues, value)
+	case "Hidden Power":
+		spec.HPType = value
+	case "Pokeball":
+		spec.PokeballType = value
+	case "Gigantamax":
+		spec.GigantaMax = value == "Yes"
+	}
+	// anything else (Shiny, Dynamax Level, Tera Type ..) we don't use
+	return err
+}
+
+// parseSpread reads stat values ie. "252 Atk / 4 SpD / 252 Spe"
+func parseSpread(st *Stats, in string) error {
+	for _, part := range strings.Split(in, "/") {
+		bits := strings.Fields(part)
+		if len(bits) != 2 {
+			return fmt.Errorf("unable to parse stat '%s'", part)
+		}
+		v, err := strconv.Atoi(bits[0])
+		if err != nil {
+			return err
+		}
+		stat := strings.ToLower(bits[1])
+		known := false
+		for _, name := range StatNames {
+			known = known || name == stat
+		}
+		if !known {
+			return fmt.Errorf("unknown stat '%s'", bits[1])
+		}
+		st.Set(stat, v)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPaste = `
+=== [gen8] Untitled ===
+
+Luke (Lucario) (M) @ Life Orb
+Ability: Inner Focus
+Level: 50
+EVs: 252 Atk / 4 SpD / 252 Spe
+Jolly Nature
+IVs: 0 SpA
+- Swords Dance
+- Close Combat
+- Extreme Speed
+- Hidden Power [Fire]
+
+Umbreon @ Leftovers
+Ability: Synchronize
+Shiny: Yes
+- Wish
+- Protect
+`
+
+func TestParseTeam(t *testing.T) {
+	team, err := ParseTeam(testPaste)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(team))
+
+	luke := team[0]
+	assert.Equal(t, "Luke", luke.Name)
+	assert.Equal(t, "Lucario", luke.Species)
+	assert.Equal(t, "M", luke.Gender)
+	assert.Equal(t, "Life Orb", luke.Item)
+	assert.Equal(t, "Inner Focus", luke.Ability)
+	assert.Equal(t, 50, luke.Level)
+	assert.Equal(t, "Jolly", luke.Nature)
+	assert.Equal(t, &Stats{0, 252, 0, 0, 4, 252}, luke.EffortValues)
+	assert.Equal(t, &Stats{31, 31, 31, 0, 31, 31}, luke.IndividualValues)
+	assert.Equal(t, []string{"swordsdance", "closecombat", "extremespeed", "hiddenpowerfire"}, luke.Moves)
+
+	umbreon := team[1]
+	assert.Equal(t, "Umbreon", umbreon.Name)
+	assert.Equal(t, "Umbreon", umbreon.Species)
+	assert.Equal(t, 100, umbreon.Level)
+	assert.Equal(t, 255, umbreon.Happiness)
+	assert.Equal(t, &Stats{}, umbreon.EffortValues)
+
+	_, err = PackTeam(team)
+	assert.Nil(t, err)
+}
+
+var dataTestParseTeamErrors = []string{
+	"",
+	"Lucario\nEVs: 252 Atk / 4 Luck",
+	"Lucario\nLevel: fifty",
+	"Lucario\nthis is not a pokemon",
+}
+
+func TestParseTeamErrors(t *testing.T) {
+	for i, in := range dataTestParseTeamErrors {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := ParseTeam(in)
+			assert.ErrorIs(t, err, ErrInvalidTeam)
+		})
+	}
+}
+
+func TestUnpackTeam(t *testing.T) {
+	team, err := ParseTeam(testPaste)
+	assert.Nil(t, err)
+	packed, err := PackTeam(team)
+	assert.Nil(t, err)
+
+	result, err := UnpackTeam(packed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, team, result)
+}
+
+func TestUnpackTeamShowdown(t *testing.T) {
+	// as Showdown packs it, blanks for defaults & ability by slot
+	in := "Luke|Lucario|lifeorb|H|swordsdance,closecombat|Jolly|,252,,,4,252|M|,,,0,,|||]Umbreon||leftovers|0|wish,protect||||||50|"
+
+	result, err := UnpackTeam(in)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "Justified", result[0].Ability)
+	assert.Equal(t, &Stats{0, 252, 0, 0, 4, 252}, result[0].EffortValues)
+	assert.Equal(t, &Stats{31, 31, 31, 0, 31, 31}, result[0].IndividualValues)
+	assert.Equal(t, 100, result[0].Level)
+	assert.Equal(t, "Umbreon", result[1].Species)
+	assert.Equal(t, "Synchronize", result[1].Ability)
+	assert.Equal(t, 50, result[1].Level)
+	assert.Nil(t, result[1].IndividualValues)
+}
+
+func TestReadTeam(t *testing.T) {
+	pasted, err := ReadTeam(testPaste)
+	assert.Nil(t, err)
+
+	packed, err := PackTeam(pasted)
+	assert.Nil(t, err)
+	unpacked, err := ReadTeam(packed + "\n")
+	assert.Nil(t, err)
+	assert.Equal(t, pasted, unpacked)
+
+	fromJSON, err := ReadTeam(`[{"species": "Umbreon", "moves": ["wish"], "level": 50}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, "Umbreon", fromJSON[0].Species)
+
+	_, err = ReadTeam(`[{"species": `)
+	assert.ErrorIs(t, err, ErrInvalidTeam)
+}
@@ -61,6 +61,7 @@ const (
 )
 
 var (
+	// natures is all valid natures
 	natures = []string{
 		NatureAdamant,
 		NatureBashful,
@@ -88,8 +89,54 @@ var (
 		NatureSerious,
 		NatureTimid,
 	}
+
+	// natureEffects maps natures to the stats they raise & lower.
+	// Natures that raise & lower the same stat have no effect.
+	natureEffects = map[string][2]string{
+		NatureAdamant: {"atk", "spa"},
+		NatureBashful: {"spa", "spa"},
+		NatureBold:    {"def", "atk"},
+		NatureBrave:   {"atk", "spe"},
+		NatureCalm:    {"spd", "atk"},
+		NatureCareful: {"spd", "spa"},
+		NatureDocile:  {"def", "def"},
+		NatureGentle:  {"spd", "def"},
+		NatureHardy:   {"atk", "atk"},
+		NatureHasty:   {"spe", "def"},
+		NatureImpish:  {"def", "spa"},
+		NatureJolly:   {"spe", "spa"},
+		NatureLax:     {"def", "spd"},
+		NatureLonely:  {"atk", "def"},
+		NatureMild:    {"spa", "def"},
+		NatureModest:  {"spa", "atk"},
+		NatureNaive:   {"spe", "spd"},
+		NatureNaughty: {"atk", "spd"},
+		NautreQuiet:   {"spa", "spe"},
+		NatureQuirky:  {"spd", "spd"},
+		NatureRash:    {"spa", "spd"},
+		NatureRelaxed: {"def", "spe"},
+		NatureSassy:   {"spd", "spe"},
+		NatureSerious: {"spe", "spe"},
+		NatureTimid:   {"spe", "atk"},
+	}
 )
 
+// NatureModifier returns the multiplier the given nature applies to the
+// given stat (hp, atk, def, spa, spd, spe). Ie. 1.1, 0.9 or 1.
+func NatureModifier(nature, stat string) float64 {
+	effect, ok := natureEffects[strings.ToLower(nature)]
+	if !ok || effect[0] == effect[1] {
+		return 1
+	}
+	switch stat {
+	case effect[0]:
+		return 1.1
+	case effect[1]:
+		return 0.9
+	}
+	return 1
+}
+
 // Natures returns all valid natures
 func Natures() []string {
 	return natures
@@ -1,7 +1,9 @@
 package sim
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
 	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
@@ -47,9 +49,13 @@ type Pokemon struct {
 	// ie. HP/MaxHap status1 status2
 	Condition string
 
-	// Species is the pokemons major species
+	// Species is the pokemons major species (including it's current forme)
 	Species string
 
+	// BaseSpecies is the species this pokemon is a forme of. Nb. this does
+	// not change, even if the pokemon changes forme (mega evolution etc)
+	BaseSpecies string
+
 	// Level is the pokemons current level
 	Level int
 
@@ -62,6 +68,31 @@ type Pokemon struct {
 
 	// Pokedex data
 	Dex *data.PokeDexItem
+
+	// Major is the pokemon's major status condition
+	Major Status
+
+	// Volatiles are temporary conditions (confusion, substitute etc)
+	Volatiles Volatiles
+
+	// ToxicStage is the number of times the pokemon has taken damage from
+	// being badly poisoned (damage is ToxicStage/16 of max HP)
+	ToxicStage int
+
+	// SleepTurns is the number of turns the pokemon has been unable to
+	// move due to sleep
+	SleepTurns int
+
+	// ConfusionTurns is the number of times the pokemon has tried to move
+	// while confused
+	ConfusionTurns int
+
+	// Boosts are the pokemon's stat stages (all zero if not active)
+	Boosts Boosts
+
+	// Nb. the simulator sends requests before the events of the turn that
+	// lead to them, so the fields above are updated as events arrive.
+	// That is, they're up to date once the events of a turn have been read.
 }
 
 // toPokemon builds a final Pokemon from a parsed pokemon struct.
@@ -77,26 +108,84 @@ func toPokemon(in *structs.Pokemon) *Pokemon {
 		moves = append(moves, m)
 	}
 
-	dex, err := data.PokeDex(in.Species)
+	dex, err := data.Species(in.Species)
 	if err != nil {
 		log.Println("failed to find pokemon", in.Species)
 	}
 
+	base := in.Species
+	if dex != nil {
+		base = dex.Base()
+	}
+
 	return &Pokemon{
-		Ident:     in.Ident,
-		Index:     in.Index,
-		Active:    in.Active,
-		Stats:     in.Stats,
-		Moves:     moves,
-		Ability:   in.Ability,
-		Item:      in.Item,
-		Status:    in.Status,
-		Details:   in.Details,
-		Condition: in.Condition,
-		Species:   in.Species,
-		Level:     in.Level,
-		Shiny:     in.Shiny,
-		Gender:    in.Gender,
-		Dex:       dex,
+		Ident:       in.Ident,
+		Index:       in.Index,
+		Active:      in.Active,
+		Stats:       in.Stats,
+		Moves:       moves,
+		Ability:     in.Ability,
+		Item:        in.Item,
+		Status:      in.Status,
+		Details:     in.Details,
+		Condition:   in.Condition,
+		Species:     in.Species,
+		BaseSpecies: base,
+		Level:       in.Level,
+		Shiny:       in.Shiny,
+		Gender:      in.Gender,
+		Dex:         dex,
+		Major:       ParseStatus(in.Condition),
+		Volatiles:   Volatiles{},
+	}
+}
+
+// Spec returns a PokemonSpec for this pokemon.
+// Nb. the simulator doesn't tell us EVs, IVs or natures (only the resulting
+// stats) so these are left unset.
+func (p *Pokemon) Spec() *PokemonSpec {
+	moves := []string{}
+	for _, m := range p.Moves {
+		moves = append(moves, m.ID)
 	}
+
+	name := p.Species
+	bits := strings.SplitN(p.Ident, ": ", 2)
+	if len(bits) == 2 {
+		name = bits[1]
+	}
+
+	return &PokemonSpec{
+		Name:    name,
+		Species: p.Species,
+		Item:    p.Item,
+		Ability: p.Ability,
+		Moves:   moves,
+		Gender:  p.Gender,
+		Level:   p.Level,
+	}
+}
+
+// SetForme changes the pokemon's current forme to the given species,
+// as happens in battle with mega evolution, Aegislash's Stance Change etc.
+// The pokemon's details & pokedex data are updated to match.
+func (p *Pokemon) SetForme(species string) error {
+	dex, err := data.Species(species)
+	if err != nil {
+		return err
+	}
+
+	if p.BaseSpecies != "" && !data.SameSpecies(p.BaseSpecies, dex.Name) {
+		// nb. Zoroark's illusion is the most likely cause of this
+		return fmt.Errorf("pokemon %s cannot change forme to %s", p.BaseSpecies, dex.Name)
+	}
+
+	bits := strings.Split(p.Details, ", ")
+	bits[0] = dex.Name
+	p.Details = strings.Join(bits, ", ")
+	p.Species = dex.Name
+	p.BaseSpecies = dex.Base()
+	p.Dex = dex
+
+	return nil
 }
@@ -36,21 +36,14 @@ func (b *PokemonSpec) enforceLimits() {
 	}
 
 	if b.EffortValues != nil {
-		b.EffortValues.HP = clamp(0, 255, b.EffortValues.HP)
-		b.EffortValues.Attack = clamp(0, 255, b.EffortValues.Attack)
-		b.EffortValues.Defense = clamp(0, 255, b.EffortValues.Defense)
-		b.EffortValues.SpecialAttack = clamp(0, 255, b.EffortValues.SpecialAttack)
-		b.EffortValues.SpecialDefense = clamp(0, 255, b.EffortValues.SpecialDefense)
-		b.EffortValues.Speed = clamp(0, 255, b.EffortValues.Speed)
-
-		if b.EffortValues.Sum() > 510 {
-			b.EffortValues.HP = 85
-			b.EffortValues.Attack = 85
-			b.EffortValues.Defense = 85
-			b.EffortValues.SpecialAttack = 85
-			b.EffortValues.SpecialDefense = 85
-			b.EffortValues.Speed = 85
-		}
+		// nb. we don't fix EVs totalling more than 510 since there's no
+		// good way to guess what was intended; Pack returns an error.
+		b.EffortValues.HP = clamp(0, MaxStatEVs, b.EffortValues.HP)
+		b.EffortValues.Attack = clamp(0, MaxStatEVs, b.EffortValues.Attack)
+		b.EffortValues.Defense = clamp(0, MaxStatEVs, b.EffortValues.Defense)
+		b.EffortValues.SpecialAttack = clamp(0, MaxStatEVs, b.EffortValues.SpecialAttack)
+		b.EffortValues.SpecialDefense = clamp(0, MaxStatEVs, b.EffortValues.SpecialDefense)
+		b.EffortValues.Speed = clamp(0, MaxStatEVs, b.EffortValues.Speed)
 	}
 	if b.IndividualValues != nil {
 		b.IndividualValues.HP = clamp(0, 31, b.IndividualValues.HP)
@@ -96,6 +89,36 @@ func PackTeam(in []*PokemonSpec) (string, error) {
 	return strings.Join(members, "]"), nil
 }
 
+// CopyTeam returns a deep copy of the given team. Packing a team can change
+// it (see Pack) so battles run at the same time should each have their own.
+func CopyTeam(in []*PokemonSpec) []*PokemonSpec {
+	if in == nil {
+		return nil
+	}
+	out := []*PokemonSpec{}
+	for _, p := range in {
+		out = append(out, p.Copy())
+	}
+	return out
+}
+
+// Copy returns a deep copy of the spec
+func (b *PokemonSpec) Copy() *PokemonSpec {
+	cp := *b
+	if b.Moves != nil {
+		cp.Moves = append([]string{}, b.Moves...)
+	}
+	if b.EffortValues != nil {
+		evs := *b.EffortValues
+		cp.EffortValues = &evs
+	}
+	if b.IndividualValues != nil {
+		ivs := *b.IndividualValues
+		cp.IndividualValues = &ivs
+	}
+	return &cp
+}
+
 // Pack a battlemon into the pokemon simulator format
 func (b *PokemonSpec) Pack() (string, error) {
 	// nb. the battle simulator packs things more tightly than we do.
@@ -120,45 +143,60 @@ func (b *PokemonSpec) Pack() (string, error) {
 	}
 
 	// --- check pokemon name / species
-	var dex *data.PokeDexItem
-	sdex, serr := data.PokeDex(b.Species)
-	ndex, nerr := data.PokeDex(b.Name)
-	if serr != nil && nerr != nil {
-		// neither name or species was found
-		return "", fmt.Errorf("no pokemon by species [%v] or name [%v]", serr, nerr)
-	} else if serr == nil && nerr == nil {
-		// both name & species yielded pokemon
-		if sdex.Number != ndex.Number {
-			return "", fmt.Errorf(
-				"name & species indicate different pokemon %s (%s) %s (%s)",
-				b.Species, sdex.Name, b.Name, ndex.Name,
-			)
+	dex, err := b.species()
+	if err != nil {
+		return "", err
+	}
+
+	// --- check forme
+	// Battle only formes (megas, primals, Aegislash-Blade etc) must enter
+	// battle as their regular forme, so we check that the pokemon could
+	// actually transform & then pack it as the forme it starts in.
+	packedSpecies := b.Species
+	if b.Species == b.Name {
+		packedSpecies = ""
+	}
+	ability, gigantamax := b.Ability, b.GigantaMax
+	err = dex.CheckRequirements(b.Item, b.Ability)
+	if err != nil {
+		return "", err
+	}
+	if dex.IsBattleOnly() {
+		if dex.IsGigantamax() {
+			gigantamax = true
+		}
+		battleDex := dex
+
+		dex, err = data.Species(dex.BattleOnly()[0])
+		if err != nil {
+			return "", err
+		}
+		packedSpecies = dex.Name
+
+		if battleDex.RequiredAbility == "" && !hasAbility(dex, ability) {
+			// we've likely been given the ability of the battle forme
+			// (ie. Charizard-Mega-Y with drought) but the pokemon enters
+			// battle with the ability of it's regular forme.
+			ability = ""
 		}
-		dex = sdex
-	} else if serr != nil {
-		// found by species
-		dex = sdex
-	} else {
-		// found by name
-		dex = ndex
 	}
 
 	// --- check pokemon ability
 	found := false
 	for _, ab := range dex.Abilities {
-		if b.Ability == "" {
+		if ability == "" {
 			// if not given, set first ability we see
 			// (order of dict iteration is undefined, so this is random)
-			b.Ability = ab
+			ability = ab
 		}
-		found = data.Strip(b.Ability) == data.Strip(ab)
+		found = data.Strip(ability) == data.Strip(ab)
 		if found {
 			break
 		}
 	}
-	if !found {
+	if !found && data.Strip(ability) != data.Strip(dex.RequiredAbility) {
 		// ability doesn't exist or this pokemon can't have this ability
-		return "", fmt.Errorf("pokemon %s cannot get ability %s", b.Species, b.Ability)
+		return "", fmt.Errorf("pokemon %s cannot get ability %s", b.Species, ability)
 	}
 
 	// --- check pokemon move(s)
@@ -175,6 +213,14 @@ func (b *PokemonSpec) Pack() (string, error) {
 		return "", fmt.Errorf("no nature found matching %s", b.Nature)
 	}
 
+	// --- check EVs
+	if b.EffortValues != nil {
+		err = ValidateEVs(b.EffortValues)
+		if err != nil {
+			return "", fmt.Errorf("%w for %s", err, b.Species)
+		}
+	}
+
 	packedIvs := "31,31,31,31,31,31"
 	if b.IndividualValues != nil {
 		packedIvs = fmt.Sprintf(
@@ -201,17 +247,12 @@ func (b *PokemonSpec) Pack() (string, error) {
 		)
 	}
 
-	packedSpecies := b.Species
-	if b.Species == b.Name {
-		packedSpecies = ""
-	}
-
 	return strings.Join(
 		[]string{
 			b.Name,
 			packedSpecies,
 			b.Item,
-			b.Ability,
+			ability,
 			strings.Join(b.Moves, ","),
 			b.Nature,
 			packedEvs,
@@ -224,13 +265,49 @@ func (b *PokemonSpec) Pack() (string, error) {
 				b.Happiness, // max is 255
 				b.HPType,
 				b.PokeballType,
-				packbool(b.GigantaMax, "G"),
+				packbool(gigantamax, "G"),
 			),
 		},
 		"|",
 	), nil
 }
 
+// species returns the pokedex entry of the pokemon described by our
+// name & species fields. Aliases & cosmetic formes are resolved to their
+// canonical pokedex entry.
+func (b *PokemonSpec) species() (*data.PokeDexItem, error) {
+	sdex, serr := data.Species(b.Species)
+	ndex, nerr := data.Species(b.Name)
+	if serr != nil && nerr != nil {
+		// neither name or species was found
+		return nil, fmt.Errorf("no pokemon by species [%v] or name [%v]", serr, nerr)
+	} else if serr == nil && nerr == nil {
+		// both name & species yielded pokemon
+		if sdex.Name != ndex.Name && !data.SameSpecies(sdex.Name, ndex.Name) {
+			return nil, fmt.Errorf(
+				"name & species indicate different pokemon %s (%s) %s (%s)",
+				b.Species, sdex.Name, b.Name, ndex.Name,
+			)
+		}
+		return sdex, nil
+	} else if serr == nil {
+		// found by species
+		return sdex, nil
+	}
+	// found by name
+	return ndex, nil
+}
+
+// hasAbility returns if the given pokemon can have the given ability
+func hasAbility(dex *data.PokeDexItem, ability string) bool {
+	for _, ab := range dex.Abilities {
+		if data.Strip(ability) == data.Strip(ab) {
+			return true
+		}
+	}
+	return false
+}
+
 // packbool turns a given bool in a blank or the given value
 func packbool(b bool, value string) string {
 	if b {
@@ -2,15 +2,19 @@ package sim
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
 )
 
 func TestEnforceLimits(t *testing.T) {
 	p := PokemonSpec{
 		Moves:            []string{"a", "b", "c", "d", "e", "f"},
-		EffortValues:     &Stats{255, 255, 255, 0, 0, 0},
+		EffortValues:     &Stats{300, 255, -4, 0, 0, 0},
 		IndividualValues: &Stats{39, -15, 31, 31, 31, 31},
 		Gender:           "X",
 		Level:            300,
@@ -20,7 +24,7 @@ func TestEnforceLimits(t *testing.T) {
 	p.enforceLimits()
 
 	assert.Equal(t, []string{"a", "b", "c", "d"}, p.Moves)
-	assert.Equal(t, &Stats{85, 85, 85, 85, 85, 85}, p.EffortValues)
+	assert.Equal(t, &Stats{255, 255, 0, 0, 0, 0}, p.EffortValues)
 	assert.Equal(t, &Stats{31, 0, 31, 31, 31, 31}, p.IndividualValues)
 	assert.Equal(t, "", p.Gender)
 	assert.Equal(t, 100, p.Level)
@@ -79,3 +83,104 @@ func TestSum(t *testing.T) {
 
 	assert.Equal(t, 210, result)
 }
+
+var dataTestPackForme = []struct {
+	Name          string
+	In            *PokemonSpec
+	ExpectSpecies string
+	ExpectAbility string
+	Err           bool
+}{
+	{
+		"cosmetic-forme",
+		&PokemonSpec{Name: "Gastrodon-East", Ability: "stormdrain", Moves: []string{"scald"}},
+		"",
+		"stormdrain",
+		false,
+	},
+	{
+		"mega-with-stone",
+		&PokemonSpec{Name: "Zard", Species: "Charizard-Mega-Y", Item: "charizarditey", Ability: "blaze", Moves: []string{"flamethrower"}},
+		"Charizard",
+		"blaze",
+		false,
+	},
+	{
+		"mega-with-wrong-stone",
+		&PokemonSpec{Name: "Zard", Species: "Charizard-Mega-Y", Item: "charizarditex", Ability: "blaze", Moves: []string{"flamethrower"}},
+		"",
+		"",
+		true,
+	},
+	{
+		"forme-requires-ability",
+		&PokemonSpec{Species: "Aegislash-Blade", Ability: "noguard", Moves: []string{"shadowsneak"}},
+		"",
+		"",
+		true,
+	},
+	{
+		"forme-requires-item",
+		&PokemonSpec{Species: "Giratina-Origin", Item: "leftovers", Ability: "levitate", Moves: []string{"shadowsneak"}},
+		"",
+		"",
+		true,
+	},
+}
+
+func TestPackForme(t *testing.T) {
+	for _, tt := range dataTestPackForme {
+		t.Run(tt.Name, func(t *testing.T) {
+			result, err := tt.In.Pack()
+
+			assert.Equal(t, tt.Err, err != nil)
+			if tt.Err {
+				assert.True(t, errors.Is(err, data.ErrFormeRequirement))
+				return
+			}
+
+			bits := strings.Split(result, "|")
+			assert.Equal(t, tt.ExpectSpecies, bits[1])
+			assert.Equal(t, tt.ExpectAbility, bits[3])
+		})
+	}
+}
+
+func TestPackBattleOnlyAbility(t *testing.T) {
+	// Drought belongs to the mega forme, not Charizard
+	p := &PokemonSpec{Species: "Charizard-Mega-Y", Item: "charizarditey", Ability: "drought", Moves: []string{"flamethrower"}}
+
+	result, err := p.Pack()
+
+	assert.Nil(t, err)
+	assert.Contains(t, []string{"blaze", "solarpower"}, data.Strip(strings.Split(result, "|")[3]))
+	assert.Equal(t, "drought", p.Ability)
+}
+
+func TestPackDoesNotChangeSpec(t *testing.T) {
+	p := &PokemonSpec{Species: "Charizard-Gmax", Level: 50, Moves: []string{"flamethrower"}}
+
+	result, err := p.Pack()
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(result, ",G"))
+	assert.Equal(t, &PokemonSpec{Species: "Charizard-Gmax", Level: 50, Moves: []string{"flamethrower"}}, p)
+}
+
+func TestCopyTeam(t *testing.T) {
+	team := []*PokemonSpec{
+		&PokemonSpec{Species: "Umbreon", Moves: []string{"wish"}, EffortValues: &Stats{HP: 252}},
+		&PokemonSpec{Species: "Lugia"},
+	}
+
+	cp := CopyTeam(team)
+	assert.Equal(t, team, cp)
+
+	cp[0].Moves[0] = "protect"
+	cp[0].EffortValues.HP = 0
+	cp[1].Species = "Ho-Oh"
+	assert.Equal(t, "wish", team[0].Moves[0])
+	assert.Equal(t, 252, team[0].EffortValues.HP)
+	assert.Equal(t, "Lugia", team[1].Species)
+	assert.Nil(t, CopyTeam(nil))
+}
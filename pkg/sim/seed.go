@@ -0,0 +1,90 @@
+package sim
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Seed is the simulator's PRNG seed, four 16 bit parts.
+// The zero value indicates a seed should be chosen at random.
+type Seed [4]uint16
+
+// NewSeed returns a seed made from the given number
+func NewSeed(n uint64) Seed {
+	return Seed{uint16(n >> 48), uint16(n >> 32), uint16(n >> 16), uint16(n)}
+}
+
+// RandomSeed returns a new seed from a cryptographically secure source
+func RandomSeed() Seed {
+	buf := make([]byte, 8)
+	_, err := rand.Read(buf)
+	if err != nil {
+		// this really shouldn't happen
+		panic(err)
+	}
+	return NewSeed(binary.BigEndian.Uint64(buf)).nonZero()
+}
+
+// IsZero returns if the seed is unset
+func (s Seed) IsZero() bool {
+	return s == Seed{}
+}
+
+// Uint64 returns the seed as a single number
+func (s Seed) Uint64() uint64 {
+	return uint64(s[0])<<48 | uint64(s[1])<<32 | uint64(s[2])<<16 | uint64(s[3])
+}
+
+// Derive returns the n-th seed derived from this one.
+// Ie. a tournament can use a single master seed & derive one for each match.
+// Derived seeds are well mixed, so that nearby values of n (or nearby master
+// seeds) do not produce related battles.
+func (s Seed) Derive(n int) Seed {
+	// splitmix64
+	z := s.Uint64() + uint64(n+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z = z ^ (z >> 31)
+	return NewSeed(z).nonZero()
+}
+
+// String returns the seed as the simulator would print it
+func (s Seed) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", s[0], s[1], s[2], s[3])
+}
+
+// ParseSeed reads a seed as given by String ("1,2,3,4") or a single number
+func ParseSeed(in string) (Seed, error) {
+	bits := strings.Split(strings.TrimSpace(in), ",")
+	if len(bits) == 1 {
+		n, err := strconv.ParseUint(bits[0], 10, 64)
+		if err != nil {
+			return Seed{}, fmt.Errorf("invalid seed %s: %v", in, err)
+		}
+		return NewSeed(n), nil
+	}
+	if len(bits) != 4 {
+		return Seed{}, fmt.Errorf("invalid seed %s: expected 4 parts, got %d", in, len(bits))
+	}
+
+	s := Seed{}
+	for i, b := range bits {
+		n, err := strconv.ParseUint(strings.TrimSpace(b), 10, 16)
+		if err != nil {
+			return Seed{}, fmt.Errorf("invalid seed %s: %v", in, err)
+		}
+		s[i] = uint16(n)
+	}
+	return s, nil
+}
+
+// nonZero ensures that a generated seed isn't mistaken for an unset one
+func (s Seed) nonZero() Seed {
+	if s.IsZero() {
+		s[3] = 1
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSeed(t *testing.T) {
+	s := NewSeed(0x0001000200030004)
+
+	assert.Equal(t, Seed{1, 2, 3, 4}, s)
+	assert.Equal(t, uint64(0x0001000200030004), s.Uint64())
+	assert.Equal(t, "1,2,3,4", s.String())
+}
+
+func TestRandomSeed(t *testing.T) {
+	a := RandomSeed()
+	b := RandomSeed()
+
+	assert.False(t, a.IsZero())
+	assert.NotEqual(t, a, b)
+}
+
+func TestDeriveSeed(t *testing.T) {
+	master := Seed{1, 2, 3, 4}
+
+	seen := map[Seed]bool{master: true}
+	for i := 0; i < 1000; i++ {
+		s := master.Derive(i)
+
+		assert.False(t, s.IsZero())
+		assert.False(t, seen[s])
+		assert.Equal(t, s, master.Derive(i))
+		seen[s] = true
+	}
+
+	// nearby masters don't give related seeds
+	assert.NotEqual(t, Seed{1, 2, 3, 5}.Derive(0), master.Derive(1))
+	assert.False(t, Seed{}.Derive(0).IsZero())
+}
+
+var dataTestParseSeed = []struct {
+	In     string
+	Expect Seed
+	Err    bool
+}{
+	{"1,2,3,4", Seed{1, 2, 3, 4}, false},
+	{" 1, 2, 3, 65535 ", Seed{1, 2, 3, 65535}, false},
+	{"281483566841860", Seed{1, 2, 3, 4}, false},
+	{"1,2,3", Seed{}, true},
+	{"1,2,3,65536", Seed{}, true},
+	{"seed", Seed{}, true},
+}
+
+func TestParseSeed(t *testing.T) {
+	for _, tt := range dataTestParseSeed {
+		t.Run(tt.In, func(t *testing.T) {
+			s, err := ParseSeed(tt.In)
+
+			assert.Equal(t, tt.Err, err != nil)
+			assert.Equal(t, tt.Expect, s)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package sim
 
 import (
+	"fmt"
+
 	"github.com/voidshard/poke-showdown-go/pkg/internal/structs"
 )
 
@@ -19,6 +21,10 @@ type Side struct {
 	// Pokemon is the list of pokemon on this player's team.
 	// Order here is important (for "switch" instructions)
 	Pokemon []*Pokemon `json:"pokemon"`
+
+	// Team is the team generated by the simulator in random battles,
+	// given on the player's first side update only
+	Team []*PokemonSpec `json:"team,omitempty"`
 }
 
 // toSide mutates the raw parsed showdown update in to our nicer style
@@ -69,3 +75,29 @@ func toSide(in *structs.Update) *Side {
 
 	return out
 }
+
+// DecodeSide reads a side request as written by showdown (the JSON of a
+// "|request|" line). The side's ID (p1, p2) is used as the Player, since
+// a server gives the user's name.
+func DecodeSide(data []byte) (*Side, error) {
+	update, err := structs.DecodeUpdate(data)
+	if err != nil {
+		return nil, err
+	}
+	if update.Team.ID != "" {
+		update.Team.Player = update.Team.ID
+	}
+	return toSide(update), nil
+}
+
+// teamSpecs returns specs for the given side's pokemon, as they were at the
+// start of the battle. Each is given an ID of the player & team position.
+func teamSpecs(side *Side) []*PokemonSpec {
+	specs := []*PokemonSpec{}
+	for i, p := range side.Pokemon {
+		spec := p.Spec()
+		spec.ID = fmt.Sprintf("%s-%d", side.Player, i+1)
+		specs = append(specs, spec)
+	}
+	return specs
+}
@@ -0,0 +1,26 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSide(t *testing.T) {
+	data := `{"active":[{"moves":[{"move":"U-turn","id":"uturn","pp":32,"maxpp":32,"target":"normal","disabled":false}]}],"side":{"name":"Alice","id":"p2","pokemon":[{"ident":"p2: Liepard","details":"Liepard, L88, M","condition":"256/256","active":true,"stats":{"atk":205,"def":138,"spa":205,"spd":138,"spe":237},"moves":["uturn"],"baseAbility":"prankster","item":"focussash","pokeball":"pokeball","ability":"prankster"}]},"rqid":3}`
+
+	result, err := DecodeSide([]byte(data))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "p2", result.Player)
+	assert.Equal(t, "p2a", result.Field[0].ID)
+	assert.Equal(t, "uturn", result.Field[0].Options.Moves[0].ID)
+	assert.Equal(t, "Liepard", result.Pokemon[0].Species)
+	assert.Equal(t, 256, result.Pokemon[0].Status.HPNow)
+}
+
+func TestDecodeSideInvalid(t *testing.T) {
+	_, err := DecodeSide([]byte(`{"side": nope}`))
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+const (
+	// MaxEVs is the most EVs a pokemon can have in total
+	MaxEVs = 510
+
+	// MaxStatEVs is the most EVs a single stat can have
+	MaxStatEVs = 255
+)
+
+var (
+	// ErrInvalidEVs indicates that a spread of EVs isn't possible
+	ErrInvalidEVs = errors.New("invalid EVs")
+
+	// StatNames are the keys of each stat, in order
+	StatNames = []string{"hp", "atk", "def", "spa", "spd", "spe"}
+
+	// defaults as packed if not given (see PokemonSpec.Pack)
+	defaultEVs = &Stats{85, 85, 85, 85, 85, 85}
+	defaultIVs = &Stats{31, 31, 31, 31, 31, 31}
+)
+
+// Get returns a stat by name (hp, atk, def, spa, spd, spe)
+func (s *Stats) Get(stat string) int {
+	switch stat {
+	case "hp":
+		return s.HP
+	case "atk":
+		return s.Attack
+	case "def":
+		return s.Defense
+	case "spa":
+		return s.SpecialAttack
+	case "spd":
+		return s.SpecialDefense
+	case "spe":
+		return s.Speed
+	}
+	return 0
+}
+
+// Set sets a stat by name (hp, atk, def, spa, spd, spe)
+func (s *Stats) Set(stat string, value int) {
+	switch stat {
+	case "hp":
+		s.HP = value
+	case "atk":
+		s.Attack = value
+	case "def":
+		s.Defense = value
+	case "spa":
+		s.SpecialAttack = value
+	case "spd":
+		s.SpecialDefense = value
+	case "spe":
+		s.Speed = value
+	}
+}
+
+// String returns non zero stats ie. "252 atk / 4 def / 252 spe"
+func (s *Stats) String() string {
+	bits := []string{}
+	for _, stat := range StatNames {
+		if v := s.Get(stat); v != 0 {
+			bits = append(bits, fmt.Sprintf("%d %s", v, stat))
+		}
+	}
+	return strings.Join(bits, " / ")
+}
+
+// ValidateEVs returns an error explaining why the given EVs are not possible
+// (if they aren't).
+func ValidateEVs(evs *Stats) error {
+	for _, stat := range StatNames {
+		v := evs.Get(stat)
+		if v < 0 || v > MaxStatEVs {
+			return fmt.Errorf("%w %s has %d EVs, each stat must have 0-%d", ErrInvalidEVs, stat, v, MaxStatEVs)
+		}
+	}
+	if evs.Sum() > MaxEVs {
+		return fmt.Errorf(
+			"%w total is %d (%s), the most a pokemon can have is %d; remove %d",
+			ErrInvalidEVs, evs.Sum(), evs.String(), MaxEVs, evs.Sum()-MaxEVs,
+		)
+	}
+	return nil
+}
+
+// CalcStats returns a pokemon's stats given it's base stats, IVs, EVs, level
+// & nature.
+func CalcStats(base data.Stats, ivs, evs *Stats, level int, nature string) *Stats {
+	if ivs == nil {
+		ivs = defaultIVs
+	}
+	if evs == nil {
+		evs = defaultEVs
+	}
+
+	bases := &Stats{base.HP, base.Atk, base.Def, base.Spa, base.Spd, base.Spe}
+	out := &Stats{}
+	for _, stat := range StatNames {
+		raw := (2*bases.Get(stat) + ivs.Get(stat) + evs.Get(stat)/4) * level / 100
+		if stat == "hp" {
+			if bases.HP == 1 {
+				// Shedinja
+				out.HP = 1
+			} else {
+				out.HP = raw + level + 10
+			}
+			continue
+		}
+		out.Set(stat, int(float64(raw+5)*NatureModifier(nature, stat)))
+	}
+	return out
+}
+
+// Stats returns the stats this pokemon will have in battle
+func (b *PokemonSpec) Stats() (*Stats, error) {
+	dex, err := b.species()
+	if err != nil {
+		return nil, err
+	}
+	return CalcStats(dex.Stats, b.IndividualValues, b.EffortValues, clamp(1, 100, b.Level), b.Nature), nil
+}
@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dataTestCalcStats = []struct {
+	Name   string
+	In     *PokemonSpec
+	Expect *Stats
+}{
+	{
+		"jolly-garchomp",
+		&PokemonSpec{Species: "Garchomp", Level: 100, Nature: "Jolly", EffortValues: &Stats{Attack: 252, Speed: 252, HP: 4}},
+		&Stats{358, 359, 226, 176, 206, 333},
+	},
+	{
+		"level-50-defaults",
+		&PokemonSpec{Species: "Garchomp", Level: 50},
+		&Stats{194, 161, 126, 111, 116, 133},
+	},
+	{
+		"shedinja",
+		&PokemonSpec{Species: "Shedinja", Level: 100, EffortValues: &Stats{HP: 252}},
+		&Stats{1, 216, 126, 96, 96, 116},
+	},
+}
+
+func TestCalcStats(t *testing.T) {
+	for _, tt := range dataTestCalcStats {
+		t.Run(tt.Name, func(t *testing.T) {
+			result, err := tt.In.Stats()
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expect, result)
+		})
+	}
+}
+
+func TestNatureModifier(t *testing.T) {
+	assert.Equal(t, 1.1, NatureModifier("Adamant", "atk"))
+	assert.Equal(t, 0.9, NatureModifier("adamant", "spa"))
+	assert.Equal(t, 1.0, NatureModifier("adamant", "spe"))
+	assert.Equal(t, 1.0, NatureModifier("serious", "spe"))
+	assert.Equal(t, 1.0, NatureModifier("", "spe"))
+}
+
+func TestValidateEVs(t *testing.T) {
+	assert.Nil(t, ValidateEVs(&Stats{Attack: 252, Speed: 252, HP: 4}))
+
+	err := ValidateEVs(&Stats{Attack: 252, Speed: 252, HP: 252})
+	assert.ErrorIs(t, err, ErrInvalidEVs)
+	assert.Contains(t, err.Error(), "total is 756 (252 hp / 252 atk / 252 spe)")
+	assert.Contains(t, err.Error(), "remove 246")
+
+	err = ValidateEVs(&Stats{Attack: 256})
+	assert.ErrorIs(t, err, ErrInvalidEVs)
+}
+
+func TestPackRejectsEVs(t *testing.T) {
+	p := &PokemonSpec{
+		Species:      "Garchomp",
+		Moves:        []string{"earthquake"},
+		EffortValues: &Stats{255, 255, 255, 0, 0, 0},
+	}
+
+	_, err := p.Pack()
+
+	assert.ErrorIs(t, err, ErrInvalidEVs)
+	assert.Equal(t, &Stats{255, 255, 255, 0, 0, 0}, p.EffortValues)
+}
@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+// Status is a pokemon's major (non volatile) status condition
+type Status string
+
+const (
+	StatusNone      Status = ""
+	StatusBurn      Status = "brn"
+	StatusFreeze    Status = "frz"
+	StatusParalysis Status = "par"
+	StatusPoison    Status = "psn"
+	StatusToxic     Status = "tox"
+	StatusSleep     Status = "slp"
+	StatusFaint     Status = "fnt"
+)
+
+// ParseStatus returns the status from a status ID (ie. "slp") or a
+// pokemon condition string (ie. "30/130 slp")
+func ParseStatus(in string) Status {
+	bits := strings.Fields(in)
+	if len(bits) == 0 {
+		return StatusNone
+	}
+	if bits[0] == "0" {
+		return StatusFaint
+	}
+
+	switch s := Status(bits[len(bits)-1]); s {
+	case StatusBurn, StatusFreeze, StatusParalysis, StatusPoison, StatusToxic, StatusSleep, StatusFaint:
+		return s
+	}
+	return StatusNone
+}
+
+// IsPoisoned returns if the status is either kind of poison
+func (s Status) IsPoisoned() bool {
+	return s == StatusPoison || s == StatusToxic
+}
+
+// Volatiles is a set of volatile conditions by ID (ie. confusion,
+// substitute, leechseed, taunt, encore). Volatiles end when a pokemon
+// switches out, if not before.
+type Volatiles map[string]bool
+
+// Has returns if the given volatile condition is present
+func (v Volatiles) Has(effect string) bool {
+	return v[volatileID(effect)]
+}
+
+// List returns the volatile conditions, sorted
+func (v Volatiles) List() []string {
+	ids := []string{}
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// volatileID returns the ID of a volatile effect as given by the simulator
+// ie. "move: Leech Seed" -> "leechseed"
+func volatileID(effect string) string {
+	bits := strings.SplitN(effect, ": ", 2)
+	return data.Strip(bits[len(bits)-1])
+}
+
+// condition is the status of a pokemon as we track it between updates
+type condition struct {
+	major     Status
+	volatiles Volatiles
+	toxic     int
+	sleep     int
+	confusion int
+	boosts    Boosts
+}
+
+// newCondition returns a condition with no statuses
+func newCondition() *condition {
+	return &condition{volatiles: Volatiles{}}
+}
+
+// setMajor sets the major status, resetting counters if it changes
+func (c *condition) setMajor(s Status) {
+	if s == c.major {
+		return
+	}
+	c.major = s
+	c.toxic = 0
+	c.sleep = 0
+}
+
+// switchOut clears everything that ends when a pokemon leaves the field
+func (c *condition) switchOut() {
+	c.volatiles = Volatiles{}
+	c.toxic = 0
+	c.confusion = 0
+	c.boosts = Boosts{}
+}
+
+// pass hands conditions on to the pokemon replacing this one, for moves
+// that do so (Baton Pass, Shed Tail)
+func (c *condition) pass(to *condition, move string) {
+	switch move {
+	case "batonpass":
+		to.boosts = c.boosts
+		for id := range c.volatiles {
+			if batonPassed[id] {
+				to.volatiles[id] = true
+			}
+		}
+		if c.volatiles["confusion"] {
+			to.confusion = c.confusion
+		}
+	case "shedtail":
+		if c.volatiles["substitute"] {
+			to.volatiles["substitute"] = true
+		}
+	}
+}
+
+// fromEvent updates the condition given some event about the pokemon
+func (c *condition) fromEvent(e *event.Event) {
+	switch p := e.Payload.(type) {
+	case *event.StatusEvent:
+		if p.Cured {
+			c.setMajor(StatusNone)
+		} else {
+			c.setMajor(ParseStatus(p.Status))
+		}
+	case *event.CureTeamEvent:
+		c.setMajor(StatusNone)
+	case *event.FaintEvent:
+		c.setMajor(StatusFaint)
+		c.switchOut()
+	case *event.VolatileEvent:
+		id := volatileID(p.Effect)
+		if p.Ended {
+			delete(c.volatiles, id)
+		} else {
+			c.volatiles[id] = true
+		}
+		if id == "confusion" {
+			c.confusion = 0
+		}
+	case *event.ActivateEvent:
+		if volatileID(p.Effect) == "confusion" {
+			c.confusion++
+		}
+	case *event.CantEvent:
+		if p.Reason == string(StatusSleep) {
+			c.sleep++
+		}
+	case *event.DamageEvent:
+		if c.major == StatusToxic && p.Source != nil && p.Source.Kind == event.CauseStatus && p.Source.Name == string(StatusPoison) {
+			c.toxic++
+		}
+	}
+}
+
+// apply sets the condition on the given pokemon
+func (c *condition) apply(p *Pokemon) {
+	volatiles := Volatiles{}
+	for id := range c.volatiles {
+		volatiles[id] = true
+	}
+
+	p.Major = c.major
+	p.Volatiles = volatiles
+	p.ToxicStage = c.toxic
+	p.SleepTurns = c.sleep
+	p.ConfusionTurns = c.confusion
+	p.Boosts = c.boosts
+}
@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+var dataTestParseStatus = []struct {
+	In     string
+	Expect Status
+}{
+	{"", StatusNone},
+	{"100/100", StatusNone},
+	{"30/130 slp", StatusSleep},
+	{"30/130 tox", StatusToxic},
+	{"0 fnt", StatusFaint},
+	{"0", StatusFaint},
+	{"par", StatusParalysis},
+	{"100/100 xyz", StatusNone},
+}
+
+func TestParseStatus(t *testing.T) {
+	for i, tt := range dataTestParseStatus {
+		t.Run(fmt.Sprintf("%d-%s", i, tt.In), func(t *testing.T) {
+			assert.Equal(t, tt.Expect, ParseStatus(tt.In))
+		})
+	}
+}
+
+func TestVolatilesHas(t *testing.T) {
+	v := Volatiles{"leechseed": true, "substitute": true}
+
+	assert.True(t, v.Has("move: Leech Seed"))
+	assert.True(t, v.Has("Substitute"))
+	assert.False(t, v.Has("confusion"))
+	assert.Equal(t, []string{"leechseed", "substitute"}, v.List())
+}
+
+// feed passes the given event lines through the stream's rosters
+func feed(s *stream, lines ...string) {
+	for _, l := range lines {
+		s.fillIDs(&Update{Event: event.Parse(l)})
+	}
+}
+
+func TestStatusFromEvents(t *testing.T) {
+	s := testStream("a", "b")
+
+	side := testSide(
+		"p1",
+		testPokemon("p1: Umbreon", "Umbreon", "synchronize", true, "wish"),
+		testPokemon("p1: Lugia", "Lugia", "pressure", false, "aeroblast"),
+	)
+	s.fillIDs(&Update{Side: side})
+	umbreon := side.Pokemon[0]
+
+	feed(s,
+		"|switch|p1a: Umbreon|Umbreon, L50|100/100",
+		"|-status|p1a: Umbreon|tox",
+		"|-damage|p1a: Umbreon|90/100 tox|[from] psn",
+		"|-damage|p1a: Umbreon|75/100 tox|[from] psn",
+		"|-start|p1a: Umbreon|move: Leech Seed",
+		"|-start|p1a: Umbreon|Substitute",
+		"|-start|p1a: Umbreon|confusion",
+		"|-activate|p1a: Umbreon|confusion",
+		"|-activate|p1a: Umbreon|confusion",
+		"|-end|p1a: Umbreon|Substitute",
+	)
+
+	// the side update we already handed out is kept up to date
+	assert.Equal(t, StatusToxic, umbreon.Major)
+	assert.Equal(t, 2, umbreon.ToxicStage)
+	assert.Equal(t, 2, umbreon.ConfusionTurns)
+	assert.Equal(t, []string{"confusion", "leechseed"}, umbreon.Volatiles.List())
+
+	// the next side update carries the same state
+	next := testSide(
+		"p1",
+		testPokemon("p1: Umbreon", "Umbreon", "synchronize", true, "wish"),
+		testPokemon("p1: Lugia", "Lugia", "pressure", false, "aeroblast"),
+	)
+	next.Pokemon[0].Condition = "75/100 tox"
+	s.fillIDs(&Update{Side: next})
+	assert.Equal(t, StatusToxic, next.Pokemon[0].Major)
+	assert.Equal(t, 2, next.Pokemon[0].ToxicStage)
+	assert.True(t, next.Pokemon[0].Volatiles.Has("leechseed"))
+
+	// switching out clears volatiles & the toxic counter, but not the status
+	feed(s, "|switch|p1a: Lugia|Lugia, L50|100/100")
+	assert.Equal(t, StatusToxic, next.Pokemon[0].Major)
+	assert.Equal(t, 0, next.Pokemon[0].ToxicStage)
+	assert.Equal(t, 0, next.Pokemon[0].ConfusionTurns)
+	assert.Equal(t, 0, len(next.Pokemon[0].Volatiles))
+}
+
+func TestStatusSleepAndCure(t *testing.T) {
+	s := testStream("a", "b")
+
+	side := testSide(
+		"p2",
+		testPokemon("p2: Snorlax", "Snorlax", "thickfat", true, "rest"),
+		testPokemon("p2: Chansey", "Chansey", "naturalcure", false, "healbell"),
+	)
+	s.fillIDs(&Update{Side: side})
+	snorlax := side.Pokemon[0]
+
+	feed(s,
+		"|-status|p2a: Snorlax|slp|[from] move: Rest",
+		"|cant|p2a: Snorlax|slp",
+		"|cant|p2a: Snorlax|slp",
+	)
+	assert.Equal(t, StatusSleep, snorlax.Major)
+	assert.Equal(t, 2, snorlax.SleepTurns)
+
+	feed(s, "|-curestatus|p2a: Snorlax|slp|[msg]")
+	assert.Equal(t, StatusNone, snorlax.Major)
+	assert.Equal(t, 0, snorlax.SleepTurns)
+
+	// a side update showing a status we missed is believed
+	next := testSide(
+		"p2",
+		testPokemon("p2: Snorlax", "Snorlax", "thickfat", true, "rest"),
+		testPokemon("p2: Chansey", "Chansey", "naturalcure", false, "healbell"),
+	)
+	next.Pokemon[0].Condition = "100/200 par"
+	s.fillIDs(&Update{Side: next})
+	assert.Equal(t, StatusParalysis, next.Pokemon[0].Major)
+
+	feed(s, "|faint|p2a: Snorlax")
+	assert.Equal(t, StatusFaint, next.Pokemon[0].Major)
+}
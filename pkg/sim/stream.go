@@ -1,7 +1,11 @@
 package sim
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"sync"
 
 	"github.com/voidshard/poke-showdown-go/pkg/event"
 	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
@@ -10,34 +14,316 @@ import (
 // stream treats the showdown simulator as an bidirectional event stream
 // (as it is intended).
 type stream struct {
-	proc  *parse.Process
-	out   chan *Update
-	idmap map[string]map[string]string
-	spec  *BattleSpec
+	out     chan *Update
+	rosters map[string]*roster
+	spec    *BattleSpec
+	cfg     *parse.Config
+
+	done chan struct{}
+	once sync.Once
+
+	// wlock serialises writes to the process, it's held while writing so
+	// that choices are kept in the order the process got them
+	wlock sync.Mutex
+
+	// lock guards the process (which is replaced if it's restarted), the
+	// choices given to it & the state of the battle. The state is set
+	// before the process is stopped so that writes don't go to it.
+	// Nb. it's never held while writing to the process, which may block.
+	lock       sync.Mutex
+	proc       *parse.Process
+	choices    []*choice
+	crashed    bool
+	stopped    bool
+	over       bool
+	restarting bool
+
+	// used only by run; the seed to give, how many messages have been
+	// passed on & the number of the last one
+	seed   Seed
+	given  int
+	number int
+}
+
+// choice is a choice given to the simulator by a player
+type choice struct {
+	player string
+	text   string
 }
 
 // Write some battle instruction to the simulator
 func (s *stream) Write(in *Action) error {
-	return s.proc.Write(in.Pack())
+	s.wlock.Lock()
+	defer s.wlock.Unlock()
+
+	s.lock.Lock()
+	err := s.closed()
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+
+	// nb. the choice is kept before it's written, as the simulator may
+	// reject it before we'd otherwise get the chance
+	c := s.keep(in.Player, in.Pack())
+	if s.restarting {
+		// the choice is given to the new process with the others
+		s.lock.Unlock()
+		return nil
+	}
+	proc := s.proc
+	s.lock.Unlock()
+
+	err = proc.Write(c.text)
+	if err == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.restarting || s.proc != proc {
+		// the process crashed & the choice is (or was) replayed
+		return nil
+	}
+	s.forget(c)
+	if closed := s.closed(); closed != nil {
+		// the process was stopped mid write
+		return closed
+	}
+	return err
+}
+
+// closed returns why choices can't be written, if they can't
+func (s *stream) closed() error {
+	switch {
+	case s.crashed:
+		return ErrSimulatorCrashed
+	case s.stopped:
+		return fmt.Errorf("simulator stream is stopped")
+	case s.over:
+		return fmt.Errorf("battle is over")
+	}
+	return nil
+}
+
+// keep records a choice to replay if the simulator is restarted
+func (s *stream) keep(player, text string) *choice {
+	c := &choice{player: player, text: text}
+	if s.spec.Restarts > 0 {
+		s.choices = append(s.choices, c)
+	}
+	return c
+}
+
+// forget removes a choice the simulator didn't get
+func (s *stream) forget(c *choice) {
+	for i := len(s.choices) - 1; i >= 0; i-- {
+		if s.choices[i] == c {
+			s.choices = append(s.choices[:i], s.choices[i+1:]...)
+			return
+		}
+	}
+}
+
+// reject forgets a player's last choice, which the simulator rejected as
+// invalid. Nb. choices that are unavailable are kept, as the simulator
+// reveals why (with a new request) which has to be replayed too.
+func (s *stream) reject(player string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := len(s.choices) - 1; i >= 0; i-- {
+		if s.choices[i].player == player {
+			s.forget(s.choices[i])
+			return
+		}
+	}
 }
 
 // Updates returns an event channel for outgoing updates.
 // These are compressed into one chan because they're strictly in order.
+// The chan is closed when the battle ends, the stream is stopped or the
+// simulator crashes (in which case the last update's Error is
+// ErrSimulatorCrashed).
 func (s *stream) Updates() <-chan *Update {
 	return s.out
 }
 
-// Stop closes the running process(es) & our update chan.
+// Stop kills the running process(es), our update chan is closed once
+// it has exited.
 func (s *stream) Stop() {
-	defer func() {
-		// If the chan is closed (process died/exited) then we're ok with
-		// that (we're in Stop() after all) - we don't want to panic here.
-		if r := recover(); r != nil {
-			log.Println("squashed panic during stop", r)
+	s.once.Do(func() {
+		close(s.done)
+
+		s.lock.Lock()
+		s.stopped = true
+		proc := s.proc
+		s.lock.Unlock()
+
+		proc.Stop()
+	})
+}
+
+// run passes messages from the simulator on as updates until the battle
+// ends, we're stopped or the simulator crashes (more times than we can
+// restart it).
+func (s *stream) run() {
+	defer close(s.out)
+
+	s.lock.Lock()
+	proc := s.proc
+	s.lock.Unlock()
+
+	for restarts := 0; ; restarts++ {
+		crash := s.relay(proc)
+		restart := crash != nil && restarts < s.spec.Restarts
+
+		s.lock.Lock()
+		switch {
+		case crash == nil:
+			s.over = true
+		case restart:
+			s.restarting = true
+		default:
+			s.crashed = true
+		}
+		s.lock.Unlock()
+		end(proc)
+
+		if crash == nil {
+			return
+		}
+		if !restart {
+			s.crash(crash)
+			return
+		}
+
+		log.Printf("restarting simulator (%d/%d): %v\n", restarts+1, s.spec.Restarts, crash)
+		var err error
+		proc, err = s.restart()
+		if err != nil {
+			s.crash(err)
+			return
+		}
+		if proc == nil {
+			// we were stopped
+			return
+		}
+	}
+}
+
+// relay passes messages from a simulator process on as updates until the
+// battle ends, we're stopped or the process crashes, in which case the
+// reason is returned.
+// Messages already passed on (before a restart) are skipped.
+func (s *stream) relay(proc *parse.Process) error {
+	seen := 0
+	for m := range proc.Messages() {
+		if isCrash(m.Error) {
+			return m.Error
+		}
+		if errors.Is(m.Error, parse.ErrInvalidChoice) {
+			// nb. invalid choices aren't replayed, so these are never
+			// given again
+			s.reject(m.Player)
+		} else {
+			seen++
+			if seen <= s.given {
+				continue
+			}
+			s.given++
 		}
-	}()
-	s.proc.Stop()
-	close(s.out)
+
+		delta := toUpdate(m)
+		delta.Seed = s.seed
+		s.seed = Seed{}
+		s.number = delta.Number
+
+		s.fillIDs(delta)
+		select {
+		case s.out <- delta:
+		case <-s.done:
+			return nil
+		}
+
+		if m.Event != nil {
+			if m.Event.Type == event.Win || m.Event.Type == event.Tie {
+				return nil
+			}
+		}
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+		// the simulator shouldn't exit by itself mid battle
+		return fmt.Errorf("%w before the battle ended", parse.ErrExited)
+	}
+}
+
+// end stops a process & waits for it to exit
+func end(proc *parse.Process) {
+	proc.Stop()
+	for range proc.Messages() {
+	}
+}
+
+// restart starts a new simulator process & replays the battle so far.
+// Nb. the simulator is deterministic for a given seed & input, so the new
+// process gives the same messages as the old one (which relay skips).
+// No process is returned if we've been stopped.
+func (s *stream) restart() (*parse.Process, error) {
+	// nb. writes wait for the replay, so they're given to the new process
+	// after it
+	s.wlock.Lock()
+	defer s.wlock.Unlock()
+
+	s.lock.Lock()
+	stopped := s.stopped
+	replay := []string{}
+	for _, c := range s.choices {
+		replay = append(replay, c.text)
+	}
+	s.lock.Unlock()
+	if stopped {
+		return nil, nil
+	}
+
+	proc, err := parse.NewProcess(s.cfg)
+	if err == nil {
+		err = proc.Write(strings.Join(replay, ""))
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.restarting = false
+	if err != nil {
+		s.crashed = true
+		proc.Stop()
+		return nil, err
+	}
+	if s.stopped {
+		proc.Stop()
+		return nil, nil
+	}
+	s.proc = proc
+	return proc, nil
+}
+
+// crash gives ErrSimulatorCrashed (with the reason) as our last update
+func (s *stream) crash(reason error) {
+	select {
+	case s.out <- &Update{Number: s.number + 1, Error: &crashError{reason: reason}}:
+	case <-s.done:
+	}
+}
+
+// isCrash returns if the error means the simulator process has died
+// (or can't be trusted)
+func isCrash(err error) bool {
+	return errors.Is(err, parse.ErrExited) || errors.Is(err, parse.ErrMalformed)
 }
 
 // NewSimulatorStream starts a new event stream & showdown process for the given
@@ -50,7 +336,15 @@ func NewSimulatorStream(spec *BattleSpec) (SimulatorStream, error) {
 
 	counts := []int{}
 	teams := []string{}
-	for _, t := range spec.Players {
+	for i := 0; i < spec.teams(); i++ {
+		if IsRandom(spec.Format) {
+			// nb. an empty team tells the simulator to generate one
+			teams = append(teams, "")
+			counts = append(counts, 0)
+			continue
+		}
+
+		t := spec.Players[i]
 		pstring, err := PackTeam(t)
 		if err != nil {
 			return nil, err
@@ -59,37 +353,30 @@ func NewSimulatorStream(spec *BattleSpec) (SimulatorStream, error) {
 		counts = append(counts, len(t))
 	}
 
-	proc, err := parse.NewProcess(&parse.Config{
+	cfg := &parse.Config{
 		Seed:      spec.Seed,
 		Format:    string(spec.Format),
 		Teams:     teams,
 		TeamSizes: counts,
-	})
+	}
+	proc, err := parse.NewProcess(cfg)
 	if err != nil {
 		proc.Stop()
 		return nil, err
 	}
 
+	// nb. the config is given defaults (including the seed) when
+	// the process starts
 	ss := &stream{
-		proc:  proc,
-		out:   make(chan *Update),
-		idmap: map[string]map[string]string{},
-		spec:  spec,
-	}
-	go func() {
-		for m := range proc.Messages() {
-			delta := toUpdate(m)
-			ss.fillIDs(delta)
-			ss.out <- delta
-
-			if m.Event != nil {
-				if m.Event.Type == event.Win {
-					ss.Stop()
-					return
-				}
-			}
-		}
-	}()
+		out:     make(chan *Update),
+		rosters: map[string]*roster{},
+		spec:    spec,
+		cfg:     cfg,
+		done:    make(chan struct{}),
+		proc:    proc,
+		seed:    Seed(cfg.Seed),
+	}
+	go ss.run()
 
 	return ss, nil
 }
@@ -97,29 +384,48 @@ func NewSimulatorStream(spec *BattleSpec) (SimulatorStream, error) {
 // fillIDs is where we match showdown returned pokemon to IDs
 // that we accept in PokemonSpec
 func (s *stream) fillIDs(u *Update) {
-	if u.Side == nil {
+	if u.Side != nil {
+		r, ok := s.rosters[u.Side.Player]
+		if !ok {
+			// if this is the first side update message then
+			// the order in which we gave pokemon (spec) is
+			// the order if which they're returned
+			var specs []*PokemonSpec
+			pidx := playerIndex(u.Side.Player)
+			if IsRandom(s.spec.Format) {
+				// the simulator has generated the team
+				specs = teamSpecs(u.Side)
+				u.Side.Team = specs
+			} else if pidx < len(s.spec.Players) {
+				specs = s.spec.Players[pidx]
+			}
+			s.rosters[u.Side.Player] = newRoster(u.Side, specs)
+		} else {
+			r.fromSide(u.Side)
+		}
 		return
 	}
 
-	pokes, ok := s.idmap[u.Side.Player]
-	if !ok {
-		// if this is the first side update message then
-		// the order in which we gave pokemon (spec) is
-		// the order if which they're returned
-		pokes = map[string]string{}
-		pidx := playerIndex(u.Side.Player)
+	if u.Event == nil {
+		return
+	}
 
-		for i, p := range u.Side.Pokemon {
-			givenID := s.spec.Players[pidx][i].ID
-			pokes[p.Ident] = givenID
-			p.ID = givenID
+	for _, r := range s.rosters {
+		r.fromEvent(u.Event)
+	}
+	s.boostEvent(u.Event)
+	for _, sub := range append([]*event.Subject{u.Event.Subject}, u.Event.Targets...) {
+		if sub == nil {
+			continue
 		}
-
-		s.idmap[u.Side.Player] = pokes
-	} else {
-		for _, p := range u.Side.Pokemon {
-			givenID, _ := pokes[p.Ident]
-			p.ID = givenID
+		r, ok := s.rosters[sub.Player]
+		if !ok {
+			continue
+		}
+		m := r.resolve(sub)
+		if m != nil {
+			sub.ID = m.ID
+			sub.Index = m.Index + 1
 		}
 	}
 }
@@ -143,6 +449,7 @@ func toUpdate(m *parse.Message) *Update {
 	}
 	if m.Error != nil {
 		u.Error = m.Error
+		u.Player = m.Player
 	}
 	if m.Update != nil {
 		u.Side = toSide(m.Update)
@@ -0,0 +1,209 @@
+package sim
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+// fakeSimulator is a pokemon-showdown where each choice for p1 is a turn,
+// "move win" ends the battle, "move bad" is invalid, "move hang" stops
+// reading choices & "move crash" crashes (on the first run only). Choices
+// are logged to "choices", next to the simulator.
+const fakeSimulator = `#!/bin/sh
+dir=$(dirname "$0")
+turn=0
+while read -r line; do
+	echo "$line" >> "$dir/choices"
+	case "$line" in
+	">p1 move win")
+		printf 'update\n|win|p1\n\n'
+		;;
+	">p1 move bad")
+		printf 'sideupdate\np1\n|error|[Invalid choice] bad move\n\n'
+		;;
+	">p1 move hang")
+		exec sleep 60
+		;;
+	">p1 "*)
+		turn=$((turn+1))
+		printf 'update\n|turn|%d\n\n' "$turn"
+		;;
+	esac
+	if [ "$line" = ">p1 move crash" ] && [ ! -e "$dir/crashed" ]; then
+		touch "$dir/crashed"
+		echo "TypeError: boom" >&2
+		exit 1
+	fi
+done
+`
+
+// useFakeSimulator puts fakeSimulator on our PATH for the test, returning
+// the directory it's in
+func useFakeSimulator(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "fakesim")
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "pokemon-showdown"), []byte(fakeSimulator), 0755)
+	assert.Nil(t, err)
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	t.Cleanup(func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func move(id string) *Action {
+	return &Action{Player: "p1", Specs: []*ActionSpec{&ActionSpec{Type: ActionMove, ID: id}}}
+}
+
+func TestStreamCrash(t *testing.T) {
+	useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random})
+	assert.Nil(t, err)
+	defer s.Stop()
+
+	assert.Nil(t, s.Write(move("tackle")))
+	assert.Nil(t, s.Write(move("crash")))
+
+	result := drain(t, s)
+
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, event.Turn, result[0].Event.Type)
+	assert.Equal(t, event.Turn, result[1].Event.Type)
+	assert.True(t, IsSimulatorCrashed(result[2].Error))
+	assert.Contains(t, result[2].Error.Error(), "TypeError: boom")
+
+	var exit *ExitError
+	assert.True(t, errors.As(result[2].Error, &exit))
+	assert.Equal(t, 1, exit.Code)
+	assert.Equal(t, "TypeError: boom", exit.Stderr)
+	assert.True(t, result[2].Number > result[1].Number)
+
+	assert.Equal(t, ErrSimulatorCrashed, s.Write(move("tackle")))
+}
+
+func TestStreamRestart(t *testing.T) {
+	useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random, Seed: Seed{1, 2, 3, 4}, Restarts: 1})
+	assert.Nil(t, err)
+	defer s.Stop()
+
+	assert.Nil(t, s.Write(move("tackle")))
+	assert.Nil(t, s.Write(move("crash")))
+	assert.Nil(t, s.Write(move("tackle")))
+	assert.Nil(t, s.Write(move("win")))
+
+	result := drain(t, s)
+
+	assert.Equal(t, 4, len(result))
+	assert.Equal(t, Seed{1, 2, 3, 4}, result[0].Seed)
+	for i, u := range result[:3] {
+		assert.Nil(t, u.Error)
+		assert.Equal(t, &event.TurnEvent{Number: i + 1}, u.Event.Payload)
+		assert.NotEqual(t, Seed{1, 2, 3, 4}, result[i+1].Seed)
+	}
+	assert.Equal(t, event.Win, result[3].Event.Type)
+}
+
+func TestStreamRestartSkipsInvalidChoices(t *testing.T) {
+	dir := useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random, Restarts: 1})
+	assert.Nil(t, err)
+	defer s.Stop()
+
+	// nb. we wait for each reply so the rejection is for the right choice
+	result := []*Update{}
+	for _, id := range []string{"tackle", "bad", "crash", "tackle", "win"} {
+		assert.Nil(t, s.Write(move(id)))
+		result = append(result, <-s.Updates())
+	}
+
+	assert.True(t, IsInvalidChoice(result[1].Error))
+	for i, u := range []*Update{result[0], result[2], result[3]} {
+		assert.Nil(t, u.Error)
+		assert.Equal(t, &event.TurnEvent{Number: i + 1}, u.Event.Payload)
+	}
+	assert.Equal(t, event.Win, result[4].Event.Type)
+	assert.Equal(t, 0, len(drain(t, s)))
+
+	choices, err := ioutil.ReadFile(filepath.Join(dir, "choices"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(choices), "move bad"))
+}
+
+func TestStreamStopDuringWrite(t *testing.T) {
+	useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random})
+	assert.Nil(t, err)
+
+	// the simulator stops reading, so writes block once it's stdin is full
+	assert.Nil(t, s.Write(move("hang")))
+	go func() {
+		for s.Write(move("tackle")) == nil {
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stop waited for a blocked write")
+	}
+}
+
+func TestStreamWriteAfterWin(t *testing.T) {
+	useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random})
+	assert.Nil(t, err)
+	defer s.Stop()
+
+	assert.Nil(t, s.Write(move("win")))
+	drain(t, s)
+
+	assert.NotNil(t, s.Write(move("tackle")))
+}
+
+func TestStreamWriteDuringCrash(t *testing.T) {
+	useFakeSimulator(t)
+
+	s, err := NewSimulatorStream(&BattleSpec{Format: FormatGen8Random})
+	assert.Nil(t, err)
+	defer s.Stop()
+
+	// keep writing while the simulator crashes & is stopped
+	written := make(chan error)
+	go func() {
+		for {
+			err := s.Write(move("tackle"))
+			if err != nil {
+				written <- err
+				return
+			}
+		}
+	}()
+	assert.Nil(t, s.Write(move("crash")))
+
+	result := drain(t, s)
+	assert.True(t, IsSimulatorCrashed(result[len(result)-1].Error))
+	assert.Equal(t, ErrSimulatorCrashed, <-written)
+}
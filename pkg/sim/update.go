@@ -10,4 +10,10 @@ type Update struct {
 	Side   *Side
 	Event  *event.Event
 	Error  error
+
+	// Player is who the Error is for, if known (ie. choice errors)
+	Player string
+
+	// Seed is the battle's effective seed, given on the first update only
+	Seed Seed
 }
@@ -0,0 +1,281 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+// ErrSpectator is returned if a spectator's view is written to
+var ErrSpectator = errors.New("spectators can't write actions")
+
+// Views gives players views of a stream that hold only what they would
+// see playing on a server, so bots can't read their opponent's team,
+// moves or exact HP.
+//
+//	views := sim.NewViews(stream)
+//	p1, p2 := views.PlayerView("p1"), views.PlayerView("p2")
+//
+// Views read all updates from the stream, so it mustn't be read from
+// elsewhere; an update read by anything else is never given to the views.
+type Views struct {
+	stream SimulatorStream
+
+	lock    sync.Mutex
+	history []*Update
+	views   map[*view]bool
+	done    bool
+}
+
+// NewViews starts reading updates from the stream for player views, it
+// must be the stream's only reader
+func NewViews(s SimulatorStream) *Views {
+	v := &Views{stream: s, history: []*Update{}, views: map[*view]bool{}}
+	go v.run()
+	return v
+}
+
+// PlayerView returns a stream of what the given player (p1, p2) can see;
+// their own side requests, choice errors & the event stream, where HP is
+// given as a percentage for pokemon that aren't theirs. Use "" for a
+// spectator, who can see only the event stream.
+// Views are given everything from the start of the battle, however
+// late they're made. Writes to a view are always for it's player.
+func (v *Views) PlayerView(player string) SimulatorStream {
+	w := newView(v, player)
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for _, u := range v.history {
+		w.push(u)
+	}
+	if v.done {
+		w.end()
+	} else {
+		v.views[w] = true
+	}
+	return w
+}
+
+// Stop stops the underlying stream
+func (v *Views) Stop() {
+	v.stream.Stop()
+}
+
+// run passes updates to views until the stream ends
+func (v *Views) run() {
+	for u := range v.stream.Updates() {
+		v.lock.Lock()
+		v.history = append(v.history, u)
+		for w := range v.views {
+			w.push(u)
+		}
+		v.lock.Unlock()
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.done = true
+	for w := range v.views {
+		w.end()
+	}
+	v.views = map[*view]bool{}
+}
+
+// remove stops giving updates to a view
+func (v *Views) remove(w *view) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.views, w)
+}
+
+// view is a SimulatorStream of what a single player can see.
+// Updates are queued so that a view that isn't read doesn't hold up
+// the others.
+type view struct {
+	views  *Views
+	player string
+
+	out  chan *Update
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	lock  sync.Mutex
+	queue []*Update
+	ended bool
+}
+
+func newView(v *Views, player string) *view {
+	w := &view{
+		views:  v,
+		player: player,
+		out:    make(chan *Update),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		queue:  []*Update{},
+	}
+	go w.run()
+	return w
+}
+
+// Write an action for our player
+func (w *view) Write(act *Action) error {
+	if w.player == "" {
+		return ErrSpectator
+	}
+	return w.views.stream.Write(&Action{Player: w.player, Specs: act.Specs})
+}
+
+// Updates returns what our player can see, the chan is closed when the
+// stream ends (or the view is stopped)
+func (w *view) Updates() <-chan *Update {
+	return w.out
+}
+
+// Stop closes the view, the stream carries on without it
+func (w *view) Stop() {
+	w.once.Do(func() {
+		w.views.remove(w)
+		close(w.done)
+	})
+}
+
+// push queues an update, if our player can see it
+func (w *view) push(u *Update) {
+	u = visible(u, w.player)
+	if u == nil {
+		return
+	}
+
+	w.lock.Lock()
+	w.queue = append(w.queue, u)
+	w.lock.Unlock()
+	w.notify()
+}
+
+// end tells the view there are no more updates
+func (w *view) end() {
+	w.lock.Lock()
+	w.ended = true
+	w.lock.Unlock()
+	w.notify()
+}
+
+// notify wakes run, if it's waiting
+func (w *view) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run passes queued updates to our chan
+func (w *view) run() {
+	defer close(w.out)
+	for {
+		w.lock.Lock()
+		queue, ended := w.queue, w.ended
+		w.queue = []*Update{}
+		w.lock.Unlock()
+
+		for _, u := range queue {
+			select {
+			case w.out <- u:
+			case <-w.done:
+				return
+			}
+		}
+		if ended && len(queue) == 0 {
+			return
+		}
+		if len(queue) > 0 {
+			// check for more before waiting
+			continue
+		}
+
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// visible returns the update as the given player ("" for a spectator)
+// would see it, or nil if they can't see it at all
+func visible(u *Update, player string) *Update {
+	switch {
+	case u.Side != nil:
+		if u.Side.Player != player {
+			return nil
+		}
+		return &Update{Number: u.Number, Side: u.Side}
+	case u.Error != nil:
+		// nb. the simulator doesn't always say who errors are for
+		if player == "" || (u.Player != "" && u.Player != player) {
+			return nil
+		}
+		return &Update{Number: u.Number, Error: u.Error, Player: u.Player}
+	case u.Event != nil:
+		return &Update{Number: u.Number, Event: publicEvent(u.Event, player)}
+	}
+	// nb. the seed is never given, it'd allow a player to predict the
+	// battle's RNG
+	return nil
+}
+
+// publicEvent returns a copy of the event as the player would see it.
+// Pokemon that aren't the player's have HP given as a percentage & no ID
+// or team index, which would give away their team's order (or who is
+// behind an Illusion).
+func publicEvent(e *event.Event, player string) *event.Event {
+	out := e.Copy()
+	for _, sub := range out.Subjects() {
+		if sub.Player != player {
+			sub.ID = ""
+			sub.Index = 0
+		}
+	}
+
+	if out.Subject == nil || out.Subject.Player == player {
+		return out
+	}
+
+	switch p := out.Payload.(type) {
+	case *event.SwitchEvent:
+		p.HP, p.MaxHP = publicHP(p.HP, p.MaxHP, p.Percent)
+	case *event.DamageEvent:
+		out.Name = publicCondition(e.Name, p.MaxHP, p.Percent)
+		p.HP, p.MaxHP = publicHP(p.HP, p.MaxHP, p.Percent)
+		out.Magnitude = p.HP
+	case *event.HealEvent:
+		out.Name = publicCondition(e.Name, p.MaxHP, p.Percent)
+		p.HP, p.MaxHP = publicHP(p.HP, p.MaxHP, p.Percent)
+		out.Magnitude = p.HP
+	}
+	return out
+}
+
+// publicHP returns HP as a percentage (unless fainted)
+func publicHP(hp, max, pct int) (int, int) {
+	if max <= 0 {
+		return hp, max
+	}
+	return pct, 100
+}
+
+// publicCondition returns a condition (ie. "131/262 brn") with HP
+// as a percentage
+func publicCondition(condition string, max, pct int) string {
+	if max <= 0 {
+		return condition
+	}
+	bits := strings.SplitN(condition, " ", 2)
+	bits[0] = fmt.Sprintf("%d/100", pct)
+	return strings.Join(bits, " ")
+}
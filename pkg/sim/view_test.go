@@ -0,0 +1,210 @@
+package sim
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/internal/parse"
+)
+
+// fakeStream sends whatever the test gives it & records writes
+type fakeStream struct {
+	lock    sync.Mutex
+	updates chan *Update
+	writes  []*Action
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{updates: make(chan *Update, 100)}
+}
+
+func (f *fakeStream) Write(a *Action) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.writes = append(f.writes, a)
+	return nil
+}
+
+func (f *fakeStream) Updates() <-chan *Update {
+	return f.updates
+}
+
+func (f *fakeStream) Stop() {
+	close(f.updates)
+}
+
+// drain returns all updates from a view until it's closed
+func drain(t *testing.T, s SimulatorStream) []*Update {
+	out := []*Update{}
+	for {
+		select {
+		case u, ok := <-s.Updates():
+			if !ok {
+				return out
+			}
+			out = append(out, u)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for view")
+			return out
+		}
+	}
+}
+
+func TestPlayerView(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	p1 := views.PlayerView("p1")
+
+	fake.updates <- &Update{Number: 0, Side: &Side{Player: "p1"}, Seed: Seed{1, 2, 3, 4}}
+	fake.updates <- &Update{Number: 1, Side: &Side{Player: "p2"}}
+	fake.updates <- &Update{Number: 2, Event: event.Parse("|switch|p2a: Umbreon|Umbreon, L50|200/200")}
+	fake.updates <- &Update{Number: 3, Event: event.Parse("|-damage|p2a: Umbreon|131/200 brn|[from] brn")}
+	fake.updates <- &Update{Number: 4, Event: event.Parse("|-damage|p1a: Lugia|150/300")}
+	fake.updates <- &Update{Number: 5, Error: fmt.Errorf("%w [Invalid choice]", parse.ErrInvalidChoice)}
+	fake.updates <- &Update{Number: 6, Error: fmt.Errorf("%w [Invalid choice]", parse.ErrInvalidChoice), Player: "p2"}
+	fake.updates <- &Update{Number: 7, Error: fmt.Errorf("%w [Invalid choice]", parse.ErrInvalidChoice), Player: "p1"}
+	fake.Stop()
+
+	result := drain(t, p1)
+
+	assert.Equal(t, 6, len(result))
+	assert.Equal(t, &Update{Number: 0, Side: &Side{Player: "p1"}}, result[0])
+
+	sw := result[1].Event.Payload.(*event.SwitchEvent)
+	assert.Equal(t, 100, sw.HP)
+	assert.Equal(t, 100, sw.MaxHP)
+
+	dmg := result[2].Event.Payload.(*event.DamageEvent)
+	assert.Equal(t, 66, dmg.HP)
+	assert.Equal(t, 100, dmg.MaxHP)
+	assert.Equal(t, "brn", dmg.Status)
+	assert.Equal(t, "66/100 brn", result[2].Event.Name)
+	assert.Equal(t, 66, result[2].Event.Magnitude)
+
+	// our own pokemon's HP is exact
+	dmg = result[3].Event.Payload.(*event.DamageEvent)
+	assert.Equal(t, 150, dmg.HP)
+	assert.Equal(t, 300, dmg.MaxHP)
+
+	// choice errors for the other player are hidden, where we know who
+	// they're for
+	assert.True(t, IsInvalidChoice(result[4].Error))
+	assert.Equal(t, 7, result[5].Number)
+	assert.Equal(t, "p1", result[5].Player)
+}
+
+func TestPlayerViewUsesPublicHP(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	p1 := views.PlayerView("p1")
+
+	fake.updates <- &Update{Event: event.ParseSplit("|-damage|p2a: Umbreon|131/200", "|-damage|p2a: Umbreon|65/100")}
+	fake.Stop()
+
+	result := drain(t, p1)
+
+	assert.Equal(t, 65, result[0].Event.Payload.(*event.DamageEvent).HP)
+	assert.Equal(t, "65/100", result[0].Event.Name)
+}
+
+func TestPlayerViewDoesNotChangeEvents(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	evt := event.Parse("|-damage|p2a: Umbreon|131/200")
+
+	fake.updates <- &Update{Event: evt}
+	fake.Stop()
+	drain(t, views.PlayerView("p1"))
+
+	assert.Equal(t, 131, evt.Payload.(*event.DamageEvent).HP)
+	assert.Equal(t, "131/200", evt.Name)
+}
+
+func TestPlayerViewHidesIllusion(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	p1, p2 := views.PlayerView("p1"), views.PlayerView("p2")
+
+	// Zoroark (the sixth in p2's team) is disguised as Umbreon, the stream
+	// knows who it really is
+	evt := event.Parse("|move|p2a: Umbreon|Night Daze|p1a: Lugia")
+	evt.Subject.ID, evt.Subject.Index = "zoroark", 6
+	evt.Targets[0].ID, evt.Targets[0].Index = "lugia", 1
+
+	fake.updates <- &Update{Event: evt}
+	fake.Stop()
+
+	seen := drain(t, p1)[0].Event
+	mv := seen.Payload.(*event.MoveEvent)
+	assert.Equal(t, "", seen.Subject.ID)
+	assert.Equal(t, 0, seen.Subject.Index)
+	assert.Equal(t, "", mv.User.ID)
+	assert.Equal(t, 0, mv.User.Index)
+	assert.Equal(t, "Umbreon", mv.User.Nickname)
+	// our own pokemon keep their IDs
+	assert.Equal(t, "lugia", mv.Target.ID)
+	assert.Equal(t, 1, mv.Target.Index)
+
+	seen = drain(t, p2)[0].Event
+	assert.Equal(t, "zoroark", seen.Payload.(*event.MoveEvent).User.ID)
+	assert.Equal(t, "", seen.Targets[0].ID)
+
+	assert.Equal(t, "zoroark", evt.Payload.(*event.MoveEvent).User.ID)
+}
+
+func TestSpectatorView(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+
+	fake.updates <- &Update{Side: &Side{Player: "p1"}}
+	fake.updates <- &Update{Error: fmt.Errorf("%w [Invalid choice]", parse.ErrInvalidChoice)}
+	fake.updates <- &Update{Event: event.Parse("|-heal|p1a: Lugia|300/300|[from] item: Leftovers")}
+	fake.Stop()
+
+	// nb. the view is made after the stream has ended
+	assert.Eventually(t, func() bool {
+		views.lock.Lock()
+		defer views.lock.Unlock()
+		return views.done
+	}, 2*time.Second, time.Millisecond)
+	result := drain(t, views.PlayerView(""))
+
+	assert.Equal(t, 1, len(result))
+	heal := result[0].Event.Payload.(*event.HealEvent)
+	assert.Equal(t, 100, heal.HP)
+	assert.Equal(t, 100, heal.MaxHP)
+}
+
+func TestPlayerViewWrite(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	specs := []*ActionSpec{&ActionSpec{Type: ActionMove, ID: "tackle"}}
+
+	err := views.PlayerView("p2").Write(&Action{Player: "p1", Specs: specs})
+	assert.Nil(t, err)
+
+	err = views.PlayerView("").Write(&Action{Player: "p1", Specs: specs})
+	assert.Equal(t, ErrSpectator, err)
+
+	assert.Equal(t, []*Action{&Action{Player: "p2", Specs: specs}}, fake.writes)
+}
+
+func TestPlayerViewStop(t *testing.T) {
+	fake := newFakeStream()
+	views := NewViews(fake)
+	p1, p2 := views.PlayerView("p1"), views.PlayerView("p2")
+
+	// p1 never reads & stops, p2 shouldn't be held up
+	p1.Stop()
+	for i := 0; i < 10; i++ {
+		fake.updates <- &Update{Event: event.Parse("|turn|1")}
+	}
+	fake.Stop()
+
+	assert.Equal(t, 10, len(drain(t, p2)))
+	assert.Equal(t, 0, len(drain(t, p1)))
+}
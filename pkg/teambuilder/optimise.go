@@ -0,0 +1,197 @@
+package teambuilder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+const (
+	// maxUseful is the most EVs in a stat that have an effect (EVs are
+	// counted in steps of four)
+	maxUseful = 252
+
+	// step is the number of EVs required to raise a stat by one point
+	step = 4
+)
+
+// ErrUnreachable is returned if no EV spread can meet a goal
+var ErrUnreachable = errors.New("goal cannot be reached")
+
+// OutSpeed sets the fewest speed EVs so that the pokemon is faster than the
+// given speed stat. Other EVs are unchanged, a pokemon with no EVs is given
+// none before optimising.
+func OutSpeed(spec *sim.PokemonSpec, speed int) error {
+	evs := evsOf(spec)
+	budget := sim.MaxEVs - (evs.Sum() - evs.Speed)
+
+	best := 0
+	for ev := 0; ev <= maxUseful && ev <= budget; ev += step {
+		trial := *evs
+		trial.Speed = ev
+
+		stats, err := statsWith(spec, &trial)
+		if err != nil {
+			return err
+		}
+		best = stats.Speed
+		if stats.Speed > speed {
+			spec.EffortValues = &trial
+			return nil
+		}
+	}
+
+	reason := fmt.Sprintf("at most %d speed with %d EVs to spare", best, budget)
+	if sim.NatureModifier(spec.Nature, "spe") <= 1 {
+		reason += ", try a nature that raises speed"
+	}
+	return fmt.Errorf("%w %s can't out speed %d: %s", ErrUnreachable, speciesOf(spec), speed, reason)
+}
+
+// Attack is some attack a pokemon might have to survive
+type Attack struct {
+	// Attacker is the pokemon using the move
+	Attacker *sim.PokemonSpec
+
+	// Move is the move used
+	Move string
+
+	// Modifier is applied to damage for anything else (type effectiveness,
+	// items, weather, critical hits etc). Zero means 1.
+	Modifier float64
+}
+
+// Damage returns the least & most damage the given attack can do to the
+// given defender, not including effects of abilities.
+func Damage(atk *Attack, defender *sim.PokemonSpec) (int, int, error) {
+	move, err := data.MoveDex(atk.Move)
+	if err != nil {
+		return 0, 0, err
+	}
+	if move.Category == "Status" || move.Power == 0 {
+		return 0, 0, fmt.Errorf("%s does not do direct damage", move.Name)
+	}
+
+	attacker, err := atk.Attacker.Stats()
+	if err != nil {
+		return 0, 0, err
+	}
+	defence, err := defender.Stats()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	a, d := attacker.Attack, defence.Defense
+	if move.Category == "Special" {
+		a, d = attacker.SpecialAttack, defence.SpecialDefense
+	}
+
+	modifier := atk.Modifier
+	if modifier == 0 {
+		modifier = 1
+	}
+	stab := 1.0
+	dex, err := data.Species(speciesOf(atk.Attacker))
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, t := range dex.Types {
+		if t == move.Type {
+			stab = 1.5
+		}
+	}
+
+	// nb. as packed (see sim.PokemonSpec)
+	level := atk.Attacker.Level
+	if level < 1 {
+		level = 1
+	} else if level > 100 {
+		level = 100
+	}
+	base := (2*level/5+2)*move.Power*a/d/50 + 2
+
+	roll := func(r float64) int {
+		dmg := math.Floor(float64(base) * r)
+		dmg = math.Floor(dmg * stab)
+		return int(math.Floor(dmg * modifier))
+	}
+	return roll(0.85), roll(1), nil
+}
+
+// Survive sets the fewest HP & defensive EVs (defense or special defense,
+// depending on the move) so that the pokemon survives the given attack
+// from full HP, whatever the damage roll.
+// EVs already in HP or the defensive stat are kept as a minimum.
+func Survive(spec *sim.PokemonSpec, atk *Attack) error {
+	move, err := data.MoveDex(atk.Move)
+	if err != nil {
+		return err
+	}
+	stat := "def"
+	if move.Category == "Special" {
+		stat = "spd"
+	}
+
+	evs := evsOf(spec)
+	budget := sim.MaxEVs - (evs.Sum() - evs.HP - evs.Get(stat))
+
+	var found *sim.Stats
+	bestHP, worstDamage := 0, 0
+	for hp := evs.HP; hp <= maxUseful; hp += step {
+		for def := evs.Get(stat); def <= maxUseful && hp+def <= budget; def += step {
+			if found != nil && hp+def >= found.HP+found.Get(stat) {
+				break
+			}
+
+			trial := *evs
+			trial.HP = hp
+			trial.Set(stat, def)
+
+			stats, err := statsWith(spec, &trial)
+			if err != nil {
+				return err
+			}
+
+			withEVs := *spec
+			withEVs.EffortValues = &trial
+			_, dmg, err := Damage(atk, &withEVs)
+			if err != nil {
+				return err
+			}
+			if dmg < stats.HP {
+				found = &trial
+				break
+			}
+			bestHP, worstDamage = stats.HP, dmg
+		}
+	}
+
+	if found == nil {
+		return fmt.Errorf(
+			"%w %s can't survive %s: it does up to %d damage against %d HP with %d EVs to spare",
+			ErrUnreachable, speciesOf(spec), move.Name, worstDamage, bestHP, budget,
+		)
+	}
+
+	spec.EffortValues = found
+	return nil
+}
+
+// evsOf returns a copy of the pokemon's EVs (or no EVs)
+func evsOf(spec *sim.PokemonSpec) *sim.Stats {
+	if spec.EffortValues == nil {
+		return &sim.Stats{}
+	}
+	evs := *spec.EffortValues
+	return &evs
+}
+
+// statsWith returns the pokemon's stats with the given EVs
+func statsWith(spec *sim.PokemonSpec, evs *sim.Stats) (*sim.Stats, error) {
+	cpy := *spec
+	cpy.EffortValues = evs
+	return cpy.Stats()
+}
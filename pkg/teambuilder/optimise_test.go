@@ -0,0 +1,97 @@
+package teambuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+func TestOutSpeed(t *testing.T) {
+	spec := &sim.PokemonSpec{Species: "Garchomp", Level: 100, Nature: "Jolly", EffortValues: &sim.Stats{Attack: 252}}
+
+	err := OutSpeed(spec, 300)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &sim.Stats{Attack: 252, Speed: 136}, spec.EffortValues)
+
+	stats, _ := spec.Stats()
+	assert.Equal(t, 301, stats.Speed)
+}
+
+func TestOutSpeedUnreachable(t *testing.T) {
+	spec := &sim.PokemonSpec{Species: "Garchomp", Level: 100, Nature: "Adamant"}
+
+	err := OutSpeed(spec, 333)
+
+	assert.ErrorIs(t, err, ErrUnreachable)
+	assert.Contains(t, err.Error(), "at most 303 speed")
+	assert.Contains(t, err.Error(), "try a nature that raises speed")
+	assert.Nil(t, spec.EffortValues)
+}
+
+func TestOutSpeedBudget(t *testing.T) {
+	spec := &sim.PokemonSpec{Species: "Garchomp", Level: 100, EffortValues: &sim.Stats{HP: 252, Attack: 252}}
+
+	err := OutSpeed(spec, 250)
+
+	assert.ErrorIs(t, err, ErrUnreachable)
+	assert.Contains(t, err.Error(), "with 6 EVs to spare")
+}
+
+func TestDamage(t *testing.T) {
+	attacker := &sim.PokemonSpec{Species: "Garchomp", Level: 100, Nature: "Jolly", EffortValues: &sim.Stats{Attack: 252}}
+	defender := &sim.PokemonSpec{Species: "Garchomp", Level: 100, EffortValues: &sim.Stats{}}
+
+	// base = floor(floor(42 * 100 * 359 / 226) / 50) + 2 = 135
+	min, max, err := Damage(&Attack{Attacker: attacker, Move: "earthquake"}, defender)
+	assert.Nil(t, err)
+	assert.Equal(t, 171, min)
+	assert.Equal(t, 202, max)
+
+	_, max, err = Damage(&Attack{Attacker: attacker, Move: "dragonclaw", Modifier: 2}, defender)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*int(float64(int(42*80*359/226/50+2))*1.5), max)
+
+	_, _, err = Damage(&Attack{Attacker: attacker, Move: "swordsdance"}, defender)
+	assert.NotNil(t, err)
+}
+
+func TestSurvive(t *testing.T) {
+	attacker := &sim.PokemonSpec{Species: "Garchomp", Level: 100, Nature: "Adamant", EffortValues: &sim.Stats{Attack: 252}}
+	atk := &Attack{Attacker: attacker, Move: "earthquake"}
+	spec := &sim.PokemonSpec{Species: "Tyranitar", Level: 100, Nature: "Impish", EffortValues: &sim.Stats{Attack: 100}}
+
+	err := Survive(spec, atk)
+	assert.Nil(t, err)
+
+	survives := func(evs *sim.Stats) bool {
+		cpy := *spec
+		cpy.EffortValues = evs
+		stats, _ := cpy.Stats()
+		_, dmg, _ := Damage(atk, &cpy)
+		return dmg < stats.HP
+	}
+
+	found := spec.EffortValues
+	assert.Equal(t, 100, found.Attack)
+	assert.True(t, survives(found))
+
+	// no spread using fewer EVs survives
+	for hp := 0; hp <= 252; hp += 4 {
+		for def := 0; hp+def < found.HP+found.Defense; def += 4 {
+			assert.False(t, survives(&sim.Stats{HP: hp, Defense: def, Attack: 100}), "%d hp %d def", hp, def)
+		}
+	}
+}
+
+func TestSurviveUnreachable(t *testing.T) {
+	attacker := &sim.PokemonSpec{Species: "Garchomp", Level: 100, EffortValues: &sim.Stats{Attack: 252}}
+	spec := &sim.PokemonSpec{Species: "Pichu", Level: 5}
+
+	err := Survive(spec, &Attack{Attacker: attacker, Move: "earthquake", Modifier: 2})
+
+	assert.ErrorIs(t, err, ErrUnreachable)
+	assert.Contains(t, err.Error(), "Pichu can't survive Earthquake")
+	assert.Nil(t, spec.EffortValues)
+}
@@ -0,0 +1,103 @@
+// Package teambuilder helps build PokemonSpecs; EV & nature presets for
+// common roles & EV optimisation using stat calculations.
+package teambuilder
+
+import (
+	"errors"
+	"fmt"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Role is some job a pokemon does for a team
+type Role string
+
+const (
+	// PhysicalSweeper maximises attack & speed
+	PhysicalSweeper Role = "physical-sweeper"
+
+	// SpecialSweeper maximises special attack & speed
+	SpecialSweeper Role = "special-sweeper"
+
+	// PhysicalWall maximises HP & defense
+	PhysicalWall Role = "physical-wall"
+
+	// SpecialWall maximises HP & special defense
+	SpecialWall Role = "special-wall"
+
+	// FastPivot maximises HP & speed, for pokemon that switch out with
+	// U-turn, Volt Switch etc.
+	FastPivot Role = "fast-pivot"
+)
+
+var (
+	// ErrUnknownRole is returned for roles we don't have presets for
+	ErrUnknownRole = errors.New("unknown role")
+
+	// presets for each role; the first nature is used for pokemon with a
+	// higher base attack than special attack, the second otherwise
+	presets = map[Role]struct {
+		EVs     sim.Stats
+		Natures [2]string
+	}{
+		PhysicalSweeper: {sim.Stats{HP: 4, Attack: 252, Speed: 252}, [2]string{sim.NatureJolly, sim.NatureJolly}},
+		SpecialSweeper:  {sim.Stats{HP: 4, SpecialAttack: 252, Speed: 252}, [2]string{sim.NatureTimid, sim.NatureTimid}},
+		PhysicalWall:    {sim.Stats{HP: 252, Defense: 252, SpecialDefense: 4}, [2]string{sim.NatureImpish, sim.NatureBold}},
+		SpecialWall:     {sim.Stats{HP: 252, Defense: 4, SpecialDefense: 252}, [2]string{sim.NatureCareful, sim.NatureCalm}},
+		FastPivot:       {sim.Stats{HP: 252, Defense: 4, Speed: 252}, [2]string{sim.NatureJolly, sim.NatureTimid}},
+	}
+)
+
+// Spread is a set of EVs & a nature
+type Spread struct {
+	EVs    *sim.Stats
+	Nature string
+}
+
+// Roles returns all roles with presets
+func Roles() []Role {
+	return []Role{PhysicalSweeper, SpecialSweeper, PhysicalWall, SpecialWall, FastPivot}
+}
+
+// Preset returns the spread for the given role. The species is used to
+// choose between natures that lower attack or special attack, so that
+// we lower whichever the pokemon is less likely to use.
+func Preset(role Role, species string) (*Spread, error) {
+	p, ok := presets[role]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownRole, role)
+	}
+
+	dex, err := data.Species(species)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := p.EVs
+	nature := p.Natures[0]
+	if dex.Stats.Spa > dex.Stats.Atk {
+		nature = p.Natures[1]
+	}
+
+	return &Spread{EVs: &evs, Nature: nature}, nil
+}
+
+// Build sets the EVs & nature of the given pokemon for the given role
+func Build(spec *sim.PokemonSpec, role Role) error {
+	s, err := Preset(role, speciesOf(spec))
+	if err != nil {
+		return err
+	}
+	spec.EffortValues = s.EVs
+	spec.Nature = s.Nature
+	return nil
+}
+
+// speciesOf returns the species (or name) of the given pokemon
+func speciesOf(spec *sim.PokemonSpec) string {
+	if spec.Species != "" {
+		return spec.Species
+	}
+	return spec.Name
+}
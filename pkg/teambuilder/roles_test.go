@@ -0,0 +1,65 @@
+package teambuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var dataTestPreset = []struct {
+	Role    Role
+	Species string
+	Expect  *Spread
+}{
+	{
+		PhysicalSweeper,
+		"Garchomp",
+		&Spread{EVs: &sim.Stats{HP: 4, Attack: 252, Speed: 252}, Nature: sim.NatureJolly},
+	},
+	{
+		SpecialWall,
+		"Blissey",
+		&Spread{EVs: &sim.Stats{HP: 252, Defense: 4, SpecialDefense: 252}, Nature: sim.NatureCalm},
+	},
+	{
+		PhysicalWall,
+		"Skarmory",
+		&Spread{EVs: &sim.Stats{HP: 252, Defense: 252, SpecialDefense: 4}, Nature: sim.NatureImpish},
+	},
+	{
+		FastPivot,
+		"Rotom-Wash",
+		&Spread{EVs: &sim.Stats{HP: 252, Defense: 4, Speed: 252}, Nature: sim.NatureTimid},
+	},
+}
+
+func TestPreset(t *testing.T) {
+	for _, tt := range dataTestPreset {
+		t.Run(string(tt.Role), func(t *testing.T) {
+			result, err := Preset(tt.Role, tt.Species)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expect, result)
+			assert.Nil(t, sim.ValidateEVs(result.EVs))
+		})
+	}
+}
+
+func TestPresetUnknown(t *testing.T) {
+	_, err := Preset("tank", "Garchomp")
+	assert.ErrorIs(t, err, ErrUnknownRole)
+
+	_, err = Preset(PhysicalSweeper, "Digimon")
+	assert.NotNil(t, err)
+}
+
+func TestBuild(t *testing.T) {
+	spec := &sim.PokemonSpec{Species: "Garchomp"}
+
+	err := Build(spec, PhysicalSweeper)
+
+	assert.Nil(t, err)
+	assert.Equal(t, sim.NatureJolly, spec.Nature)
+	assert.Equal(t, &sim.Stats{HP: 4, Attack: 252, Speed: 252}, spec.EffortValues)
+}
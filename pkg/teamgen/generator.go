@@ -0,0 +1,204 @@
+// Package teamgen generates random teams natively (without pokemon-showdown)
+// using the data in pokedata.
+package teamgen
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// ErrNoCandidates is returned if no species match the given constraints
+	ErrNoCandidates = errors.New("no pokemon match constraints")
+
+	// tiers that are never used, unless explicitly asked for
+	notFullyEvolved = map[string]bool{"lc": true, "nfe": true}
+)
+
+// Generator creates random teams
+type Generator struct {
+	lock sync.Mutex
+	rng  *rand.Rand
+	cfg  *opts
+
+	// candidate species (sorted so the generator is deterministic)
+	species []*data.PokeDexItem
+	moves   []*data.MoveDexItem
+}
+
+// New returns a new team generator with the given constraints
+func New(in ...Option) (*Generator, error) {
+	cfg := buildOpts(in)
+	if cfg.Size < 1 || cfg.Size > 6 {
+		return nil, fmt.Errorf("team size must be between 1 and 6, got %d", cfg.Size)
+	}
+	if cfg.Level < 1 || cfg.Level > 100 {
+		return nil, fmt.Errorf("level must be between 1 and 100, got %d", cfg.Level)
+	}
+
+	g := &Generator{
+		rng:     rand.New(rand.NewSource(cfg.Seed)),
+		cfg:     cfg,
+		species: candidates(cfg),
+		moves:   movePool(),
+	}
+	if len(g.species) == 0 {
+		return nil, ErrNoCandidates
+	}
+
+	return g, nil
+}
+
+// Team returns a new random team
+func (g *Generator) Team() ([]*sim.PokemonSpec, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	pool := g.species
+	if g.cfg.Monotype {
+		typ := g.cfg.MonoType
+		if typ == "" {
+			typ = g.randomType()
+		}
+		pool = withType(pool, map[string]bool{typ: true})
+	}
+
+	// species clause; one of each base species
+	order := g.rng.Perm(len(pool))
+	used := map[string]bool{}
+	team := []*sim.PokemonSpec{}
+	for _, i := range order {
+		dex := pool[i]
+		if used[dex.Base()] {
+			continue
+		}
+		used[dex.Base()] = true
+
+		team = append(team, g.pokemon(dex))
+		if len(team) == g.cfg.Size {
+			return team, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w found %d of %d pokemon", ErrNoCandidates, len(team), g.cfg.Size)
+}
+
+// pokemon builds a random set for the given species
+func (g *Generator) pokemon(dex *data.PokeDexItem) *sim.PokemonSpec {
+	physical := dex.Stats.Atk >= dex.Stats.Spa
+	moves := g.chooseMoves(dex, physical)
+
+	return &sim.PokemonSpec{
+		Name:         dex.Name,
+		Species:      dex.Name,
+		Ability:      g.chooseAbility(dex),
+		Item:         g.chooseItem(dex, moves, physical),
+		Moves:        moves,
+		Nature:       nature(dex, physical),
+		EffortValues: spread(dex, physical),
+		Gender:       g.chooseGender(dex),
+		Level:        g.cfg.Level,
+	}
+}
+
+// randomType returns a random type from the candidate species
+func (g *Generator) randomType() string {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, dex := range g.species {
+		for _, t := range dex.Types {
+			t = data.Strip(t)
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types[g.rng.Intn(len(types))]
+}
+
+// chooseAbility returns a random ability the species can have
+func (g *Generator) chooseAbility(dex *data.PokeDexItem) string {
+	if dex.RequiredAbility != "" {
+		return dex.RequiredAbility
+	}
+
+	slots := []string{}
+	for slot := range dex.Abilities {
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return ""
+	}
+	sort.Strings(slots)
+	return dex.Abilities[slots[g.rng.Intn(len(slots))]]
+}
+
+// chooseGender returns a gender according to the species gender ratio
+func (g *Generator) chooseGender(dex *data.PokeDexItem) string {
+	if dex.Gender != "" {
+		return dex.Gender
+	}
+	male, ok := dex.GenderRatio["M"]
+	if !ok {
+		male = 0.5
+	}
+	if g.rng.Float64() < male {
+		return "M"
+	}
+	return "F"
+}
+
+// candidates returns all species matching the given constraints
+func candidates(cfg *opts) []*data.PokeDexItem {
+	found := []*data.PokeDexItem{}
+	for _, name := range data.AllPokemon() {
+		dex, err := data.PokeDex(name)
+		if err != nil || dex.Number <= 0 || dex.IsNonstandard != "" || dex.IsBattleOnly() {
+			continue
+		}
+		if cfg.Banned[data.Strip(dex.Name)] || cfg.Banned[data.Strip(dex.Base())] {
+			continue
+		}
+
+		tier := data.Strip(dex.Tier)
+		if len(cfg.Tiers) > 0 && !cfg.Tiers[tier] {
+			continue
+		} else if len(cfg.Tiers) == 0 && (tier == "" || tier == "illegal" || notFullyEvolved[tier]) {
+			continue
+		}
+
+		found = append(found, dex)
+	}
+
+	if len(cfg.Types) > 0 {
+		found = withType(found, cfg.Types)
+	}
+	if cfg.Monotype && cfg.MonoType != "" {
+		found = withType(found, map[string]bool{cfg.MonoType: true})
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// withType returns species that have at least one of the given types
+func withType(in []*data.PokeDexItem, types map[string]bool) []*data.PokeDexItem {
+	found := []*data.PokeDexItem{}
+	for _, dex := range in {
+		for _, t := range dex.Types {
+			if types[data.Strip(t)] {
+				found = append(found, dex)
+				break
+			}
+		}
+	}
+	return found
+}
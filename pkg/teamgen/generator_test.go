@@ -0,0 +1,195 @@
+package teamgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// speciesTypes returns the stripped types of the given species
+func speciesTypes(t *testing.T, spec *sim.PokemonSpec) []string {
+	dex, err := data.Species(spec.Species)
+	assert.Nil(t, err)
+
+	types := []string{}
+	for _, typ := range dex.Types {
+		types = append(types, data.Strip(typ))
+	}
+	return types
+}
+
+func TestTeamsPack(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		g, err := New(Seed(seed))
+		assert.Nil(t, err)
+
+		team, err := g.Team()
+		assert.Nil(t, err)
+		assert.Equal(t, 6, len(team))
+
+		bases := map[string]bool{}
+		for _, p := range team {
+			_, err := p.Pack()
+			assert.Nil(t, err, p.Species)
+
+			dex, _ := data.Species(p.Species)
+			assert.False(t, bases[dex.Base()], "species clause")
+			bases[dex.Base()] = true
+
+			assert.LessOrEqual(t, p.EffortValues.Sum(), 510)
+			assert.LessOrEqual(t, len(p.Moves), 4)
+		}
+	}
+}
+
+func TestTeamsReproducible(t *testing.T) {
+	a, _ := New(Seed(7))
+	b, _ := New(Seed(7))
+
+	for i := 0; i < 3; i++ {
+		x, err := a.Team()
+		assert.Nil(t, err)
+		y, err := b.Team()
+		assert.Nil(t, err)
+
+		assert.Equal(t, x, y)
+	}
+}
+
+var dataTestConstraints = []struct {
+	Name  string
+	Opts  []Option
+	Check func(*testing.T, *sim.PokemonSpec)
+}{
+	{
+		"tier",
+		[]Option{Tiers("OU")},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			dex, _ := data.Species(p.Species)
+			assert.Equal(t, "OU", dex.Tier)
+		},
+	},
+	{
+		"level",
+		[]Option{Level(50)},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			assert.Equal(t, 50, p.Level)
+		},
+	},
+	{
+		"types",
+		[]Option{Types("Fire", "water")},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			types := speciesTypes(t, p)
+			assert.True(t, contains(types, "fire") || contains(types, "water"), p.Species)
+		},
+	},
+	{
+		"monotype",
+		[]Option{Monotype("Dragon")},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			assert.Contains(t, speciesTypes(t, p), "dragon")
+		},
+	},
+	{
+		"banned",
+		[]Option{Tiers("Uber"), Banned("Zacian", "Eternatus")},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			assert.NotContains(t, []string{"Zacian", "Eternatus"}, p.Species)
+		},
+	},
+	{
+		"learnset",
+		[]Option{Learnset(func(species string) []string {
+			return []string{"tackle", "growl", "earthquake", "Flamethrower"}
+		})},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			for _, m := range p.Moves {
+				assert.Contains(t, []string{"tackle", "growl", "earthquake", "flamethrower"}, m)
+			}
+		},
+	},
+	{
+		"no learnset",
+		[]Option{Learnset(func(species string) []string { return nil })},
+		func(t *testing.T, p *sim.PokemonSpec) {
+			assert.True(t, len(p.Moves) > 0)
+		},
+	},
+}
+
+func TestConstraints(t *testing.T) {
+	for _, tt := range dataTestConstraints {
+		t.Run(tt.Name, func(t *testing.T) {
+			g, err := New(append(tt.Opts, Seed(1))...)
+			assert.Nil(t, err)
+
+			for i := 0; i < 10; i++ {
+				team, err := g.Team()
+				assert.Nil(t, err)
+				for _, p := range team {
+					tt.Check(t, p)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomMonotype(t *testing.T) {
+	g, err := New(Seed(3), Monotype(""))
+	assert.Nil(t, err)
+
+	team, err := g.Team()
+	assert.Nil(t, err)
+
+	shared := speciesTypes(t, team[0])
+	for _, p := range team[1:] {
+		types := speciesTypes(t, p)
+		found := []string{}
+		for _, typ := range shared {
+			if contains(types, typ) {
+				found = append(found, typ)
+			}
+		}
+		shared = found
+	}
+	assert.NotEmpty(t, shared)
+}
+
+func TestNoCandidates(t *testing.T) {
+	_, err := New(Tiers("NotATier"))
+	assert.ErrorIs(t, err, ErrNoCandidates)
+
+	// there aren't six fairy type ubers
+	g, err := New(Tiers("Uber"), Monotype("Fairy"))
+	if err == nil {
+		_, err = g.Team()
+	}
+	assert.ErrorIs(t, err, ErrNoCandidates)
+}
+
+func TestInvalidOptions(t *testing.T) {
+	_, err := New(Size(7))
+	assert.NotNil(t, err)
+
+	_, err = New(Level(0))
+	assert.NotNil(t, err)
+}
+
+func contains(in []string, s string) bool {
+	for _, i := range in {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLearnsetDefault(t *testing.T) {
+	cfg := buildOpts(nil)
+
+	assert.NotNil(t, cfg.Learnset)
+	assert.Nil(t, buildOpts([]Option{Learnset(nil)}).Learnset)
+}
@@ -0,0 +1,120 @@
+package teamgen
+
+import (
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+)
+
+// Option is some option for New
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Seed     int64
+	Size     int
+	Level    int
+	Tiers    map[string]bool
+	Types    map[string]bool
+	Banned   map[string]bool
+	Monotype bool
+	MonoType string
+	Learnset func(species string) []string
+}
+
+// buildOpts turns options into an opts struct
+func buildOpts(in []Option) *opts {
+	cfg := &opts{
+		Size:     6,
+		Level:    100,
+		Tiers:    map[string]bool{},
+		Types:    map[string]bool{},
+		Banned:   map[string]bool{},
+		Learnset: learnset,
+	}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Seed sets the seed for the generator, teams are reproducible for a given
+// seed & set of options.
+func Seed(seed int64) Option {
+	return func(o *opts) {
+		o.Seed = seed
+	}
+}
+
+// Size sets the number of pokemon per team (1-6). Defaults to 6.
+func Size(n int) Option {
+	return func(o *opts) {
+		o.Size = n
+	}
+}
+
+// Level sets the level of all pokemon (1-100). Defaults to 100.
+func Level(lvl int) Option {
+	return func(o *opts) {
+		o.Level = lvl
+	}
+}
+
+// Tiers limits species to those in the given tiers (ie. OU, UU, Uber, LC).
+// By default any tier of fully evolved pokemon may be used.
+func Tiers(tiers ...string) Option {
+	return func(o *opts) {
+		for _, t := range tiers {
+			o.Tiers[data.Strip(t)] = true
+		}
+	}
+}
+
+// Types limits species to those with at least one of the given types
+func Types(types ...string) Option {
+	return func(o *opts) {
+		for _, t := range types {
+			o.Types[data.Strip(t)] = true
+		}
+	}
+}
+
+// Banned excludes the given species (and their formes)
+func Banned(species ...string) Option {
+	return func(o *opts) {
+		for _, s := range species {
+			o.Banned[data.Strip(s)] = true
+		}
+	}
+}
+
+// Monotype requires all pokemon on a team share the given type.
+// If no type is given one is chosen at random for each team.
+func Monotype(typ string) Option {
+	return func(o *opts) {
+		o.Monotype = true
+		o.MonoType = data.Strip(typ)
+	}
+}
+
+// Learnset sets a function returning the move IDs a species can learn.
+// Defaults to the learnsets in pokedata.
+//
+// Species without a learnset (or all species, if given nil) have moves
+// chosen from the movedex by type (same type attacks, coverage & status
+// moves), which may not be legal for the species. The simulator doesn't
+// validate teams, so these are fine for battles but will fail a team
+// validator.
+func Learnset(fn func(species string) []string) Option {
+	return func(o *opts) {
+		o.Learnset = fn
+	}
+}
+
+// learnset returns the moves a species can learn from pokedata, or nil if
+// we've no learnset for it
+func learnset(species string) []string {
+	ids, err := data.Learnset(species)
+	if err != nil {
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,185 @@
+package teamgen
+
+import (
+	"sort"
+
+	data "github.com/voidshard/poke-showdown-go/pkg/pokedata"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// minPower is the weakest attack we'll consider
+const minPower = 60
+
+// movePool returns moves we'd consider giving a pokemon, sorted by ID
+func movePool() []*data.MoveDexItem {
+	ids := data.AllMoves()
+	sort.Strings(ids)
+
+	moves := []*data.MoveDexItem{}
+	for _, id := range ids {
+		m, err := data.MoveDex(id)
+		if err != nil || m.IsNonstandard != "" || m.IsZ != "" || m.IsMax() {
+			continue
+		}
+		if m.Flags["charge"] > 0 || m.Flags["recharge"] > 0 {
+			// ie. solar beam, hyper beam
+			continue
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+// chooseMoves returns up to four moves for the species
+func (g *Generator) chooseMoves(dex *data.PokeDexItem, physical bool) []string {
+	pool := g.moves
+	legal := false
+	if g.cfg.Learnset != nil {
+		ids := g.cfg.Learnset(dex.Name)
+		if len(ids) > 0 {
+			pool = learnable(ids)
+			legal = true
+		}
+	}
+
+	category := "Special"
+	if physical {
+		category = "Physical"
+	}
+
+	stab := []*data.MoveDexItem{}
+	coverage := []*data.MoveDexItem{}
+	status := []*data.MoveDexItem{}
+	for _, m := range pool {
+		switch {
+		case m.Category == "Status":
+			status = append(status, m)
+		case m.Category != category || m.Power < minPower:
+			continue
+		case hasType(dex, m.Type):
+			stab = append(stab, m)
+		case legal || m.Type != "Normal":
+			coverage = append(coverage, m)
+		}
+	}
+
+	chosen := []string{}
+	seen := map[string]bool{}
+	pick := func(from []*data.MoveDexItem, n int) {
+		for _, i := range g.rng.Perm(len(from)) {
+			if n <= 0 || len(chosen) >= 4 {
+				return
+			}
+			id := data.Strip(from[i].Name)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			chosen = append(chosen, id)
+			n--
+		}
+	}
+
+	if dex.RequiredMove != "" {
+		seen[data.Strip(dex.RequiredMove)] = true
+		chosen = append(chosen, data.Strip(dex.RequiredMove))
+	}
+	pick(stab, 2)
+	pick(coverage, 1)
+	pick(status, 1)
+
+	// if any group was short, fill up from anything
+	pick(append(append(append([]*data.MoveDexItem{}, stab...), coverage...), status...), 4)
+	if len(chosen) == 0 {
+		// every pokemon can learn something ..
+		chosen = append(chosen, "struggle")
+	}
+	return chosen
+}
+
+// learnable returns the moves in the given list of IDs
+func learnable(ids []string) []*data.MoveDexItem {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+
+	moves := []*data.MoveDexItem{}
+	for _, id := range sorted {
+		m, err := data.MoveDex(id)
+		if err == nil {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// chooseItem returns an item that suits the given set
+func (g *Generator) chooseItem(dex *data.PokeDexItem, moves []string, physical bool) string {
+	if dex.RequiredItem != "" {
+		return data.Strip(dex.RequiredItem)
+	}
+	if len(dex.RequiredItems) > 0 {
+		return data.Strip(dex.RequiredItems[0])
+	}
+
+	attacker := true
+	for _, id := range moves {
+		m, err := data.MoveDex(id)
+		if err == nil && m.Category == "Status" {
+			attacker = false
+		}
+	}
+
+	items := []string{"leftovers", "lifeorb", "heavydutyboots", "sitrusberry"}
+	if attacker {
+		choice := "choicespecs"
+		if physical {
+			choice = "choiceband"
+		}
+		items = []string{choice, "choicescarf", "assaultvest", "lifeorb"}
+	}
+	return items[g.rng.Intn(len(items))]
+}
+
+// nature returns a nature boosting the species best attacking stat & speed
+// (if fast) or the attacking stat (if slow)
+func nature(dex *data.PokeDexItem, physical bool) string {
+	fast := dex.Stats.Spe >= 70
+	switch {
+	case physical && fast:
+		return "Jolly"
+	case physical:
+		return "Adamant"
+	case fast:
+		return "Timid"
+	}
+	return "Modest"
+}
+
+// spread returns EVs maximising the species best attacking stat & speed
+// (if fast) or HP (if slow)
+func spread(dex *data.PokeDexItem, physical bool) *sim.Stats {
+	evs := &sim.Stats{}
+	if physical {
+		evs.Attack = 252
+	} else {
+		evs.SpecialAttack = 252
+	}
+	if dex.Stats.Spe >= 70 {
+		evs.Speed = 252
+		evs.HP = 4
+	} else {
+		evs.HP = 252
+		evs.Defense = 4
+	}
+	return evs
+}
+
+// hasType returns if the species has the given type
+func hasType(dex *data.PokeDexItem, typ string) bool {
+	for _, t := range dex.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package tournament
+
+import (
+	"math"
+)
+
+const (
+	// DefaultRating is the rating (Elo & Glicko-2) new players start with
+	DefaultRating = 1500.0
+
+	// DefaultDeviation is the Glicko-2 rating deviation new players start with
+	DefaultDeviation = 350.0
+
+	// DefaultVolatility is the Glicko-2 volatility new players start with
+	DefaultVolatility = 0.06
+
+	// glickoScale converts between Glicko & Glicko-2 scales
+	glickoScale = 173.7178
+
+	// tau constrains how much volatility can change
+	tau = 0.5
+
+	// epsilon is the convergence tolerance when finding the new volatility
+	epsilon = 0.000001
+
+	// z95 is the z score for a 95% confidence interval
+	z95 = 1.96
+)
+
+// elo returns the new ratings of a & b after a game where a scored s
+// (1 win, 0.5 tie, 0 loss)
+func elo(a, b, s, k float64) (float64, float64) {
+	expect := 1 / (1 + math.Pow(10, (b-a)/400))
+	return a + k*(s-expect), b - k*(s-expect)
+}
+
+// glicko is a player's Glicko-2 rating
+type glicko struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+// newGlicko returns a rating for a new player
+func newGlicko() *glicko {
+	return &glicko{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// outcome is a single game in a rating period
+type outcome struct {
+	opponent *glicko
+	score    float64
+}
+
+// update returns the rating after a rating period with the given outcomes,
+// following Glickman's "Example of the Glicko-2 system".
+// Nb. opponents ratings must be from the start of the period.
+func (g *glicko) update(games []*outcome) *glicko {
+	phi := g.Deviation / glickoScale
+	if len(games) == 0 {
+		// the player didn't play; only their deviation increases
+		phi = math.Sqrt(phi*phi + g.Volatility*g.Volatility)
+		return &glicko{Rating: g.Rating, Deviation: phi * glickoScale, Volatility: g.Volatility}
+	}
+
+	mu := (g.Rating - DefaultRating) / glickoScale
+
+	v := 0.0
+	delta := 0.0
+	for _, o := range games {
+		muj := (o.opponent.Rating - DefaultRating) / glickoScale
+		gj := gphi(o.opponent.Deviation / glickoScale)
+		e := 1 / (1 + math.Exp(-gj*(mu-muj)))
+		v += gj * gj * e * (1 - e)
+		delta += gj * (o.score - e)
+	}
+	v = 1 / v
+	delta *= v
+
+	sigma := newVolatility(phi, g.Volatility, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muNew := mu + phiNew*phiNew*delta/v
+
+	return &glicko{
+		Rating:     muNew*glickoScale + DefaultRating,
+		Deviation:  phiNew * glickoScale,
+		Volatility: sigma,
+	}
+}
+
+// interval returns the 95% confidence interval of the rating
+func (g *glicko) interval() (float64, float64) {
+	return g.Rating - z95*g.Deviation, g.Rating + z95*g.Deviation
+}
+
+// gphi is the g function of Glicko-2
+func gphi(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// newVolatility finds the new volatility by the Illinois algorithm
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		num := ex * (delta*delta - phi*phi - v - ex)
+		den := 2 * math.Pow(phi*phi+v+ex, 2)
+		return num/den - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
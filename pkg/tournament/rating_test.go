@@ -0,0 +1,47 @@
+package tournament
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElo(t *testing.T) {
+	a, b := elo(1500, 1500, 1, 32)
+	assert.InDelta(t, 1516, a, 0.001)
+	assert.InDelta(t, 1484, b, 0.001)
+
+	// a tie against a stronger player gains rating
+	a, b = elo(1400, 1600, 0.5, 32)
+	assert.InDelta(t, 1408.31, a, 0.01)
+	assert.InDelta(t, 1591.69, b, 0.01)
+}
+
+func TestGlickoUpdate(t *testing.T) {
+	// the worked example from Glickman's "Example of the Glicko-2 system"
+	player := &glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	result := player.update([]*outcome{
+		&outcome{opponent: &glicko{Rating: 1400, Deviation: 30}, score: 1},
+		&outcome{opponent: &glicko{Rating: 1550, Deviation: 100}, score: 0},
+		&outcome{opponent: &glicko{Rating: 1700, Deviation: 300}, score: 0},
+	})
+
+	assert.InDelta(t, 1464.06, result.Rating, 0.01)
+	assert.InDelta(t, 151.52, result.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, result.Volatility, 0.00001)
+
+	low, high := result.interval()
+	assert.InDelta(t, 1464.06-1.96*151.52, low, 0.1)
+	assert.InDelta(t, 1464.06+1.96*151.52, high, 0.1)
+}
+
+func TestGlickoNoGames(t *testing.T) {
+	player := &glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	result := player.update(nil)
+
+	assert.Equal(t, 1500.0, result.Rating)
+	assert.InDelta(t, 200.27, result.Deviation, 0.01)
+	assert.Equal(t, 0.06, result.Volatility)
+}
@@ -0,0 +1,130 @@
+package tournament
+
+// maxPairingSteps bounds the search for pairings without rematches. Once
+// rematches can't be avoided (in later Swiss rounds) a full search would
+// take exponential time.
+const maxPairingSteps = 10000
+
+// Pairing is how players are matched up each round
+type Pairing string
+
+const (
+	// RoundRobin has every player meet every other player
+	RoundRobin Pairing = "round-robin"
+
+	// Swiss pairs players with similar scores each round, without rematches
+	// (where possible)
+	Swiss Pairing = "swiss"
+)
+
+// roundRobin returns the pairings for each round by the circle method, such
+// that everyone plays at most once per round
+func roundRobin(names []string) [][][2]string {
+	list := append([]string{}, names...)
+	if len(list)%2 == 1 {
+		// whoever is paired with "" sits the round out
+		list = append(list, "")
+	}
+	n := len(list)
+
+	rounds := [][][2]string{}
+	for r := 0; r < n-1; r++ {
+		pairs := [][2]string{}
+		for i := 0; i < n/2; i++ {
+			a, b := list[i], list[n-1-i]
+			if a == "" || b == "" {
+				continue
+			}
+			if r%2 == 1 && i == 0 {
+				// the first player never moves, so swap sides every other round
+				a, b = b, a
+			}
+			pairs = append(pairs, [2]string{a, b})
+		}
+		rounds = append(rounds, pairs)
+
+		// rotate everyone but the first player
+		last := list[n-1]
+		copy(list[2:], list[1:n-1])
+		list[1] = last
+	}
+
+	return rounds
+}
+
+// swiss pairs players given in order of standing (best first) with the
+// nearest player they haven't met. If there is an odd number of players the
+// lowest placed player without a bye sits out & is returned.
+func swiss(order []string, met map[string]map[string]bool, byes map[string]bool) ([][2]string, string) {
+	players := append([]string{}, order...)
+
+	bye := ""
+	if len(players)%2 == 1 {
+		idx := len(players) - 1
+		for i := len(players) - 1; i >= 0; i-- {
+			if !byes[players[i]] {
+				idx = i
+				break
+			}
+		}
+		bye = players[idx]
+		players = append(players[:idx], players[idx+1:]...)
+	}
+
+	steps := 0
+	pairs, ok := pairUnmet(players, met, &steps)
+	if !ok {
+		// rematches can't be avoided (or we couldn't find a way to
+		// avoid them in time)
+		pairs = pairNearest(players, met)
+	}
+	return pairs, bye
+}
+
+// pairUnmet pairs the players (in order) so that no one meets someone they
+// have already met, if possible. The search gives up after maxPairingSteps.
+func pairUnmet(players []string, met map[string]map[string]bool, steps *int) ([][2]string, bool) {
+	if len(players) == 0 {
+		return [][2]string{}, true
+	}
+
+	first := players[0]
+	for i := 1; i < len(players); i++ {
+		if met[first][players[i]] {
+			continue
+		}
+		*steps++
+		if *steps > maxPairingSteps {
+			return nil, false
+		}
+
+		rest := []string{}
+		rest = append(rest, players[1:i]...)
+		rest = append(rest, players[i+1:]...)
+
+		pairs, ok := pairUnmet(rest, met, steps)
+		if ok {
+			return append([][2]string{{first, players[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// pairNearest pairs the players (in order) with the nearest player they
+// haven't met, or the next player if they've met everyone left
+func pairNearest(players []string, met map[string]map[string]bool) [][2]string {
+	left := append([]string{}, players...)
+	pairs := [][2]string{}
+	for len(left) > 1 {
+		idx := 1
+		for i := 1; i < len(left); i++ {
+			if !met[left[0]][left[i]] {
+				idx = i
+				break
+			}
+		}
+		pairs = append(pairs, [2]string{left[0], left[idx]})
+		left = append(left[1:idx], left[idx+1:]...)
+	}
+	return pairs
+}
@@ -0,0 +1,130 @@
+package tournament
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobin(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 8} {
+		t.Run(fmt.Sprintf("%d", n), func(t *testing.T) {
+			names := []string{}
+			for i := 0; i < n; i++ {
+				names = append(names, fmt.Sprintf("bot-%d", i))
+			}
+
+			rounds := roundRobin(names)
+
+			met := map[string]int{}
+			for _, pairs := range rounds {
+				seen := map[string]bool{}
+				for _, p := range pairs {
+					// no one plays twice in a round
+					assert.False(t, seen[p[0]])
+					assert.False(t, seen[p[1]])
+					seen[p[0]], seen[p[1]] = true, true
+
+					a, b := p[0], p[1]
+					if a > b {
+						a, b = b, a
+					}
+					met[a+"/"+b]++
+				}
+			}
+
+			// everyone meets everyone else exactly once
+			assert.Equal(t, n*(n-1)/2, len(met))
+			for _, count := range met {
+				assert.Equal(t, 1, count)
+			}
+		})
+	}
+}
+
+func TestSwissAvoidsRematches(t *testing.T) {
+	order := []string{"a", "b", "c", "d"}
+	met := map[string]map[string]bool{
+		"a": {"b": true},
+		"b": {"a": true},
+		"c": {"d": true},
+		"d": {"c": true},
+	}
+
+	pairs, bye := swiss(order, met, map[string]bool{})
+
+	assert.Equal(t, "", bye)
+	assert.Equal(t, [][2]string{{"a", "c"}, {"b", "d"}}, pairs)
+}
+
+func TestSwissBacktracks(t *testing.T) {
+	// pairing a-c greedily leaves b & d, who have met
+	order := []string{"a", "b", "c", "d"}
+	met := map[string]map[string]bool{
+		"a": {"b": true},
+		"b": {"a": true, "d": true},
+		"d": {"b": true},
+	}
+
+	pairs, _ := swiss(order, met, map[string]bool{})
+
+	assert.Equal(t, [][2]string{{"a", "d"}, {"b", "c"}}, pairs)
+}
+
+func TestSwissBye(t *testing.T) {
+	order := []string{"a", "b", "c"}
+
+	pairs, bye := swiss(order, map[string]map[string]bool{}, map[string]bool{"c": true})
+
+	assert.Equal(t, "b", bye)
+	assert.Equal(t, [][2]string{{"a", "c"}}, pairs)
+}
+
+func TestSwissEveryoneMet(t *testing.T) {
+	order := []string{"a", "b"}
+	met := map[string]map[string]bool{"a": {"b": true}, "b": {"a": true}}
+
+	pairs, _ := swiss(order, met, map[string]bool{})
+
+	assert.Equal(t, [][2]string{{"a", "b"}}, pairs)
+}
+
+func TestSwissUnavoidableRematch(t *testing.T) {
+	// the last player has met everyone, so every full search fails
+	order := []string{}
+	for i := 0; i < 40; i++ {
+		order = append(order, fmt.Sprintf("p%d", i))
+	}
+	last := order[len(order)-1]
+	met := map[string]map[string]bool{last: {}}
+	for _, name := range order[:len(order)-1] {
+		met[name] = map[string]bool{last: true}
+		met[last][name] = true
+	}
+
+	done := make(chan [][2]string)
+	go func() {
+		pairs, _ := swiss(order, met, map[string]bool{})
+		done <- pairs
+	}()
+
+	var pairs [][2]string
+	select {
+	case pairs = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out pairing players")
+	}
+
+	seen := map[string]bool{}
+	rematches := 0
+	for _, pair := range pairs {
+		seen[pair[0]], seen[pair[1]] = true, true
+		if met[pair[0]][pair[1]] {
+			rematches++
+		}
+	}
+	assert.Equal(t, 40, len(seen))
+	assert.Equal(t, 1, rematches)
+}
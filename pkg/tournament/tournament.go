@@ -0,0 +1,621 @@
+/*
+Package tournament runs round robin or Swiss tournaments between players,
+rating them with Elo & Glicko-2.
+*/
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/voidshard/poke-showdown-go/pkg/match"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+var (
+	// indirection allows for easier testing
+	play = match.Play
+
+	// ErrInvalidEntrants is returned if the entrants can't hold a tournament
+	ErrInvalidEntrants = errors.New("invalid entrants")
+
+	// ErrMismatch is returned if a results file is for some other tournament
+	ErrMismatch = errors.New("results file is for a different tournament")
+)
+
+// Entrant is a player registered in a tournament
+type Entrant struct {
+	// Name of the entrant, must be unique
+	Name string
+
+	// Team the entrant uses, not required for random battle formats
+	Team []*sim.PokemonSpec
+
+	// New returns a player for a single battle, given a seed for any
+	// randomness it needs
+	New func(seed int64) player.Player
+}
+
+// Game is a single battle in a tournament
+type Game struct {
+	Round int `json:"round"`
+
+	// P1 & P2 are the entrants playing, P2 is empty for a bye
+	P1 string `json:"p1"`
+	P2 string `json:"p2,omitempty"`
+
+	Seed sim.Seed `json:"seed"`
+
+	// Done is true once the game has been played. Games that failed (see
+	// Error) or ended without a winner or tie are played again when the
+	// tournament is resumed.
+	Done   bool   `json:"done"`
+	Winner string `json:"winner,omitempty"`
+	Tie    bool   `json:"tie,omitempty"`
+	Turns  int    `json:"turns,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// finished returns if the game has been played to a result; a winner or
+// a tie (byes are always finished)
+func (g *Game) finished() bool {
+	return g.Done && g.Error == "" && (g.P2 == "" || g.Tie || g.Winner != "")
+}
+
+// rated returns if the game counts towards ratings
+func (g *Game) rated() bool {
+	return g.finished() && g.P2 != ""
+}
+
+// score returns the score of P1 (1 win, 0.5 tie, 0 loss).
+// Nb. only finished games have a score.
+func (g *Game) score() float64 {
+	switch {
+	case g.Tie:
+		return 0.5
+	case g.Winner == g.P1:
+		return 1
+	}
+	return 0
+}
+
+// Results is everything about a tournament, as saved to file
+type Results struct {
+	Format   sim.Format `json:"format"`
+	Pairing  Pairing    `json:"pairing"`
+	Seed     sim.Seed   `json:"seed"`
+	Entrants []string   `json:"entrants"`
+	Games    []*Game    `json:"games"`
+}
+
+// Standing is an entrant's record & ratings
+type Standing struct {
+	Name   string  `json:"name"`
+	Played int     `json:"played"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Ties   int     `json:"ties"`
+	Byes   int     `json:"byes"`
+	Points float64 `json:"points"`
+
+	// Elo rating
+	Elo float64 `json:"elo"`
+
+	// Glicko-2 rating, deviation & volatility
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+
+	// Low & High are the 95% confidence interval of the Glicko-2 rating
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Option is some option for New
+type Option func(*opts)
+
+// opts is our function inputs
+type opts struct {
+	Pairing     Pairing
+	Seed        sim.Seed
+	Games       int
+	Rounds      int
+	Concurrency int
+	File        string
+	K           float64
+	Match       []match.Option
+}
+
+func buildOpts(in []Option) *opts {
+	cfg := &opts{Pairing: RoundRobin, Games: 2, Concurrency: runtime.NumCPU(), K: 32}
+	for _, o := range in {
+		o(cfg)
+	}
+	return cfg
+}
+
+// Schedule sets how players are paired. Defaults to RoundRobin.
+func Schedule(p Pairing) Option {
+	return func(o *opts) {
+		o.Pairing = p
+	}
+}
+
+// Seed sets the master seed, from which every game's seed is derived.
+// Defaults to a random seed (or the seed in the results file if resuming).
+func Seed(s sim.Seed) Option {
+	return func(o *opts) {
+		o.Seed = s
+	}
+}
+
+// Games sets how many games each pairing plays, alternating sides.
+// Defaults to 2.
+func Games(n int) Option {
+	return func(o *opts) {
+		o.Games = n
+	}
+}
+
+// Rounds sets the number of Swiss rounds. Defaults to log2 of the
+// number of entrants (rounded up).
+func Rounds(n int) Option {
+	return func(o *opts) {
+		o.Rounds = n
+	}
+}
+
+// Concurrency sets how many games are played at once.
+// Defaults to the number of CPUs.
+func Concurrency(n int) Option {
+	return func(o *opts) {
+		o.Concurrency = n
+	}
+}
+
+// File sets where results are saved as games finish. If the file exists the
+// tournament is resumed from it.
+func File(path string) Option {
+	return func(o *opts) {
+		o.File = path
+	}
+}
+
+// K sets the Elo K factor. Defaults to 32.
+func K(k float64) Option {
+	return func(o *opts) {
+		o.K = k
+	}
+}
+
+// MatchOptions sets options for each game (see match.Play)
+func MatchOptions(in ...match.Option) Option {
+	return func(o *opts) {
+		o.Match = in
+	}
+}
+
+// Tournament plays games between entrants
+type Tournament struct {
+	lock     sync.Mutex
+	cfg      *opts
+	entrants map[string]*Entrant
+	results  *Results
+}
+
+// New returns a tournament between the given entrants
+func New(format sim.Format, entrants []*Entrant, in ...Option) (*Tournament, error) {
+	cfg := buildOpts(in)
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Games < 1 {
+		cfg.Games = 1
+	}
+	if cfg.Pairing != RoundRobin && cfg.Pairing != Swiss {
+		return nil, fmt.Errorf("unknown pairing %s", cfg.Pairing)
+	}
+
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("%w at least 2 entrants are required, got %d", ErrInvalidEntrants, len(entrants))
+	}
+	t := &Tournament{cfg: cfg, entrants: map[string]*Entrant{}}
+	names := []string{}
+	for _, e := range entrants {
+		if e.Name == "" || e.New == nil {
+			return nil, fmt.Errorf("%w entrants require a name & player", ErrInvalidEntrants)
+		}
+		if _, ok := t.entrants[e.Name]; ok {
+			return nil, fmt.Errorf("%w duplicate name %s", ErrInvalidEntrants, e.Name)
+		}
+		if !sim.IsRandom(format) && len(e.Team) == 0 {
+			return nil, fmt.Errorf("%w %s has no team for %s", ErrInvalidEntrants, e.Name, format)
+		}
+		t.entrants[e.Name] = e
+		names = append(names, e.Name)
+	}
+
+	if cfg.Rounds < 1 {
+		cfg.Rounds = int(math.Ceil(math.Log2(float64(len(names)))))
+	}
+
+	t.results = &Results{Format: format, Pairing: cfg.Pairing, Seed: cfg.Seed, Entrants: names, Games: []*Game{}}
+	if cfg.File != "" {
+		err := t.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t.results.Seed.IsZero() {
+		t.results.Seed = sim.RandomSeed()
+	}
+
+	return t, nil
+}
+
+// load resumes from the results file, if it exists
+func (t *Tournament) load() error {
+	data, err := ioutil.ReadFile(t.cfg.File)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	saved := &Results{}
+	err = json.Unmarshal(data, saved)
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrMismatch, err)
+	}
+
+	switch {
+	case saved.Format != t.results.Format:
+		return fmt.Errorf("%w format is %s not %s", ErrMismatch, saved.Format, t.results.Format)
+	case saved.Pairing != t.results.Pairing:
+		return fmt.Errorf("%w pairing is %s not %s", ErrMismatch, saved.Pairing, t.results.Pairing)
+	case !t.cfg.Seed.IsZero() && saved.Seed != t.cfg.Seed:
+		return fmt.Errorf("%w seed is %s not %s", ErrMismatch, saved.Seed, t.cfg.Seed)
+	case fmt.Sprint(saved.Entrants) != fmt.Sprint(t.results.Entrants):
+		return fmt.Errorf("%w entrants are %v not %v", ErrMismatch, saved.Entrants, t.results.Entrants)
+	}
+
+	t.results = saved
+	return nil
+}
+
+// save writes results to file (if set). Expects the lock to be held.
+func (t *Tournament) save() error {
+	if t.cfg.File == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(t.results, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// nb. write & rename so we never leave a partial file
+	tmp := t.cfg.File + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.cfg.File)
+}
+
+// Run plays all games that haven't been played yet & returns the standings.
+// Games that fail are recorded with their error (see Game) rather than
+// stopping the tournament.
+func (t *Tournament) Run() ([]*Standing, error) {
+	switch t.cfg.Pairing {
+	case Swiss:
+		for r := 0; r < t.cfg.Rounds; r++ {
+			games, err := t.swissRound(r)
+			if err != nil {
+				return nil, err
+			}
+			err = t.playAll(games)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		games, err := t.roundRobin()
+		if err != nil {
+			return nil, err
+		}
+		err = t.playAll(games)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.Standings(), nil
+}
+
+// Results returns the games so far
+func (t *Tournament) Results() *Results {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	data, _ := json.Marshal(t.results)
+	cp := &Results{}
+	json.Unmarshal(data, cp)
+	return cp
+}
+
+// schedule adds a game, with it's seed derived from the master seed.
+// Expects the lock to be held.
+func (t *Tournament) schedule(round int, p1, p2 string) *Game {
+	g := &Game{Round: round, P1: p1, P2: p2, Seed: t.results.Seed.Derive(len(t.results.Games))}
+	if p2 == "" {
+		g.Done = true
+	}
+	t.results.Games = append(t.results.Games, g)
+	return g
+}
+
+// roundRobin schedules every pairing (if not already scheduled)
+func (t *Tournament) roundRobin() ([]*Game, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.results.Games) > 0 {
+		return t.results.Games, nil
+	}
+
+	for r, pairs := range roundRobin(t.results.Entrants) {
+		for _, pair := range pairs {
+			for g := 0; g < t.cfg.Games; g++ {
+				if g%2 == 0 {
+					t.schedule(r, pair[0], pair[1])
+				} else {
+					t.schedule(r, pair[1], pair[0])
+				}
+			}
+		}
+	}
+
+	return t.results.Games, t.save()
+}
+
+// swissRound returns the games of the given round, pairing players
+// by their standings if the round hasn't been scheduled yet
+func (t *Tournament) swissRound(round int) ([]*Game, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	games := []*Game{}
+	for _, g := range t.results.Games {
+		if g.Round == round {
+			games = append(games, g)
+		}
+	}
+	if len(games) > 0 {
+		return games, nil
+	}
+
+	standings := standings(t.results, t.cfg.K)
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		return standings[i].Elo > standings[j].Elo
+	})
+	order := []string{}
+	for _, s := range standings {
+		order = append(order, s.Name)
+	}
+
+	met := map[string]map[string]bool{}
+	byes := map[string]bool{}
+	for _, name := range t.results.Entrants {
+		met[name] = map[string]bool{}
+	}
+	for _, g := range t.results.Games {
+		if g.P2 == "" {
+			byes[g.P1] = true
+			continue
+		}
+		met[g.P1][g.P2] = true
+		met[g.P2][g.P1] = true
+	}
+
+	pairs, bye := swiss(order, met, byes)
+	for _, pair := range pairs {
+		for g := 0; g < t.cfg.Games; g++ {
+			if g%2 == 0 {
+				games = append(games, t.schedule(round, pair[0], pair[1]))
+			} else {
+				games = append(games, t.schedule(round, pair[1], pair[0]))
+			}
+		}
+	}
+	if bye != "" {
+		games = append(games, t.schedule(round, bye, ""))
+	}
+
+	return games, t.save()
+}
+
+// playAll plays the given games that haven't been played (or failed)
+func (t *Tournament) playAll(games []*Game) error {
+	work := make(chan *Game)
+	errs := make(chan error, len(games))
+
+	var wg sync.WaitGroup
+	for w := 0; w < t.cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range work {
+				errs <- t.play(g)
+			}
+		}()
+	}
+
+	t.lock.Lock()
+	pending := []*Game{}
+	for _, g := range games {
+		if !g.finished() {
+			pending = append(pending, g)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, g := range pending {
+		work <- g
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// play plays a single game & saves the result
+func (t *Tournament) play(g *Game) error {
+	t.lock.Lock()
+	p1, p2, seed := t.entrants[g.P1], t.entrants[g.P2], g.Seed
+	t.lock.Unlock()
+
+	spec := &sim.BattleSpec{Format: t.results.Format, Seed: seed}
+	if !sim.IsRandom(spec.Format) {
+		spec.Players = [][]*sim.PokemonSpec{sim.CopyTeam(p1.Team), sim.CopyTeam(p2.Team)}
+	}
+	players := []player.Player{
+		p1.New(int64(seed.Derive(0).Uint64())),
+		p2.New(int64(seed.Derive(1).Uint64())),
+	}
+
+	res, err := play(spec, players, t.cfg.Match...)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	g.Done = true
+	g.Error = ""
+	if err != nil {
+		g.Error = err.Error()
+	}
+	if res != nil {
+		g.Tie = res.Tie
+		g.Turns = res.Turns
+		switch res.Winner {
+		case "p1":
+			g.Winner = g.P1
+		case "p2":
+			g.Winner = g.P2
+		}
+	}
+
+	return t.save()
+}
+
+// Standings returns each entrant's record & ratings, best rated first
+func (t *Tournament) Standings() []*Standing {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	out := standings(t.results, t.cfg.K)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Rating != out[j].Rating {
+			return out[i].Rating > out[j].Rating
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// standings works out records & ratings from the games played, in the
+// order of the entrants.
+// Elo is updated after each game, Glicko-2 after each round (it's rating
+// period).
+func standings(res *Results, k float64) []*Standing {
+	byName := map[string]*Standing{}
+	ratings := map[string]*glicko{}
+	out := []*Standing{}
+	for _, name := range res.Entrants {
+		s := &Standing{Name: name, Elo: DefaultRating}
+		byName[name] = s
+		ratings[name] = newGlicko()
+		out = append(out, s)
+	}
+
+	rounds := map[int][]*Game{}
+	order := []int{}
+	for _, g := range res.Games {
+		if _, ok := rounds[g.Round]; !ok {
+			order = append(order, g.Round)
+		}
+		rounds[g.Round] = append(rounds[g.Round], g)
+	}
+	sort.Ints(order)
+
+	for _, r := range order {
+		outcomes := map[string][]*outcome{}
+		for _, g := range rounds[r] {
+			a, b := byName[g.P1], byName[g.P2]
+			if a == nil || !g.finished() {
+				continue
+			}
+			if g.P2 == "" {
+				a.Byes++
+				a.Points++
+				continue
+			}
+			if b == nil || !g.rated() {
+				continue
+			}
+
+			s := g.score()
+			a.Played++
+			b.Played++
+			a.Points += s
+			b.Points += 1 - s
+			switch s {
+			case 1:
+				a.Wins++
+				b.Losses++
+			case 0:
+				a.Losses++
+				b.Wins++
+			default:
+				a.Ties++
+				b.Ties++
+			}
+
+			a.Elo, b.Elo = elo(a.Elo, b.Elo, s, k)
+			outcomes[g.P1] = append(outcomes[g.P1], &outcome{opponent: ratings[g.P2], score: s})
+			outcomes[g.P2] = append(outcomes[g.P2], &outcome{opponent: ratings[g.P1], score: 1 - s})
+		}
+
+		// nb. everyone is updated from ratings at the start of the period
+		next := map[string]*glicko{}
+		for name, g := range ratings {
+			next[name] = g.update(outcomes[name])
+		}
+		ratings = next
+	}
+
+	for name, s := range byName {
+		g := ratings[name]
+		s.Rating, s.Deviation, s.Volatility = g.Rating, g.Deviation, g.Volatility
+		s.Low, s.High = g.interval()
+	}
+
+	return out
+}
@@ -0,0 +1,247 @@
+package tournament
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/match"
+	"github.com/voidshard/poke-showdown-go/pkg/player"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// bot is a player that wins against weaker bots (see fakePlay)
+type bot struct {
+	strength int
+}
+
+func (b *bot) Choose(*sim.Side) (*sim.Action, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// fakePlay decides games by bot strength & counts games played
+type fakePlay struct {
+	lock  sync.Mutex
+	games int
+	seeds map[sim.Seed]bool
+	fail  map[int]bool
+}
+
+func (f *fakePlay) play(spec *sim.BattleSpec, players []player.Player, in ...match.Option) (*match.Result, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.games++
+	if f.fail[f.games] {
+		return nil, fmt.Errorf("simulator crashed")
+	}
+	f.seeds[spec.Seed] = true
+
+	a, b := players[0].(*bot), players[1].(*bot)
+	res := &match.Result{Seed: spec.Seed, Turns: 10}
+	switch {
+	case a.strength > b.strength:
+		res.Winner = "p1"
+	case a.strength < b.strength:
+		res.Winner = "p2"
+	default:
+		res.Tie = true
+	}
+	return res, nil
+}
+
+func withPlay(t *testing.T, fail ...int) *fakePlay {
+	f := &fakePlay{seeds: map[sim.Seed]bool{}, fail: map[int]bool{}}
+	for _, n := range fail {
+		f.fail[n] = true
+	}
+	orig := play
+	play = f.play
+	t.Cleanup(func() { play = orig })
+	return f
+}
+
+// tempFile returns a path in a temporary directory
+func tempFile(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tournament")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "results.json")
+}
+
+func testEntrants(n int) []*Entrant {
+	out := []*Entrant{}
+	for i := 0; i < n; i++ {
+		strength := i
+		out = append(out, &Entrant{
+			Name: fmt.Sprintf("bot-v%d", i),
+			New:  func(int64) player.Player { return &bot{strength: strength} },
+		})
+	}
+	return out
+}
+
+func TestRoundRobinTournament(t *testing.T) {
+	f := withPlay(t)
+
+	tour, err := New(sim.FormatGen8Random, testEntrants(4), Seed(sim.Seed{1, 2, 3, 4}), Games(2))
+	assert.Nil(t, err)
+
+	standings, err := tour.Run()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 12, f.games) // 6 pairings, 2 games each
+	assert.Equal(t, 12, len(f.seeds))
+
+	// the strongest bot wins everything
+	names := []string{}
+	for _, s := range standings {
+		names = append(names, s.Name)
+		assert.Equal(t, 6, s.Played)
+		assert.True(t, s.Low < s.Rating && s.Rating < s.High)
+	}
+	assert.Equal(t, []string{"bot-v3", "bot-v2", "bot-v1", "bot-v0"}, names)
+	assert.Equal(t, 6, standings[0].Wins)
+	assert.Equal(t, 6, standings[3].Losses)
+	assert.True(t, standings[0].Elo > standings[1].Elo)
+
+	// each pairing plays from both sides
+	sides := map[string]int{}
+	for _, g := range tour.Results().Games {
+		sides[g.P1+"/"+g.P2]++
+	}
+	assert.Equal(t, 12, len(sides))
+}
+
+func TestSwissTournament(t *testing.T) {
+	f := withPlay(t)
+
+	tour, err := New(sim.FormatGen8Random, testEntrants(5), Schedule(Swiss), Games(1), Rounds(3))
+	assert.Nil(t, err)
+
+	standings, err := tour.Run()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, f.games) // 2 games a round, plus a bye
+
+	met := map[string]bool{}
+	byes := map[string]bool{}
+	for _, g := range tour.Results().Games {
+		if g.P2 == "" {
+			// no one has two byes
+			assert.False(t, byes[g.P1])
+			byes[g.P1] = true
+			continue
+		}
+		key := g.P1 + "/" + g.P2
+		if g.P1 > g.P2 {
+			key = g.P2 + "/" + g.P1
+		}
+		assert.False(t, met[key], key)
+		met[key] = true
+	}
+	assert.Equal(t, 3, len(byes))
+	assert.Equal(t, "bot-v4", standings[0].Name)
+}
+
+func TestTournamentResume(t *testing.T) {
+	path := tempFile(t)
+
+	// the 2nd game fails & is recorded as such
+	f := withPlay(t, 2)
+	tour, err := New(sim.FormatGen8Random, testEntrants(3), File(path), Games(1))
+	assert.Nil(t, err)
+	_, err = tour.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, f.games)
+
+	failed := 0
+	for _, g := range tour.Results().Games {
+		if g.Error != "" {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+
+	// resuming plays only the failed game, with the saved seed
+	f = withPlay(t)
+	resumed, err := New(sim.FormatGen8Random, testEntrants(3), File(path), Games(1))
+	assert.Nil(t, err)
+	standings, err := resumed.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, f.games)
+	assert.Equal(t, tour.Results().Seed, resumed.Results().Seed)
+
+	played := 0
+	for _, s := range standings {
+		played += s.Played
+	}
+	assert.Equal(t, 6, played)
+
+	// nothing left to play
+	f = withPlay(t)
+	again, err := New(sim.FormatGen8Random, testEntrants(3), File(path), Games(1))
+	assert.Nil(t, err)
+	_, err = again.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, f.games)
+}
+
+func TestTournamentMismatch(t *testing.T) {
+	path := tempFile(t)
+	withPlay(t)
+
+	tour, err := New(sim.FormatGen8Random, testEntrants(2), File(path))
+	assert.Nil(t, err)
+	_, err = tour.Run()
+	assert.Nil(t, err)
+
+	_, err = New(sim.FormatGen8Random, testEntrants(3), File(path))
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	_, err = New(sim.FormatGen7Random, testEntrants(2), File(path))
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	_, err = New(sim.FormatGen8Random, testEntrants(2), File(path), Seed(sim.Seed{9, 9, 9, 9}))
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	err = ioutil.WriteFile(path, []byte("{"), 0644)
+	assert.Nil(t, err)
+	_, err = New(sim.FormatGen8Random, testEntrants(2), File(path))
+	assert.ErrorIs(t, err, ErrMismatch)
+}
+
+func TestNewInvalidEntrants(t *testing.T) {
+	_, err := New(sim.FormatGen8Random, testEntrants(1))
+	assert.ErrorIs(t, err, ErrInvalidEntrants)
+
+	dupes := append(testEntrants(2), testEntrants(1)...)
+	_, err = New(sim.FormatGen8Random, dupes)
+	assert.ErrorIs(t, err, ErrInvalidEntrants)
+
+	// given teams are required for non random formats
+	_, err = New(sim.FormatGen8, testEntrants(2))
+	assert.ErrorIs(t, err, ErrInvalidEntrants)
+}
+
+func TestStandingsUnfinishedGames(t *testing.T) {
+	res := &Results{
+		Entrants: []string{"a", "b"},
+		Games: []*Game{
+			&Game{Round: 0, P1: "a", P2: "b", Done: true},
+			&Game{Round: 1, P1: "b", P2: "a", Done: true, Winner: "b"},
+		},
+	}
+
+	result := standings(res, 32)
+
+	assert.Equal(t, 1, result[0].Played)
+	assert.Equal(t, 1, result[0].Losses)
+	assert.Equal(t, 0.0, result[0].Points)
+	assert.Equal(t, 1, result[1].Wins)
+	assert.False(t, res.Games[0].finished())
+}
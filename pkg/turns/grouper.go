@@ -0,0 +1,259 @@
+package turns
+
+import (
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// Batch is all updates from the simulator for a single turn, with a
+// summary of what happened.
+//
+// Nb. the simulator sends requests (side updates) for the next decision
+// before the events that lead up to them, so a batch's side updates are
+// generally those for the *next* turn.
+type Batch struct {
+	Summary *TurnSummary
+	Updates []*sim.Update
+}
+
+// Grouper collects updates in to turns.
+type Grouper struct {
+	current *Batch
+
+	// hp is the last known HP (percent) of each pokemon by Key()
+	hp map[string]float64
+
+	// last move used this turn, so we know who to credit damage to
+	lastMove *event.MoveEvent
+
+	// upkeep is set once we're handling end of turn effects
+	upkeep bool
+}
+
+// NewGrouper returns a new turn grouper
+func NewGrouper() *Grouper {
+	return &Grouper{
+		current: newBatch(0),
+		hp:      map[string]float64{},
+	}
+}
+
+// Group reads updates from the given channel & returns batches of them by
+// turn. The final (partial) turn, if any, is sent when the input closes.
+func Group(updates <-chan *sim.Update) <-chan *Batch {
+	out := make(chan *Batch)
+
+	go func() {
+		defer close(out)
+
+		g := NewGrouper()
+		for u := range updates {
+			b := g.Update(u)
+			if b != nil {
+				out <- b
+			}
+		}
+
+		b := g.Flush()
+		if b != nil {
+			out <- b
+		}
+	}()
+
+	return out
+}
+
+// Update adds the given update to the current turn. If the update ends the
+// turn we return the batch for the turn, otherwise nil.
+func (g *Grouper) Update(u *sim.Update) *Batch {
+	var done *Batch
+
+	if u.Event != nil {
+		switch u.Event.Type {
+		case event.Turn:
+			// the turn event is the start of a new turn
+			done = g.Flush()
+			g.current = newBatch(u.Event.Magnitude)
+		case event.Win, event.Tie:
+			g.current.Updates = append(g.current.Updates, u)
+			g.summarise(u.Event)
+			return g.Flush()
+		}
+	}
+
+	g.current.Updates = append(g.current.Updates, u)
+	if u.Event != nil {
+		g.summarise(u.Event)
+	}
+
+	return done
+}
+
+// Flush returns the current batch (if it has any updates) and starts
+// a new one.
+func (g *Grouper) Flush() *Batch {
+	b := g.current
+	g.current = newBatch(b.Summary.Number + 1)
+	g.lastMove = nil
+	g.upkeep = false
+
+	if len(b.Updates) == 0 {
+		return nil
+	}
+	return b
+}
+
+// summarise adds the event to our turn summary
+func (g *Grouper) summarise(e *event.Event) {
+	t := g.current.Summary
+	t.Events = append(t.Events, e)
+
+	if g.upkeep {
+		t.Residuals = append(t.Residuals, e)
+	}
+
+	switch p := e.Payload.(type) {
+	case *event.UpkeepEvent:
+		g.upkeep = true
+		g.lastMove = nil
+	case *event.MoveEvent:
+		g.lastMove = p
+		t.pokemon(p.User)
+
+		targets := []*event.Subject{}
+		if p.Target != nil {
+			targets = append(targets, p.Target)
+		}
+		t.Actions = append(t.Actions, &Action{
+			Player:  p.User.Player,
+			Pokemon: p.User,
+			Kind:    event.Move,
+			Name:    p.Move,
+			Targets: targets,
+		})
+	case *event.CantEvent:
+		if p.Pokemon == nil {
+			return
+		}
+		t.pokemon(p.Pokemon)
+		t.Actions = append(t.Actions, &Action{
+			Player:  p.Pokemon.Player,
+			Pokemon: p.Pokemon,
+			Kind:    event.Cant,
+			Name:    p.Reason,
+		})
+	case *event.SwitchEvent:
+		if p.Pokemon == nil {
+			return
+		}
+		t.Switches = append(t.Switches, p)
+		t.pokemon(p.Pokemon)
+		g.hp[Key(p.Pokemon)] = percent(p.HP, p.MaxHP)
+
+		if !p.Forced && t.Number > 0 {
+			t.Actions = append(t.Actions, &Action{
+				Player:  p.Pokemon.Player,
+				Pokemon: p.Pokemon,
+				Kind:    event.Switch,
+				Name:    p.Details,
+			})
+		}
+	case *event.DamageEvent:
+		if p.Target == nil {
+			return
+		}
+		lost := -g.setHP(p.Target, p.HP, p.MaxHP)
+		if lost <= 0 {
+			return
+		}
+		t.pokemon(p.Target).DamageTaken += lost
+
+		dealer := g.dealer(p)
+		if dealer != nil && Key(dealer) != Key(p.Target) {
+			t.pokemon(dealer).DamageDealt += lost
+		}
+	case *event.HealEvent:
+		if p.Target == nil {
+			return
+		}
+		change := g.setHP(p.Target, p.HP, p.MaxHP)
+		if change > 0 {
+			t.pokemon(p.Target).Healed += change
+		} else if change < 0 {
+			// -sethp (pain split) can lower HP too
+			t.pokemon(p.Target).DamageTaken -= change
+		}
+	case *event.FaintEvent:
+		if p.Pokemon == nil {
+			return
+		}
+		t.pokemon(p.Pokemon).Fainted = true
+		t.Faints = append(t.Faints, p.Pokemon)
+		g.hp[Key(p.Pokemon)] = 0
+	case *event.StatusEvent:
+		if p.Pokemon == nil || p.Cured {
+			return
+		}
+		t.pokemon(p.Pokemon)
+		t.Statuses = append(t.Statuses, p)
+	case *event.WeatherEvent:
+		if !p.Upkeep {
+			t.Field = append(t.Field, e)
+		}
+	case *event.FieldEvent, *event.SideConditionEvent:
+		t.Field = append(t.Field, e)
+	case *event.InfoEvent:
+		if e.Type == event.SwapSideConditions {
+			t.Field = append(t.Field, e)
+		}
+	case *event.WinEvent:
+		t.Ended = true
+		t.Winner = p.Player
+		t.Tie = p.Tie
+	}
+}
+
+// dealer returns who was responsible for some damage, if anyone.
+func (g *Grouper) dealer(p *event.DamageEvent) *event.Subject {
+	if p.Source != nil {
+		// indirect damage; we only know who is responsible if told
+		// ie. [from] ability: Rough Skin|[of] p1a: Garchomp
+		return p.Source.Source
+	}
+	if g.lastMove != nil {
+		return g.lastMove.User
+	}
+	return nil
+}
+
+// setHP records the pokemon's new HP & returns the change (in percent)
+func (g *Grouper) setHP(s *event.Subject, hp, max int) float64 {
+	key := Key(s)
+
+	prev, ok := g.hp[key]
+	if !ok {
+		// we haven't seen it switch in; assume it was healthy
+		prev = 100
+	}
+
+	now := percent(hp, max)
+	g.hp[key] = now
+
+	return now - prev
+}
+
+// percent returns hp as a percentage of max
+func percent(hp, max int) float64 {
+	if max <= 0 || hp <= 0 {
+		// nb. fainted pokemon are reported with no max HP
+		return 0
+	}
+	return float64(hp) / float64(max) * 100
+}
+
+func newBatch(num int) *Batch {
+	return &Batch{
+		Summary: newTurnSummary(num),
+		Updates: []*sim.Update{},
+	}
+}
@@ -0,0 +1,120 @@
+package turns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+	"github.com/voidshard/poke-showdown-go/pkg/sim"
+)
+
+// testUpdates turns protocol lines into updates
+func testUpdates(lines ...string) []*sim.Update {
+	ups := []*sim.Update{}
+	for _, l := range lines {
+		ups = append(ups, &sim.Update{Event: event.Parse(l)})
+	}
+	return ups
+}
+
+func TestGrouperTurns(t *testing.T) {
+	g := NewGrouper()
+
+	batches := []*Batch{}
+	for _, u := range testUpdates(
+		"|start",
+		"|switch|p1a: Lugia|Lugia, L50|100/100",
+		"|switch|p2a: Umbreon|Umbreon, L50|100/100",
+		"|turn|1",
+		"|move|p1a: Lugia|Aeroblast|p2a: Umbreon",
+		"|-damage|p2a: Umbreon|60/100",
+		"|move|p2a: Umbreon|Toxic|p1a: Lugia",
+		"|-status|p1a: Lugia|tox",
+		"|upkeep",
+		"|-damage|p1a: Lugia|94/100 tox|[from] psn",
+		"|turn|2",
+		"|switch|p2a: Gengar|Gengar, L50|100/100",
+		"|move|p1a: Lugia|Aeroblast|p2a: Gengar",
+		"|-damage|p2a: Gengar|0 fnt",
+		"|faint|p2a: Gengar",
+		"|win|p1",
+	) {
+		b := g.Update(u)
+		if b != nil {
+			batches = append(batches, b)
+		}
+	}
+
+	assert.Nil(t, g.Flush())
+	assert.Equal(t, 3, len(batches))
+
+	lead := batches[0].Summary
+	assert.Equal(t, 0, lead.Number)
+	assert.Equal(t, 2, len(lead.Switches))
+	assert.Equal(t, 0, len(lead.Actions))
+
+	one := batches[1].Summary
+	assert.Equal(t, 1, one.Number)
+	assert.Equal(t, 7, len(batches[1].Updates))
+	assert.Equal(t, 2, len(one.Actions))
+	assert.Equal(t, "Aeroblast", one.ActionsBy("p1")[0].Name)
+	assert.Equal(t, "Toxic", one.ActionsBy("p2")[0].Name)
+	assert.InDelta(t, 40, one.Pokemon["p2: Umbreon"].DamageTaken, 0.001)
+	assert.InDelta(t, 40, one.Pokemon["p1: Lugia"].DamageDealt, 0.001)
+	assert.InDelta(t, 6, one.Pokemon["p1: Lugia"].DamageTaken, 0.001)
+	assert.Equal(t, 1, len(one.Statuses))
+	assert.Equal(t, 1, len(one.Residuals))
+
+	two := batches[2].Summary
+	assert.Equal(t, 2, two.Number)
+	assert.Equal(t, event.Switch, two.Actions[0].Kind)
+	assert.True(t, two.Pokemon["p2: Gengar"].Fainted)
+	assert.InDelta(t, 100, two.Pokemon["p2: Gengar"].DamageTaken, 0.001)
+	assert.True(t, two.Ended)
+	assert.Equal(t, "p1", two.Winner)
+}
+
+func TestGrouperDamageDealtBy(t *testing.T) {
+	g := NewGrouper()
+
+	for _, u := range testUpdates(
+		"|turn|1",
+		"|move|p2a: Umbreon|Foul Play|p1a: Garchomp",
+		"|-damage|p1a: Garchomp|50/100",
+		"|-damage|p2a: Umbreon|88/100|[from] ability: Rough Skin|[of] p1a: Garchomp",
+		"|-damage|p2a: Umbreon|78/100|[from] item: Rocky Helmet|[of] p1a: Garchomp",
+		"|-heal|p2a: Umbreon|84/100|[from] item: Leftovers",
+	) {
+		g.Update(u)
+	}
+
+	b := g.Flush()
+	sum := b.Summary
+
+	assert.InDelta(t, 50, sum.Pokemon["p2: Umbreon"].DamageDealt, 0.001)
+	assert.InDelta(t, 22, sum.Pokemon["p2: Umbreon"].DamageTaken, 0.001)
+	assert.InDelta(t, 6, sum.Pokemon["p2: Umbreon"].Healed, 0.001)
+	assert.InDelta(t, 22, sum.Pokemon["p1: Garchomp"].DamageDealt, 0.001)
+}
+
+func TestGroupChannel(t *testing.T) {
+	in := make(chan *sim.Update)
+	go func() {
+		defer close(in)
+		for _, u := range testUpdates("|turn|1", "|move|p1a: Lugia|Roost|p1a: Lugia", "|turn|2", "|cant|p1a: Lugia|par") {
+			in <- u
+		}
+	}()
+
+	nums := []int{}
+	for b := range Group(in) {
+		nums = append(nums, b.Summary.Number)
+	}
+
+	assert.Equal(t, []int{1, 2}, nums)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "x", Key(&event.Subject{Player: "p1", Position: "a", Nickname: "Bob", ID: "x"}))
+	assert.Equal(t, "p1: Bob", Key(&event.Subject{Player: "p1", Position: "a", Nickname: "Bob"}))
+}
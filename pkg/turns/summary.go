@@ -0,0 +1,113 @@
+package turns
+
+import (
+	"github.com/voidshard/poke-showdown-go/pkg/event"
+)
+
+// Action is something a pokemon chose to do this turn; use a move,
+// switch out or try (and fail) to act.
+type Action struct {
+	Player  string
+	Pokemon *event.Subject
+
+	// Kind is the event type; event.Move, event.Switch or event.Cant
+	Kind string
+
+	// Name is the move used, details of the pokemon switched in or the
+	// reason the pokemon couldn't act
+	Name string
+
+	Targets []*event.Subject
+}
+
+// PokemonSummary is what happened to a single pokemon in a turn.
+// HP changes are given as a percentage of max HP, since that's all we're
+// told about the opponent's pokemon.
+type PokemonSummary struct {
+	// Pokemon as last referred to this turn
+	Pokemon *event.Subject
+
+	DamageTaken float64
+	DamageDealt float64
+	Healed      float64
+	Fainted     bool
+}
+
+// TurnSummary is an overview of everything that happened in a turn
+type TurnSummary struct {
+	// Number of the turn (0 is the lead pokemon being sent out)
+	Number int
+
+	// Actions in the order they happened
+	Actions []*Action
+
+	// Pokemon that did, or had something done to them, this turn.
+	// Keyed by Key()
+	Pokemon map[string]*PokemonSummary
+
+	Faints   []*event.Subject
+	Switches []*event.SwitchEvent
+
+	// Statuses inflicted this turn (cures are not included)
+	Statuses []*event.StatusEvent
+
+	// Field holds weather, terrain & side condition changes
+	Field []*event.Event
+
+	// Residuals are end of turn effects (after 'upkeep')
+	Residuals []*event.Event
+
+	// Events are all events in the turn, in order
+	Events []*event.Event
+
+	// Ended is set if the battle finished this turn
+	Ended  bool
+	Winner string
+	Tie    bool
+}
+
+// ActionsBy returns the actions taken by the given player, in order
+func (t *TurnSummary) ActionsBy(player string) []*Action {
+	found := []*Action{}
+	for _, a := range t.Actions {
+		if a.Player == player {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// Key returns the key used for a pokemon in a TurnSummary; the ID of the
+// pokemon if known, otherwise it's ident (player & nickname)
+func Key(s *event.Subject) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Ident()
+}
+
+// pokemon returns the summary for the given pokemon, creating it if needed
+func (t *TurnSummary) pokemon(s *event.Subject) *PokemonSummary {
+	key := Key(s)
+	p, ok := t.Pokemon[key]
+	if !ok {
+		p = &PokemonSummary{}
+		t.Pokemon[key] = p
+	}
+	p.Pokemon = s
+	return p
+}
+
+func newTurnSummary(num int) *TurnSummary {
+	return &TurnSummary{
+		Number:    num,
+		Actions:   []*Action{},
+		Pokemon:   map[string]*PokemonSummary{},
+		Faints:    []*event.Subject{},
+		Switches:  []*event.SwitchEvent{},
+		Statuses:  []*event.StatusEvent{},
+		Field:     []*event.Event{},
+		Residuals: []*event.Event{},
+		Events:    []*event.Event{},
+	}
+}
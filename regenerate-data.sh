@@ -4,6 +4,7 @@
 # Generates an assets.go file in pkg/pokedata from pokemon-showdown 
 # - pokedex.json
 # - moves.json
+# - learnsets.json
 #
 # Data is embedded in to the library with go-bindata
 # - see: https://github.com/go-bindata/go-bindata
@@ -17,6 +18,7 @@ DIR=$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )
 mkdir ${DIR}/assets
 wget "https://play.pokemonshowdown.com/data/pokedex.json" -O ${DIR}/assets/pokedex.json
 wget "https://play.pokemonshowdown.com/data/moves.json" -O ${DIR}/assets/moves.json
+wget "https://play.pokemonshowdown.com/data/learnsets.json" -O ${DIR}/assets/learnsets.json
 
 # generate assets.go
 go-bindata -prefix "${DIR}/assets" -o ${DIR}/pkg/pokedata/assets.go ${DIR}/assets/
@@ -25,4 +27,5 @@ sed -i 's/package main/package pokedata/g' ${DIR}/pkg/pokedata/assets.go
 # clean up
 rm -v ${DIR}/assets/pokedex.json
 rm -v ${DIR}/assets/moves.json
+rm -v ${DIR}/assets/learnsets.json
 rmdir ${DIR}/assets